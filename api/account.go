@@ -2,6 +2,7 @@ package api
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -9,7 +10,10 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/mohammad19khodaei/simple_bank/api/middlewares"
+	"github.com/mohammad19khodaei/simple_bank/api/policies"
 	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/token"
+	"github.com/mohammad19khodaei/simple_bank/utils"
 )
 
 type createAccountRequest struct {
@@ -25,7 +29,7 @@ func (s *server) createAccountHandler(ctx *gin.Context) {
 		return
 	}
 
-	account, err := s.store.CreateAccount(ctx, db.CreateAccountParams{
+	account, err := s.createAccountWithNumber(ctx, db.CreateAccountParams{
 		Owner:    owner,
 		Currency: request.Currency,
 		Balance:  0,
@@ -44,7 +48,30 @@ func (s *server) createAccountHandler(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusCreated, account)
+	ctx.JSON(http.StatusCreated, s.newAccountResponse(account))
+}
+
+// accountNumberAttempts bounds the draws of a random account number, a clash
+// is already unlikely on the first one.
+const accountNumberAttempts = 3
+
+// createAccountWithNumber creates the account with a fresh account number,
+// drawing another one when it is already taken.
+func (s *server) createAccountWithNumber(ctx *gin.Context, params db.CreateAccountParams) (account db.Account, err error) {
+	for attempt := 0; attempt < accountNumberAttempts; attempt++ {
+		params.Number, err = utils.NewAccountNumber()
+		if err != nil {
+			return
+		}
+
+		account, err = s.store.CreateAccount(ctx, params)
+
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) || pgErr.ConstraintName != "accounts_number_key" {
+			return
+		}
+	}
+	return
 }
 
 type getAccountParams struct {
@@ -67,14 +94,61 @@ func (s *server) getAccountHandler(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
 		return
 	}
-	username := ctx.MustGet(middlewares.AuthUsernameKey).(string)
+	payload := ctx.MustGet(middlewares.AuthPayloadKey).(*token.Payload)
 
-	if account.Owner != username {
+	if !policies.CanReadAccount(payload, account) {
 		ctx.JSON(http.StatusForbidden, s.errorResponse(errors.New("forbidden: account does not belong to you")))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, s.newAccountResponse(account))
+}
+
+func (s *server) closeAccountHandler(ctx *gin.Context) {
+	var params getAccountParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	account, err := s.store.GetAccount(ctx, params.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, s.errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	payload := ctx.MustGet(middlewares.AuthPayloadKey).(*token.Payload)
+	if !policies.CanCloseAccount(payload, account) {
+		ctx.JSON(http.StatusForbidden, s.errorResponse(errors.New("forbidden: account does not belong to you")))
+		return
+	}
+
+	if !utils.CanTransitionAccountStatus(account.Status, utils.AccountStatusClosed) {
+		ctx.JSON(http.StatusConflict, s.errorResponse(fmt.Errorf("cannot close a %s account", account.Status)))
+		return
+	}
+
+	if account.Balance != 0 {
+		ctx.JSON(http.StatusConflict, s.errorResponse(errors.New("account balance must be zero to close it")))
+		return
+	}
+
+	account, err = s.store.CloseAccount(ctx, account.ID)
+	if err != nil {
+		// the balance or status changed after we read the account
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusConflict, s.errorResponse(errors.New("account changed while closing it, try again")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, s.newAccountResponse(account))
 }
 
 type listAccountsParams struct {
@@ -111,5 +185,5 @@ func (s *server) ListAccountsHandler(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, accounts)
+	ctx.JSON(http.StatusOK, s.newAccountsResponse(accounts))
 }
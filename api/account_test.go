@@ -1,6 +1,7 @@
 package api_test
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -8,12 +9,15 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/mohammad19khodaei/simple_bank/api"
 	"github.com/mohammad19khodaei/simple_bank/api/middlewares"
 	mockdb "github.com/mohammad19khodaei/simple_bank/db/mock"
 	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
 	"github.com/mohammad19khodaei/simple_bank/token"
+	"github.com/mohammad19khodaei/simple_bank/utils"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
@@ -32,7 +36,7 @@ func TestGetAccount(t *testing.T) {
 			name:      "OK",
 			accountID: account.ID,
 			setAuthHeader: func(tokenMaker token.Maker, req *http.Request) {
-				token, err := tokenMaker.GenerateToken(account.Owner, config.TokenDuration)
+				token, _, err := tokenMaker.GenerateToken(account.Owner, utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
 				require.NoError(t, err)
 				req.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, token))
 			},
@@ -47,16 +51,53 @@ func TestGetAccount(t *testing.T) {
 
 				body, err := io.ReadAll(recorder.Body)
 				require.NoError(t, err)
-				gotAccount := db.Account{}
+				gotAccount := api.AccountResponse{}
 				json.Unmarshal(body, &gotAccount)
-				require.Equal(t, account, gotAccount)
+				require.Equal(t, account, gotAccount.Account)
+				require.Equal(t, fmt.Sprintf("%d.%02d", account.Balance/100, account.Balance%100), gotAccount.FormattedBalance)
+			},
+		},
+		{
+			name:      "account of another customer",
+			accountID: account.ID,
+			setAuthHeader: func(tokenMaker token.Maker, req *http.Request) {
+				token, _, err := tokenMaker.GenerateToken(utils.RandomOwner(), utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
+				require.NoError(t, err)
+				req.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, token))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "support staff reads any account",
+			accountID: account.ID,
+			setAuthHeader: func(tokenMaker token.Maker, req *http.Request) {
+				token, _, err := tokenMaker.GenerateToken(utils.RandomOwner(), utils.RoleSupport, config.TokenDuration, token.TokenTypeAccess)
+				require.NoError(t, err)
+				req.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, token))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
 			},
 		},
 		{
 			name:      "not found",
 			accountID: account.ID,
 			setAuthHeader: func(tokenMaker token.Maker, req *http.Request) {
-				token, err := tokenMaker.GenerateToken(account.Owner, config.TokenDuration)
+				token, _, err := tokenMaker.GenerateToken(account.Owner, utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
 				require.NoError(t, err)
 				req.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, token))
 			},
@@ -74,7 +115,7 @@ func TestGetAccount(t *testing.T) {
 			name:      "internal error",
 			accountID: account.ID,
 			setAuthHeader: func(tokenMaker token.Maker, req *http.Request) {
-				token, err := tokenMaker.GenerateToken(account.Owner, config.TokenDuration)
+				token, _, err := tokenMaker.GenerateToken(account.Owner, utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
 				require.NoError(t, err)
 				req.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, token))
 			},
@@ -92,7 +133,7 @@ func TestGetAccount(t *testing.T) {
 			name:      "invalid id",
 			accountID: 0,
 			setAuthHeader: func(tokenMaker token.Maker, req *http.Request) {
-				token, err := tokenMaker.GenerateToken(account.Owner, config.TokenDuration)
+				token, _, err := tokenMaker.GenerateToken(account.Owner, utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
 				require.NoError(t, err)
 				req.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, token))
 			},
@@ -113,7 +154,7 @@ func TestGetAccount(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	store := mockdb.NewMockStore(ctrl)
+	store := newMockStore(ctrl)
 
 	server, err := api.NewServer(config, store)
 	require.NoError(t, err)
@@ -134,3 +175,242 @@ func TestGetAccount(t *testing.T) {
 	}
 
 }
+
+func TestCloseAccount(t *testing.T) {
+	account := createRandomAccount()
+	account.Balance = 0
+
+	fundedAccount := createRandomAccount()
+	fundedAccount.Balance = 100
+
+	testCases := []struct {
+		name          string
+		account       db.Account
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			account:  account,
+			username: account.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				closedAccount := account
+				closedAccount.Status = utils.AccountStatusClosed
+
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					CloseAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(closedAccount, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var gotAccount db.Account
+				err := json.Unmarshal(recorder.Body.Bytes(), &gotAccount)
+				require.NoError(t, err)
+				require.Equal(t, utils.AccountStatusClosed, gotAccount.Status)
+			},
+		},
+		{
+			name:     "account of another customer",
+			account:  account,
+			username: utils.RandomOwner(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					CloseAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "non-zero balance",
+			account:  fundedAccount,
+			username: fundedAccount.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(fundedAccount.ID)).
+					Times(1).
+					Return(fundedAccount, nil)
+				store.EXPECT().
+					CloseAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "balance changed concurrently",
+			account:  account,
+			username: account.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					CloseAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(db.Account{}, pgx.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	tokenMaker, err := token.NewPasetoMaker(config.SecretKey)
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := newMockStore(ctrl)
+
+	server, err := api.NewServer(config, store)
+	require.NoError(t, err)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.buildStubs(store)
+
+			accessToken, _, err := tokenMaker.GenerateToken(tc.username, utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			url := fmt.Sprintf("/accounts/%d/close", tc.account.ID)
+			request := httptest.NewRequest(http.MethodPost, url, nil)
+			request.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, accessToken))
+
+			server.Router().ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCreateAccount(t *testing.T) {
+	owner := utils.RandomOwner()
+
+	testCases := []struct {
+		name          string
+		currency      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			currency: "USD",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccount(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateAccountParams) (db.Account, error) {
+						require.Equal(t, owner, arg.Owner)
+						require.Equal(t, "USD", arg.Currency)
+						require.True(t, utils.IsValidAccountNumber(arg.Number))
+
+						return db.Account{ID: 1, Owner: arg.Owner, Currency: arg.Currency, Number: arg.Number}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var account api.AccountResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &account)
+				require.NoError(t, err)
+				require.True(t, utils.IsValidAccountNumber(account.Number))
+			},
+		},
+		{
+			name:     "account number taken",
+			currency: "USD",
+			buildStubs: func(store *mockdb.MockStore) {
+				var taken string
+				gomock.InOrder(
+					store.EXPECT().
+						CreateAccount(gomock.Any(), gomock.Any()).
+						Times(1).
+						DoAndReturn(func(_ any, arg db.CreateAccountParams) (db.Account, error) {
+							taken = arg.Number
+							return db.Account{}, &pgconn.PgError{Code: pgerrcode.UniqueViolation, ConstraintName: "accounts_number_key"}
+						}),
+					store.EXPECT().
+						CreateAccount(gomock.Any(), gomock.Any()).
+						Times(1).
+						DoAndReturn(func(_ any, arg db.CreateAccountParams) (db.Account, error) {
+							require.NotEqual(t, taken, arg.Number)
+							return db.Account{ID: 1, Owner: arg.Owner, Currency: arg.Currency, Number: arg.Number}, nil
+						}),
+				)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name:     "account exists for the currency",
+			currency: "USD",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccount(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, &pgconn.PgError{Code: pgerrcode.UniqueViolation, ConstraintName: "owner_currency_key"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "unsupported currency",
+			currency: "GBP",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	tokenMaker, err := token.NewPasetoMaker(config.SecretKey)
+	require.NoError(t, err)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := newMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := api.NewServer(config, store)
+			require.NoError(t, err)
+
+			accessToken, _, err := tokenMaker.GenerateToken(owner, utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
+			require.NoError(t, err)
+
+			jsonData, err := json.Marshal(map[string]string{"currency": tc.currency})
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/accounts", bytes.NewReader(jsonData))
+			request.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, accessToken))
+
+			server.Router().ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
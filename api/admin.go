@@ -0,0 +1,432 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+)
+
+type listUsersParams struct {
+	Search  string `form:"search"`
+	Page    int32  `form:"page" binding:"omitempty,min=1"`
+	PerPage int32  `form:"per_page" binding:"omitempty,min=5,max=50"`
+}
+
+func (s *server) adminListUsersHandler(ctx *gin.Context) {
+	var params listUsersParams
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	page := int32(1)
+	if params.Page != 0 {
+		page = params.Page
+	}
+
+	perPage := int32(20)
+	if params.PerPage != 0 {
+		perPage = params.PerPage
+	}
+
+	users, err := s.store.ListUsers(ctx, db.ListUsersParams{
+		Search: params.Search,
+		Limit:  perPage,
+		Offset: (page - 1) * perPage,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	resp := make([]UserResponse, 0, len(users))
+	for _, user := range users {
+		resp = append(resp, createUserResponse(user))
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+type usernameParams struct {
+	Username string `uri:"username" binding:"required,alphanum"`
+}
+
+func (s *server) adminGetUserHandler(ctx *gin.Context) {
+	var params usernameParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	user, err := s.store.GetUser(ctx, params.Username)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, s.errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, createUserResponse(user))
+}
+
+func (s *server) adminFreezeUserHandler(ctx *gin.Context) {
+	s.setUserFrozen(ctx, true)
+}
+
+func (s *server) adminUnfreezeUserHandler(ctx *gin.Context) {
+	s.setUserFrozen(ctx, false)
+}
+
+func (s *server) setUserFrozen(ctx *gin.Context, isFrozen bool) {
+	var params usernameParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	user, err := s.store.UpdateUserFrozen(ctx, db.UpdateUserFrozenParams{
+		IsFrozen: isFrozen,
+		Username: params.Username,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, s.errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	// a frozen user must not keep using the tokens issued before the freeze
+	if isFrozen {
+		if err := s.revokeUserSessions(ctx, user.Username, time.Now()); err != nil {
+			ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, createUserResponse(user))
+}
+
+func (s *server) adminRevokeSessionsHandler(ctx *gin.Context) {
+	var params usernameParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	if err := s.revokeUserSessions(ctx, params.Username, time.Now()); err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+type resetPasswordRequest struct {
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
+
+func (s *server) adminResetPasswordHandler(ctx *gin.Context) {
+	var params usernameParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	var request resetPasswordRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(request.NewPassword)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	user, err := s.store.UpdateUserPassword(ctx, db.UpdateUserPasswordParams{
+		HashedPassword:    hashedPassword,
+		PasswordChangedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		Username:          params.Username,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, s.errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	if err := s.revokeUserSessions(ctx, user.Username, user.PasswordChangedAt.Time); err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, createUserResponse(user))
+}
+
+type adminGetAccountQuery struct {
+	Page    int32 `form:"page" binding:"omitempty,min=1"`
+	PerPage int32 `form:"per_page" binding:"omitempty,min=5,max=50"`
+}
+
+type AdminAccountResponse struct {
+	Account   AccountResponse    `json:"account"`
+	Entries   []EntryResponse    `json:"entries"`
+	Transfers []TransferResponse `json:"transfers"`
+}
+
+func (s *server) adminGetAccountHandler(ctx *gin.Context) {
+	var params getAccountParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	var query adminGetAccountQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	page := int32(1)
+	if query.Page != 0 {
+		page = query.Page
+	}
+
+	perPage := int32(20)
+	if query.PerPage != 0 {
+		perPage = query.PerPage
+	}
+
+	account, err := s.store.GetAccount(ctx, params.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, s.errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	entries, err := s.store.ListEntries(ctx, db.ListEntriesParams{
+		AccountID: account.ID,
+		Limit:     perPage,
+		Offset:    (page - 1) * perPage,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	transfers, err := s.store.ListTransfers(ctx, db.ListTransfersParams{
+		AccountID: account.ID,
+		Limit:     perPage,
+		Offset:    (page - 1) * perPage,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, AdminAccountResponse{
+		Account:   s.newAccountResponse(account),
+		Entries:   s.newEntriesResponse(entries, account.Currency),
+		Transfers: s.newTransfersResponse(transfers),
+	})
+}
+
+func (s *server) adminFreezeAccountHandler(ctx *gin.Context) {
+	s.setAccountStatus(ctx, utils.AccountStatusFrozen)
+}
+
+func (s *server) adminUnfreezeAccountHandler(ctx *gin.Context) {
+	s.setAccountStatus(ctx, utils.AccountStatusActive)
+}
+
+func (s *server) setAccountStatus(ctx *gin.Context, status string) {
+	var params getAccountParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	account, err := s.store.GetAccount(ctx, params.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, s.errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	if !utils.CanTransitionAccountStatus(account.Status, status) {
+		ctx.JSON(http.StatusConflict, s.errorResponse(fmt.Errorf("cannot change account status from %s to %s", account.Status, status)))
+		return
+	}
+
+	account, err = s.store.UpdateAccountStatus(ctx, db.UpdateAccountStatusParams{
+		Status:        status,
+		ID:            account.ID,
+		CurrentStatus: account.Status,
+	})
+	if err != nil {
+		// another request changed the status after we read the account
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusConflict, s.errorResponse(errors.New("account status changed, try again")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, s.newAccountResponse(account))
+}
+
+type setTransferLimitRequest struct {
+	Currency string `json:"currency" binding:"required,currency"`
+	// the limit is the one of the currency without Owner and AccountID, of
+	// the account of the user in the currency with Owner, of the account
+	// with AccountID
+	Owner     string `json:"owner,omitempty" binding:"omitempty,alphanum,excluded_with=AccountID"`
+	AccountID int32  `json:"account_id,omitempty" binding:"omitempty,min=1"`
+	// Limits are in the minor unit of Currency. A limit left out falls back
+	// to the one of the currency, or to no limit.
+	PerTransaction int64 `json:"per_transaction,omitempty" binding:"omitempty,gt=0"`
+	Daily          int64 `json:"daily,omitempty" binding:"omitempty,gt=0"`
+	Monthly        int64 `json:"monthly,omitempty" binding:"omitempty,gt=0"`
+}
+
+// adminSetTransferLimitHandler creates or replaces the outgoing limits of a
+// currency, of a user or of an account.
+func (s *server) adminSetTransferLimitHandler(ctx *gin.Context) {
+	var request setTransferLimitRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	if request.AccountID != 0 {
+		account, err := s.store.GetAccount(ctx, request.AccountID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				ctx.JSON(http.StatusNotFound, s.errorResponse(err))
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+			return
+		}
+		if account.Currency != request.Currency {
+			ctx.JSON(http.StatusBadRequest, s.errorResponse(fmt.Errorf("%w: account %d holds %s", db.ErrCurrencyMismatch, account.ID, account.Currency)))
+			return
+		}
+	}
+
+	if request.Owner != "" {
+		if _, err := s.store.GetUser(ctx, request.Owner); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				ctx.JSON(http.StatusNotFound, s.errorResponse(err))
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+			return
+		}
+	}
+
+	limit, err := s.store.UpsertTransferLimit(ctx, db.UpsertTransferLimitParams{
+		Currency:       request.Currency,
+		Owner:          pgtype.Text{String: request.Owner, Valid: request.Owner != ""},
+		AccountID:      pgtype.Int4{Int32: request.AccountID, Valid: request.AccountID != 0},
+		PerTransaction: pgtype.Int8{Int64: request.PerTransaction, Valid: request.PerTransaction != 0},
+		Daily:          pgtype.Int8{Int64: request.Daily, Valid: request.Daily != 0},
+		Monthly:        pgtype.Int8{Int64: request.Monthly, Valid: request.Monthly != 0},
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, limit)
+}
+
+type setFeeScheduleRequest struct {
+	Currency string `json:"currency" binding:"required,currency"`
+	// amounts are in the minor unit of Currency, MinFee and MaxFee are left
+	// out for no bound
+	Flat          int64 `json:"flat" binding:"min=0"`
+	PercentageBps int32 `json:"percentage_bps" binding:"min=0,max=10000"`
+	MinFee        int64 `json:"min_fee,omitempty" binding:"omitempty,gt=0"`
+	MaxFee        int64 `json:"max_fee,omitempty" binding:"omitempty,gt=0"`
+}
+
+// adminSetFeeScheduleHandler creates or replaces the fee schedule of a
+// currency. It applies to the transfers made from then on.
+func (s *server) adminSetFeeScheduleHandler(ctx *gin.Context) {
+	var request setFeeScheduleRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	if request.MinFee != 0 && request.MaxFee != 0 && request.MinFee > request.MaxFee {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(errors.New("min_fee must not be greater than max_fee")))
+		return
+	}
+
+	// transfers charged a fee would fail without an account to credit it to
+	if _, err := s.store.GetFeeAccount(ctx, request.Currency); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusUnprocessableEntity, s.errorResponse(fmt.Errorf("no fee account in %s", request.Currency)))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	schedule, err := s.store.UpsertFeeSchedule(ctx, db.UpsertFeeScheduleParams{
+		Currency:      request.Currency,
+		Flat:          request.Flat,
+		PercentageBps: request.PercentageBps,
+		MinFee:        pgtype.Int8{Int64: request.MinFee, Valid: request.MinFee != 0},
+		MaxFee:        pgtype.Int8{Int64: request.MaxFee, Valid: request.MaxFee != 0},
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, s.newFeeScheduleResponse(schedule))
+}
+
+type feeScheduleParams struct {
+	Currency string `uri:"currency" binding:"required,currency"`
+}
+
+// adminDeleteFeeScheduleHandler stops charging fees in a currency.
+func (s *server) adminDeleteFeeScheduleHandler(ctx *gin.Context) {
+	var params feeScheduleParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	if err := s.store.DeleteFeeSchedule(ctx, params.Currency); err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
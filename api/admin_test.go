@@ -0,0 +1,342 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/mohammad19khodaei/simple_bank/api"
+	"github.com/mohammad19khodaei/simple_bank/api/middlewares"
+	mockdb "github.com/mohammad19khodaei/simple_bank/db/mock"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/token"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAdminGetAccount(t *testing.T) {
+	account := createRandomAccount()
+
+	testCases := []struct {
+		name          string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "customer is forbidden",
+			role: utils.RoleCustomer,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "not found",
+			role: utils.RoleSupport,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(db.Account{}, pgx.ErrNoRows)
+				store.EXPECT().
+					ListEntries(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "ok",
+			role: utils.RoleSupport,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					ListEntries(gomock.Any(), gomock.Eq(db.ListEntriesParams{
+						AccountID: account.ID,
+						Limit:     20,
+						Offset:    0,
+					})).
+					Times(1).
+					Return([]db.Entry{{ID: 1, AccountID: account.ID, Amount: 10}}, nil)
+				store.EXPECT().
+					ListTransfers(gomock.Any(), gomock.Eq(db.ListTransfersParams{
+						AccountID: account.ID,
+						Limit:     20,
+						Offset:    0,
+					})).
+					Times(1).
+					Return([]db.Transfer{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp api.AdminAccountResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				require.NoError(t, err)
+				require.Equal(t, account, resp.Account.Account)
+				require.Equal(t, fmt.Sprintf("%d.%02d", account.Balance/100, account.Balance%100), resp.Account.FormattedBalance)
+				require.Len(t, resp.Entries, 1)
+				require.Empty(t, resp.Transfers)
+			},
+		},
+	}
+
+	tokenMaker, err := token.NewPasetoMaker(config.SecretKey)
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := newMockStore(ctrl)
+
+	server, err := api.NewServer(config, store)
+	require.NoError(t, err)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.buildStubs(store)
+
+			accessToken, _, err := tokenMaker.GenerateToken(utils.RandomOwner(), tc.role, config.TokenDuration, token.TokenTypeAccess)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			url := fmt.Sprintf("/admin/accounts/%d", account.ID)
+			request := httptest.NewRequest(http.MethodGet, url, nil)
+			request.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, accessToken))
+
+			server.Router().ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAdminFreezeAccount(t *testing.T) {
+	account := createRandomAccount()
+
+	tokenMaker, err := token.NewPasetoMaker(config.SecretKey)
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := newMockStore(ctrl)
+	store.EXPECT().
+		GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+		Times(1).
+		Return(account, nil)
+	store.EXPECT().
+		UpdateAccountStatus(gomock.Any(), gomock.Eq(db.UpdateAccountStatusParams{
+			Status:        utils.AccountStatusFrozen,
+			ID:            account.ID,
+			CurrentStatus: utils.AccountStatusActive,
+		})).
+		Times(1).
+		DoAndReturn(func(_ any, arg db.UpdateAccountStatusParams) (db.Account, error) {
+			account.Status = arg.Status
+			return account, nil
+		})
+
+	server, err := api.NewServer(config, store)
+	require.NoError(t, err)
+
+	accessToken, _, err := tokenMaker.GenerateToken(utils.RandomOwner(), utils.RoleSupport, config.TokenDuration, token.TokenTypeAccess)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	url := fmt.Sprintf("/admin/accounts/%d/freeze", account.ID)
+	request := httptest.NewRequest(http.MethodPost, url, nil)
+	request.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, accessToken))
+
+	server.Router().ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var gotAccount db.Account
+	err = json.Unmarshal(recorder.Body.Bytes(), &gotAccount)
+	require.NoError(t, err)
+	require.Equal(t, utils.AccountStatusFrozen, gotAccount.Status)
+}
+
+func TestAdminUnfreezeClosedAccount(t *testing.T) {
+	account := createRandomAccount()
+	account.Status = utils.AccountStatusClosed
+
+	tokenMaker, err := token.NewPasetoMaker(config.SecretKey)
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := newMockStore(ctrl)
+	store.EXPECT().
+		GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+		Times(1).
+		Return(account, nil)
+	store.EXPECT().
+		UpdateAccountStatus(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	server, err := api.NewServer(config, store)
+	require.NoError(t, err)
+
+	accessToken, _, err := tokenMaker.GenerateToken(utils.RandomOwner(), utils.RoleAdmin, config.TokenDuration, token.TokenTypeAccess)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	url := fmt.Sprintf("/admin/accounts/%d/unfreeze", account.ID)
+	request := httptest.NewRequest(http.MethodPost, url, nil)
+	request.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, accessToken))
+
+	server.Router().ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusConflict, recorder.Code)
+}
+
+func TestAdminFreezeUser(t *testing.T) {
+	user := createRandomUser(utils.RandomString(6))
+
+	tokenMaker, err := token.NewPasetoMaker(config.SecretKey)
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := newMockStore(ctrl)
+	store.EXPECT().
+		UpdateUserFrozen(gomock.Any(), gomock.Eq(db.UpdateUserFrozenParams{
+			IsFrozen: true,
+			Username: user.Username,
+		})).
+		Times(1).
+		DoAndReturn(func(_ any, arg db.UpdateUserFrozenParams) (db.User, error) {
+			user.IsFrozen = arg.IsFrozen
+			return user, nil
+		})
+	store.EXPECT().
+		BlockUserSessions(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(nil)
+
+	server, err := api.NewServer(config, store)
+	require.NoError(t, err)
+
+	staffToken, _, err := tokenMaker.GenerateToken(utils.RandomOwner(), utils.RoleSupport, config.TokenDuration, token.TokenTypeAccess)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	url := fmt.Sprintf("/admin/users/%s/freeze", user.Username)
+	request := httptest.NewRequest(http.MethodPost, url, nil)
+	request.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, staffToken))
+
+	server.Router().ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var resp api.UserResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &resp)
+	require.NoError(t, err)
+	require.True(t, resp.IsFrozen)
+}
+
+func TestAdminResetPassword(t *testing.T) {
+	user := createRandomUser(utils.RandomString(6))
+
+	testCases := []struct {
+		name          string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "support is forbidden",
+			role: utils.RoleSupport,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUserPassword(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "user not found",
+			role: utils.RoleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUserPassword(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, pgx.ErrNoRows)
+				store.EXPECT().
+					BlockUserSessions(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "ok",
+			role: utils.RoleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUserPassword(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.UpdateUserPasswordParams) (db.User, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.True(t, utils.IsHashPasswordValid(arg.HashedPassword, "new-secret"))
+						return user, nil
+					})
+				store.EXPECT().
+					BlockUserSessions(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+	}
+
+	tokenMaker, err := token.NewPasetoMaker(config.SecretKey)
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := newMockStore(ctrl)
+
+	server, err := api.NewServer(config, store)
+	require.NoError(t, err)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.buildStubs(store)
+
+			accessToken, _, err := tokenMaker.GenerateToken(utils.RandomOwner(), tc.role, config.TokenDuration, token.TokenTypeAccess)
+			require.NoError(t, err)
+
+			jsonData, err := json.Marshal(map[string]string{"new_password": "new-secret"})
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			url := fmt.Sprintf("/admin/users/%s/password", user.Username)
+			request := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(jsonData))
+			request.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, accessToken))
+
+			server.Router().ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listCurrenciesHandler lists the known currencies so clients can render
+// amounts, which are integers in the minor unit of their currency.
+func (s *server) listCurrenciesHandler(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, s.currencies.List())
+}
@@ -0,0 +1,104 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mohammad19khodaei/simple_bank/api"
+	"github.com/mohammad19khodaei/simple_bank/currency"
+	mockdb "github.com/mohammad19khodaei/simple_bank/db/mock"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestListCurrencies(t *testing.T) {
+	postgresConfig := config
+	postgresConfig.CurrencySource = "postgres"
+
+	testCases := []struct {
+		name          string
+		config        utils.Config
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, currencies []currency.Currency)
+	}{
+		{
+			name:   "static",
+			config: config,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListCurrencies(gomock.Any()).
+					Times(1).
+					Return(currencyRows(currency.Defaults()), nil)
+			},
+			checkResponse: func(t *testing.T, currencies []currency.Currency) {
+				require.Equal(t, currency.Defaults(), currencies)
+			},
+		},
+		{
+			name:   "postgres",
+			config: postgresConfig,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListCurrencies(gomock.Any()).
+					Times(1).
+					Return([]db.Currency{
+						{Code: "JPY", NumericCode: 392, MinorUnits: 0, Symbol: "¥", Name: "Yen", Enabled: false},
+						{Code: "USD", NumericCode: 840, MinorUnits: 2, Symbol: "$", Name: "US Dollar", Enabled: true},
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, currencies []currency.Currency) {
+				require.Len(t, currencies, 2)
+				require.Equal(t, "JPY", currencies[0].Code)
+				require.Zero(t, currencies[0].MinorUnits)
+				require.False(t, currencies[0].Enabled)
+				require.Equal(t, "USD", currencies[1].Code)
+				require.Equal(t, 2, currencies[1].MinorUnits)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := api.NewServer(tc.config, store)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/currencies", nil)
+
+			server.Router().ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusOK, recorder.Code)
+
+			var currencies []currency.Currency
+			err = json.Unmarshal(recorder.Body.Bytes(), &currencies)
+			require.NoError(t, err)
+			tc.checkResponse(t, currencies)
+		})
+	}
+}
+
+func TestNewServerMinorUnitsMismatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rows := currencyRows(currency.Defaults())
+	rows[0].MinorUnits = 3
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListCurrencies(gomock.Any()).
+		Times(1).
+		Return(rows, nil)
+
+	_, err := api.NewServer(config, store)
+	require.ErrorIs(t, err, currency.ErrInvalidCurrency)
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+)
+
+type cashRequest struct {
+	Amount int64 `json:"amount" binding:"required,gt=0"`
+}
+
+func (s *server) depositHandler(ctx *gin.Context) {
+	var params getAccountParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	var request cashRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	result, err := s.store.DepositTx(ctx, db.DepositTxParams{
+		AccountID: params.ID,
+		Amount:    request.Amount,
+	})
+	if err != nil {
+		ctx.JSON(transferErrorStatus(err), s.errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, s.newTransferTxResponse(result))
+}
+
+func (s *server) withdrawHandler(ctx *gin.Context) {
+	var params getAccountParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	var request cashRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	result, err := s.store.WithdrawTx(ctx, db.WithdrawTxParams{
+		AccountID: params.ID,
+		Amount:    request.Amount,
+	})
+	if err != nil {
+		ctx.JSON(transferErrorStatus(err), s.errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, s.newTransferTxResponse(result))
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mohammad19khodaei/simple_bank/api/middlewares"
+	"github.com/mohammad19khodaei/simple_bank/api/policies"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/token"
+)
+
+type listAccountEntriesQuery struct {
+	From    time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To      time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Page    int32     `form:"page" binding:"omitempty,min=1"`
+	PerPage int32     `form:"per_page" binding:"omitempty,min=5,max=50"`
+}
+
+// listAccountEntriesHandler returns the statement of an account, newest line
+// first, with the balance of the account right after each entry.
+func (s *server) listAccountEntriesHandler(ctx *gin.Context) {
+	var params getAccountParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	var query listAccountEntriesQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(errors.New("from must be before to")))
+		return
+	}
+
+	page := int32(1)
+	if query.Page != 0 {
+		page = query.Page
+	}
+
+	perPage := int32(20)
+	if query.PerPage != 0 {
+		perPage = query.PerPage
+	}
+
+	account, err := s.store.GetAccount(ctx, params.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, s.errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	payload := ctx.MustGet(middlewares.AuthPayloadKey).(*token.Payload)
+	if !policies.CanReadStatement(payload, account) {
+		ctx.JSON(http.StatusForbidden, s.errorResponse(errors.New("forbidden: account does not belong to you")))
+		return
+	}
+
+	entries, err := s.store.ListAccountStatement(ctx, db.ListAccountStatementParams{
+		AccountID: account.ID,
+		FromTime:  pgtype.Timestamptz{Time: query.From, Valid: !query.From.IsZero()},
+		ToTime:    pgtype.Timestamptz{Time: query.To, Valid: !query.To.IsZero()},
+		Limit:     perPage,
+		Offset:    (page - 1) * perPage,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	resp := make([]StatementLineResponse, 0, len(entries))
+	for _, entry := range entries {
+		resp = append(resp, StatementLineResponse{
+			ListAccountStatementRow: entry,
+			FormattedAmount:         s.formatAmount(entry.Amount, account.Currency),
+			FormattedRunningBalance: s.formatAmount(entry.RunningBalance, account.Currency),
+		})
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// listFeeSchedulesHandler lists the fee schedules, a currency without one
+// charges no fee.
+func (s *server) listFeeSchedulesHandler(ctx *gin.Context) {
+	schedules, err := s.store.ListFeeSchedules(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, s.newFeeSchedulesResponse(schedules))
+}
+
+type feeQuoteQuery struct {
+	// Currency is the one of the from account, fees are charged in it.
+	Currency string `form:"currency" binding:"required,currency"`
+	// Amount and AmountDecimal work as on POST /transfer, exactly one of
+	// them must be sent.
+	Amount        int64  `form:"amount" binding:"omitempty,gt=0"`
+	AmountDecimal string `form:"amount_decimal" binding:"required_without=Amount,excluded_with=Amount"`
+}
+
+// feeQuoteHandler previews the fee of a transfer before it is made. The fee
+// is computed again when the transfer is made, with the schedule in effect
+// then.
+func (s *server) feeQuoteHandler(ctx *gin.Context) {
+	var query feeQuoteQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	amount := query.Amount
+	if query.AmountDecimal != "" {
+		var err error
+		amount, err = s.parseAmount(query.AmountDecimal, query.Currency)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+			return
+		}
+	}
+
+	schedule, err := s.store.GetFeeSchedule(ctx, query.Currency)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	// without a schedule the zero one charges nothing
+	fee := schedule.Fee(amount)
+
+	ctx.JSON(http.StatusOK, FeeQuoteResponse{
+		Currency:        query.Currency,
+		Amount:          amount,
+		Fee:             fee,
+		Total:           amount + fee,
+		FormattedAmount: s.formatAmount(amount, query.Currency),
+		FormattedFee:    s.formatAmount(fee, query.Currency),
+		FormattedTotal:  s.formatAmount(amount+fee, query.Currency),
+	})
+}
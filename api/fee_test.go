@@ -0,0 +1,245 @@
+package api_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mohammad19khodaei/simple_bank/api"
+	mockdb "github.com/mohammad19khodaei/simple_bank/db/mock"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestFeeQuote(t *testing.T) {
+	schedule := db.FeeSchedule{
+		Currency:      "USD",
+		Flat:          25,
+		PercentageBps: 100,
+		MaxFee:        pgtype.Int8{Int64: 1000, Valid: true},
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "currency=USD&amount=10000",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetFeeSchedule(gomock.Any(), gomock.Eq("USD")).Times(1).Return(schedule, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp api.FeeQuoteResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				require.NoError(t, err)
+				require.Equal(t, int64(10000), resp.Amount)
+				require.Equal(t, int64(125), resp.Fee)
+				require.Equal(t, int64(10125), resp.Total)
+				require.Equal(t, "1.25", resp.FormattedFee)
+				require.Equal(t, "101.25", resp.FormattedTotal)
+			},
+		},
+		{
+			name:  "amount decimal",
+			query: "currency=USD&amount_decimal=5000.00",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetFeeSchedule(gomock.Any(), gomock.Eq("USD")).Times(1).Return(schedule, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp api.FeeQuoteResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				require.NoError(t, err)
+				require.Equal(t, int64(500000), resp.Amount)
+				require.Equal(t, int64(1000), resp.Fee)
+			},
+		},
+		{
+			name:  "no schedule",
+			query: "currency=EUR&amount=10000",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetFeeSchedule(gomock.Any(), gomock.Eq("EUR")).Times(1).Return(db.FeeSchedule{}, pgx.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp api.FeeQuoteResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				require.NoError(t, err)
+				require.Zero(t, resp.Fee)
+				require.Equal(t, int64(10000), resp.Total)
+			},
+		},
+		{
+			name:  "amount and amount decimal",
+			query: "currency=USD&amount=100&amount_decimal=1.00",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetFeeSchedule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "unknown currency",
+			query: "currency=XYZ&amount=100",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetFeeSchedule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "internal error",
+			query: "currency=USD&amount=100",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetFeeSchedule(gomock.Any(), gomock.Any()).Times(1).Return(db.FeeSchedule{}, errors.New("db down"))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := serveHoldRequest(t, utils.RandomOwner(), utils.RoleCustomer, http.MethodGet, "/fees/quote?"+tc.query, nil, tc.buildStubs)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAdminSetFeeSchedule(t *testing.T) {
+	testCases := []struct {
+		name          string
+		role          string
+		body          map[string]any
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			role: utils.RoleAdmin,
+			body: map[string]any{
+				"currency":       "USD",
+				"flat":           25,
+				"percentage_bps": 150,
+				"max_fee":        1000,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetFeeAccount(gomock.Any(), gomock.Eq("USD")).Times(1).Return(db.Account{ID: 1, Kind: utils.AccountKindFee}, nil)
+				store.EXPECT().
+					UpsertFeeSchedule(gomock.Any(), gomock.Eq(db.UpsertFeeScheduleParams{
+						Currency:      "USD",
+						Flat:          25,
+						PercentageBps: 150,
+						MaxFee:        pgtype.Int8{Int64: 1000, Valid: true},
+					})).
+					Times(1).
+					Return(db.FeeSchedule{
+						Currency:      "USD",
+						Flat:          25,
+						PercentageBps: 150,
+						MaxFee:        pgtype.Int8{Int64: 1000, Valid: true},
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp api.FeeScheduleResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				require.NoError(t, err)
+				require.Equal(t, "0.25", resp.FormattedFlat)
+				require.Equal(t, "10.00", resp.FormattedMaxFee)
+				require.Empty(t, resp.FormattedMinFee)
+			},
+		},
+		{
+			name: "min fee over max fee",
+			role: utils.RoleAdmin,
+			body: map[string]any{
+				"currency": "USD",
+				"min_fee":  500,
+				"max_fee":  100,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertFeeSchedule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "percentage over 100",
+			role: utils.RoleAdmin,
+			body: map[string]any{
+				"currency":       "USD",
+				"percentage_bps": 10001,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertFeeSchedule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "no fee account",
+			role: utils.RoleAdmin,
+			body: map[string]any{
+				"currency": "EUR",
+				"flat":     10,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetFeeAccount(gomock.Any(), gomock.Eq("EUR")).Times(1).Return(db.Account{}, pgx.ErrNoRows)
+				store.EXPECT().UpsertFeeSchedule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "support is forbidden",
+			role: utils.RoleSupport,
+			body: map[string]any{
+				"currency": "USD",
+				"flat":     10,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertFeeSchedule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := serveHoldRequest(t, utils.RandomOwner(), tc.role, http.MethodPut, "/admin/fee-schedules", tc.body, tc.buildStubs)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAdminDeleteFeeSchedule(t *testing.T) {
+	buildStubs := func(store *mockdb.MockStore) {
+		store.EXPECT().DeleteFeeSchedule(gomock.Any(), gomock.Eq("USD")).Times(1).Return(nil)
+	}
+
+	recorder := serveHoldRequest(t, utils.RandomOwner(), utils.RoleAdmin, http.MethodDelete, "/admin/fee-schedules/USD", nil, buildStubs)
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mohammad19khodaei/simple_bank/api/middlewares"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/fx"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+)
+
+type createFxQuoteRequest struct {
+	FromCurrency string `json:"from_currency" binding:"required,currency"`
+	ToCurrency   string `json:"to_currency" binding:"required,currency,nefield=FromCurrency"`
+}
+
+type FxQuoteResponse struct {
+	ID           string    `json:"id"`
+	FromCurrency string    `json:"from_currency"`
+	ToCurrency   string    `json:"to_currency"`
+	Rate         string    `json:"rate"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// createFxQuoteHandler locks the current rate between two currencies for a
+// short window. The quote is passed to POST /transfer as quote_id to move
+// money between accounts of different currencies.
+func (s *server) createFxQuoteHandler(ctx *gin.Context) {
+	var request createFxQuoteRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	rate, err := s.rateProvider.Rate(ctx, request.FromCurrency, request.ToCurrency)
+	if err != nil {
+		if errors.Is(err, fx.ErrRateNotFound) {
+			ctx.JSON(http.StatusUnprocessableEntity, s.errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	value, err := utils.RatToNumeric(rate.Value)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	quote, err := s.store.CreateFxQuote(ctx, db.CreateFxQuoteParams{
+		ID:           pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Username:     ctx.MustGet(middlewares.AuthUsernameKey).(string),
+		FromCurrency: request.FromCurrency,
+		ToCurrency:   request.ToCurrency,
+		Rate:         value,
+		ExpiresAt:    pgtype.Timestamptz{Time: time.Now().Add(s.config.FxQuoteDuration), Valid: true},
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	response, err := newFxQuoteResponse(quote)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+func newFxQuoteResponse(quote db.FxQuote) (FxQuoteResponse, error) {
+	rate, err := utils.NumericToRat(quote.Rate)
+	if err != nil {
+		return FxQuoteResponse{}, err
+	}
+
+	return FxQuoteResponse{
+		ID:           uuid.UUID(quote.ID.Bytes).String(),
+		FromCurrency: quote.FromCurrency,
+		ToCurrency:   quote.ToCurrency,
+		Rate:         utils.FormatRate(rate),
+		ExpiresAt:    quote.ExpiresAt.Time,
+	}, nil
+}
@@ -0,0 +1,184 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/mohammad19khodaei/simple_bank/api"
+	"github.com/mohammad19khodaei/simple_bank/api/middlewares"
+	mockdb "github.com/mohammad19khodaei/simple_bank/db/mock"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/token"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateFxQuote(t *testing.T) {
+	username := utils.RandomOwner()
+
+	rate, err := utils.ParseRate("0.92")
+	require.NoError(t, err)
+	numericRate, err := utils.RatToNumeric(rate)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		body          map[string]string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: map[string]string{"from_currency": "USD", "to_currency": "EUR"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetFxRate(gomock.Any(), gomock.Eq(db.GetFxRateParams{BaseCurrency: "USD", QuoteCurrency: "EUR"})).
+					Times(1).
+					Return(db.FxRate{BaseCurrency: "USD", QuoteCurrency: "EUR", Rate: numericRate}, nil)
+
+				store.EXPECT().
+					CreateFxQuote(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateFxQuoteParams) (db.FxQuote, error) {
+						require.Equal(t, username, arg.Username)
+						require.Equal(t, "USD", arg.FromCurrency)
+						require.Equal(t, "EUR", arg.ToCurrency)
+						require.WithinDuration(t, time.Now().Add(config.FxQuoteDuration), arg.ExpiresAt.Time, time.Second)
+
+						return db.FxQuote{
+							ID:           arg.ID,
+							Username:     arg.Username,
+							FromCurrency: arg.FromCurrency,
+							ToCurrency:   arg.ToCurrency,
+							Rate:         arg.Rate,
+							ExpiresAt:    arg.ExpiresAt,
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var quote api.FxQuoteResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &quote)
+				require.NoError(t, err)
+				require.NotEmpty(t, quote.ID)
+				require.Equal(t, "USD", quote.FromCurrency)
+				require.Equal(t, "EUR", quote.ToCurrency)
+				require.Equal(t, "0.92", quote.Rate)
+			},
+		},
+		{
+			name: "inverse rate",
+			body: map[string]string{"from_currency": "EUR", "to_currency": "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetFxRate(gomock.Any(), gomock.Eq(db.GetFxRateParams{BaseCurrency: "EUR", QuoteCurrency: "USD"})).
+					Times(1).
+					Return(db.FxRate{}, pgx.ErrNoRows)
+
+				store.EXPECT().
+					GetFxRate(gomock.Any(), gomock.Eq(db.GetFxRateParams{BaseCurrency: "USD", QuoteCurrency: "EUR"})).
+					Times(1).
+					Return(db.FxRate{BaseCurrency: "USD", QuoteCurrency: "EUR", Rate: numericRate}, nil)
+
+				store.EXPECT().
+					CreateFxQuote(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateFxQuoteParams) (db.FxQuote, error) {
+						return db.FxQuote{
+							ID:           arg.ID,
+							FromCurrency: arg.FromCurrency,
+							ToCurrency:   arg.ToCurrency,
+							Rate:         arg.Rate,
+							ExpiresAt:    arg.ExpiresAt,
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var quote api.FxQuoteResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &quote)
+				require.NoError(t, err)
+				require.Equal(t, "1.0869565217", quote.Rate)
+			},
+		},
+		{
+			name: "rate not found",
+			body: map[string]string{"from_currency": "USD", "to_currency": "IRR"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetFxRate(gomock.Any(), gomock.Any()).
+					Times(2).
+					Return(db.FxRate{}, pgx.ErrNoRows)
+
+				store.EXPECT().
+					CreateFxQuote(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "same currency",
+			body: map[string]string{"from_currency": "USD", "to_currency": "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetFxRate(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "unsupported currency",
+			body: map[string]string{"from_currency": "USD", "to_currency": "GBP"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetFxRate(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	tokenMaker, err := token.NewPasetoMaker(config.SecretKey)
+	require.NoError(t, err)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := newMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := api.NewServer(config, store)
+			require.NoError(t, err)
+
+			accessToken, _, err := tokenMaker.GenerateToken(username, utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
+			require.NoError(t, err)
+
+			jsonData, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/fx/quotes", bytes.NewReader(jsonData))
+			request.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, accessToken))
+
+			server.Router().ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/mohammad19khodaei/simple_bank/api/middlewares"
+	"github.com/mohammad19khodaei/simple_bank/api/policies"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/token"
+)
+
+type createHoldRequest struct {
+	AccountID   int32 `json:"account_id" binding:"required,min=1"`
+	ToAccountID int32 `json:"to_account_id" binding:"required,min=1,nefield=AccountID"`
+	// Amount and AmountDecimal work as on POST /transfer, exactly one of
+	// them must be sent.
+	Amount        int64  `json:"amount,omitempty" binding:"omitempty,gt=0"`
+	AmountDecimal string `json:"amount_decimal,omitempty" binding:"required_without=Amount,excluded_with=Amount"`
+	Description   string `json:"description,omitempty" binding:"omitempty,max=255"`
+	Reference     string `json:"reference,omitempty" binding:"omitempty,max=64"`
+}
+
+// createHoldHandler reserves money on an account of the caller for the to
+// account to capture later. The hold is released when it is voided or
+// expires after HOLD_DURATION.
+func (s *server) createHoldHandler(ctx *gin.Context) {
+	var request createHoldRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	account, err := s.store.GetAccount(ctx, request.AccountID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, s.errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	payload := ctx.MustGet(middlewares.AuthPayloadKey).(*token.Payload)
+	if !policies.CanDebitAccount(payload, account) {
+		ctx.JSON(http.StatusForbidden, s.errorResponse(errors.New("forbidden: account does not belong to you")))
+		return
+	}
+
+	amount := request.Amount
+	if request.AmountDecimal != "" {
+		amount, err = s.parseAmount(request.AmountDecimal, account.Currency)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+			return
+		}
+	}
+
+	// balance, currency and status are checked by HoldTx on the locked accounts
+	result, err := s.store.HoldTx(ctx, db.HoldTxParams{
+		AccountID:   account.ID,
+		ToAccountID: request.ToAccountID,
+		Amount:      amount,
+		Description: request.Description,
+		Reference:   request.Reference,
+		ExpiresAt:   time.Now().Add(s.config.HoldDuration),
+	})
+	if err != nil {
+		ctx.JSON(transferErrorStatus(err), s.errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, s.newHoldTxResponse(result))
+}
+
+type getHoldParams struct {
+	ID int32 `uri:"id" binding:"required,min=1"`
+}
+
+func (s *server) getHoldHandler(ctx *gin.Context) {
+	var params getHoldParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	hold, ok := s.loadHold(ctx, params.ID, false)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, s.newHoldResponse(hold))
+}
+
+type captureHoldRequest struct {
+	// Amount and AmountDecimal are at most the held amount, without either
+	// the whole hold is captured.
+	Amount        int64  `json:"amount,omitempty" binding:"omitempty,gt=0"`
+	AmountDecimal string `json:"amount_decimal,omitempty" binding:"excluded_with=Amount"`
+}
+
+// captureHoldHandler moves the held money, or part of it, to the to account.
+func (s *server) captureHoldHandler(ctx *gin.Context) {
+	var params getHoldParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	// the body is optional, without one the whole hold is captured
+	var request captureHoldRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	hold, ok := s.loadHold(ctx, params.ID, true)
+	if !ok {
+		return
+	}
+
+	amount := request.Amount
+	if request.AmountDecimal != "" {
+		var err error
+		amount, err = s.parseAmount(request.AmountDecimal, hold.Currency)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+			return
+		}
+	}
+
+	// status, expiry and the held amount are checked by CaptureTx on the
+	// locked hold
+	result, err := s.store.CaptureTx(ctx, db.CaptureTxParams{
+		HoldID: hold.ID,
+		Amount: amount,
+	})
+	if err != nil {
+		ctx.JSON(transferErrorStatus(err), s.errorResponse(err))
+		return
+	}
+
+	resp, err := s.newCaptureTxResponse(ctx, result)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, resp)
+}
+
+// voidHoldHandler cancels a hold and gives the money back to the available
+// balance of the account it was held on.
+func (s *server) voidHoldHandler(ctx *gin.Context) {
+	var params getHoldParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	hold, ok := s.loadHold(ctx, params.ID, true)
+	if !ok {
+		return
+	}
+
+	result, err := s.store.VoidTx(ctx, hold.ID)
+	if err != nil {
+		ctx.JSON(transferErrorStatus(err), s.errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, s.newHoldTxResponse(result))
+}
+
+// loadHold fetches a hold the caller may read, or settle when settle is set,
+// and reports false after writing the error response when it cannot.
+func (s *server) loadHold(ctx *gin.Context, id int32, settle bool) (db.Hold, bool) {
+	hold, err := s.store.GetHold(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, s.errorResponse(err))
+			return db.Hold{}, false
+		}
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return db.Hold{}, false
+	}
+
+	account, err := s.store.GetAccount(ctx, hold.AccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return db.Hold{}, false
+	}
+
+	toAccount, err := s.store.GetAccount(ctx, hold.ToAccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return db.Hold{}, false
+	}
+
+	payload := ctx.MustGet(middlewares.AuthPayloadKey).(*token.Payload)
+	if settle && policies.CanSettleHold(payload, toAccount) {
+		return hold, true
+	}
+	if !policies.CanReadHold(payload, account, toAccount) {
+		// do not reveal that a hold with this id exists
+		ctx.JSON(http.StatusNotFound, s.errorResponse(pgx.ErrNoRows))
+		return db.Hold{}, false
+	}
+	if settle {
+		ctx.JSON(http.StatusForbidden, s.errorResponse(errors.New("forbidden: only the recipient can settle a hold")))
+		return db.Hold{}, false
+	}
+
+	return hold, true
+}
@@ -0,0 +1,374 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/mohammad19khodaei/simple_bank/api"
+	"github.com/mohammad19khodaei/simple_bank/api/middlewares"
+	mockdb "github.com/mohammad19khodaei/simple_bank/db/mock"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/token"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateHold(t *testing.T) {
+	account := createRandomAccount("USD")
+	toAccount := createRandomAccount("USD")
+
+	testCases := []struct {
+		name          string
+		username      string
+		body          map[string]any
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: account.Owner,
+			body: map[string]any{
+				"account_id":     account.ID,
+				"to_account_id":  toAccount.ID,
+				"amount_decimal": "12.50",
+				"reference":      "order-42",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+				heldAccount := account
+				heldAccount.HeldAmount = 1250
+				heldAccount.AvailableBalance = account.Balance - 1250
+				store.EXPECT().
+					HoldTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, params db.HoldTxParams) (db.HoldTxResult, error) {
+						require.Equal(t, account.ID, params.AccountID)
+						require.Equal(t, toAccount.ID, params.ToAccountID)
+						require.Equal(t, int64(1250), params.Amount)
+						require.Equal(t, "order-42", params.Reference)
+						require.WithinDuration(t, time.Now().Add(config.HoldDuration), params.ExpiresAt, time.Minute)
+
+						return db.HoldTxResult{
+							Hold:    db.Hold{ID: 1, Amount: 1250, Currency: "USD", Status: utils.HoldStatusPending},
+							Account: heldAccount,
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var resp api.HoldTxResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				require.NoError(t, err)
+				require.Equal(t, "12.50", resp.Hold.FormattedAmount)
+				require.Equal(t, account.Balance, resp.Account.Balance)
+				require.Equal(t, account.Balance-1250, resp.Account.AvailableBalance)
+			},
+		},
+		{
+			name:     "account of another user",
+			username: toAccount.Owner,
+			body: map[string]any{
+				"account_id":    account.ID,
+				"to_account_id": toAccount.ID,
+				"amount":        100,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().HoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "insufficient available balance",
+			username: account.Owner,
+			body: map[string]any{
+				"account_id":    account.ID,
+				"to_account_id": toAccount.ID,
+				"amount":        100,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().HoldTx(gomock.Any(), gomock.Any()).Times(1).Return(db.HoldTxResult{}, db.ErrInsufficientBalance)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPaymentRequired, recorder.Code)
+			},
+		},
+		{
+			name:     "same account",
+			username: account.Owner,
+			body: map[string]any{
+				"account_id":    account.ID,
+				"to_account_id": account.ID,
+				"amount":        100,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := serveHoldRequest(t, tc.username, utils.RoleCustomer, http.MethodPost, "/holds", tc.body, tc.buildStubs)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestSettleHold(t *testing.T) {
+	account := createRandomAccount("USD")
+	toAccount := createRandomAccount("USD")
+	hold := db.Hold{
+		ID:          int32(utils.RandomInt(1, 1000)),
+		AccountID:   account.ID,
+		ToAccountID: toAccount.ID,
+		Amount:      1000,
+		Currency:    "USD",
+		Status:      utils.HoldStatusPending,
+	}
+
+	stubHold := func(store *mockdb.MockStore) {
+		store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		role          string
+		method        string
+		path          string
+		body          map[string]any
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "payer reads the hold",
+			username: account.Owner,
+			role:     utils.RoleCustomer,
+			method:   http.MethodGet,
+			path:     fmt.Sprintf("/holds/%d", hold.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				stubHold(store)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp api.HoldResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				require.NoError(t, err)
+				require.Equal(t, hold, resp.Hold)
+				require.Equal(t, "10.00", resp.FormattedAmount)
+			},
+		},
+		{
+			name:     "partial capture",
+			username: toAccount.Owner,
+			role:     utils.RoleCustomer,
+			method:   http.MethodPost,
+			path:     fmt.Sprintf("/holds/%d/capture", hold.ID),
+			body:     map[string]any{"amount": 600},
+			buildStubs: func(store *mockdb.MockStore) {
+				stubHold(store)
+				store.EXPECT().
+					CaptureTx(gomock.Any(), gomock.Eq(db.CaptureTxParams{HoldID: hold.ID, Amount: 600})).
+					Times(1).
+					Return(db.CaptureTxResult{
+						TransferTxResult: db.TransferTxResult{FromAccount: account, ToAccount: toAccount},
+					}, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(account.Owner)).
+					Times(1).
+					Return(db.User{Username: account.Owner, FullName: "John Smith"}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				// the payer is only shown by number and masked name
+				var resp api.CaptureTxResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				require.NoError(t, err)
+				require.Nil(t, resp.FromAccount)
+				require.Equal(t, account.Number, resp.Counterparty.AccountNumber)
+				require.Equal(t, "J*** S****", resp.Counterparty.Name)
+				require.Equal(t, toAccount.ID, resp.ToAccount.ID)
+			},
+		},
+		{
+			name:     "full capture by an admin",
+			username: utils.RandomOwner(),
+			role:     utils.RoleAdmin,
+			method:   http.MethodPost,
+			path:     fmt.Sprintf("/holds/%d/capture", hold.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				stubHold(store)
+				store.EXPECT().
+					CaptureTx(gomock.Any(), gomock.Eq(db.CaptureTxParams{HoldID: hold.ID})).
+					Times(1).
+					Return(db.CaptureTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name:     "payer cannot capture",
+			username: account.Owner,
+			role:     utils.RoleCustomer,
+			method:   http.MethodPost,
+			path:     fmt.Sprintf("/holds/%d/capture", hold.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				stubHold(store)
+				store.EXPECT().CaptureTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "capture more than held",
+			username: toAccount.Owner,
+			role:     utils.RoleCustomer,
+			method:   http.MethodPost,
+			path:     fmt.Sprintf("/holds/%d/capture", hold.ID),
+			body:     map[string]any{"amount": 1001},
+			buildStubs: func(store *mockdb.MockStore) {
+				stubHold(store)
+				store.EXPECT().CaptureTx(gomock.Any(), gomock.Any()).Times(1).Return(db.CaptureTxResult{}, db.ErrCaptureExceedsHold)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "capture expired hold",
+			username: toAccount.Owner,
+			role:     utils.RoleCustomer,
+			method:   http.MethodPost,
+			path:     fmt.Sprintf("/holds/%d/capture", hold.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				stubHold(store)
+				store.EXPECT().CaptureTx(gomock.Any(), gomock.Any()).Times(1).Return(db.CaptureTxResult{}, db.ErrHoldExpired)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:     "void",
+			username: toAccount.Owner,
+			role:     utils.RoleCustomer,
+			method:   http.MethodPost,
+			path:     fmt.Sprintf("/holds/%d/void", hold.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				stubHold(store)
+				store.EXPECT().VoidTx(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(db.HoldTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "void settled hold",
+			username: toAccount.Owner,
+			role:     utils.RoleCustomer,
+			method:   http.MethodPost,
+			path:     fmt.Sprintf("/holds/%d/void", hold.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				stubHold(store)
+				store.EXPECT().VoidTx(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(db.HoldTxResult{}, db.ErrHoldNotPending)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "hold of other customers",
+			username: utils.RandomOwner(),
+			role:     utils.RoleCustomer,
+			method:   http.MethodPost,
+			path:     fmt.Sprintf("/holds/%d/void", hold.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				stubHold(store)
+				store.EXPECT().VoidTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "not found",
+			username: account.Owner,
+			role:     utils.RoleCustomer,
+			method:   http.MethodGet,
+			path:     fmt.Sprintf("/holds/%d", hold.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(db.Hold{}, pgx.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := serveHoldRequest(t, tc.username, tc.role, tc.method, tc.path, tc.body, tc.buildStubs)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func serveHoldRequest(
+	t *testing.T,
+	username string,
+	role string,
+	method string,
+	path string,
+	body map[string]any,
+	buildStubs func(store *mockdb.MockStore),
+) *httptest.ResponseRecorder {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := newMockStore(ctrl)
+	buildStubs(store)
+
+	server, err := api.NewServer(config, store)
+	require.NoError(t, err)
+
+	tokenMaker, err := token.NewPasetoMaker(config.SecretKey)
+	require.NoError(t, err)
+
+	accessToken, _, err := tokenMaker.GenerateToken(username, role, config.TokenDuration, token.TokenTypeAccess)
+	require.NoError(t, err)
+
+	var data bytes.Buffer
+	if body != nil {
+		err = json.NewEncoder(&data).Encode(body)
+		require.NoError(t, err)
+	}
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(method, path, &data)
+	request.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, accessToken))
+
+	server.Router().ServeHTTP(recorder, request)
+	return recorder
+}
@@ -0,0 +1,24 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mohammad19khodaei/simple_bank/token"
+)
+
+type PublicKeysResponse struct {
+	Keys []token.JWK `json:"keys"`
+}
+
+// publicKeysHandler publishes the keys other services need to verify our
+// tokens. Symmetric makers have nothing to publish.
+func (s *server) publicKeysHandler(ctx *gin.Context) {
+	keys := []token.JWK{}
+	if provider, ok := s.tokenMaker.(token.PublicKeyProvider); ok {
+		keys = provider.PublicKeys()
+	}
+
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, PublicKeysResponse{Keys: keys})
+}
@@ -0,0 +1,73 @@
+package api_test
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mohammad19khodaei/simple_bank/api"
+	"github.com/mohammad19khodaei/simple_bank/token"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPublicKeys(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	publicConfig := config
+	publicConfig.TokenType = token.TypePasetoPublic
+	publicConfig.TokenPrivateKey = base64.StdEncoding.EncodeToString(privateKey.Seed())
+	publicConfig.TokenPrivateKeyID = "k1"
+
+	testCases := []struct {
+		name          string
+		config        utils.Config
+		checkResponse func(t *testing.T, resp api.PublicKeysResponse)
+	}{
+		{
+			name:   "symmetric maker",
+			config: config,
+			checkResponse: func(t *testing.T, resp api.PublicKeysResponse) {
+				require.Empty(t, resp.Keys)
+			},
+		},
+		{
+			name:   "public maker",
+			config: publicConfig,
+			checkResponse: func(t *testing.T, resp api.PublicKeysResponse) {
+				require.Len(t, resp.Keys, 1)
+				require.Equal(t, "k1", resp.Keys[0].KeyID)
+				require.Equal(t, "OKP", resp.Keys[0].KeyType)
+				require.Equal(t, base64.RawURLEncoding.EncodeToString(publicKey), resp.Keys[0].X)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := newMockStore(ctrl)
+
+			server, err := api.NewServer(tc.config, store)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/.well-known/keys", nil)
+
+			server.Router().ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusOK, recorder.Code)
+
+			var resp api.PublicKeysResponse
+			err = json.Unmarshal(recorder.Body.Bytes(), &resp)
+			require.NoError(t, err)
+			tc.checkResponse(t, resp)
+		})
+	}
+}
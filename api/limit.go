@@ -0,0 +1,46 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/mohammad19khodaei/simple_bank/api/middlewares"
+	"github.com/mohammad19khodaei/simple_bank/api/policies"
+	"github.com/mohammad19khodaei/simple_bank/token"
+)
+
+// getAccountLimitsHandler shows the outgoing limits in effect on an account
+// and how much of the daily and monthly ones is left.
+func (s *server) getAccountLimitsHandler(ctx *gin.Context) {
+	var params getAccountParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	account, err := s.store.GetAccount(ctx, params.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, s.errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	payload := ctx.MustGet(middlewares.AuthPayloadKey).(*token.Payload)
+	if !policies.CanReadAccount(payload, account) {
+		ctx.JSON(http.StatusForbidden, s.errorResponse(errors.New("forbidden: account does not belong to you")))
+		return
+	}
+
+	limits, err := s.store.GetAccountLimits(ctx, account)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, s.newAccountLimitsResponse(account, limits))
+}
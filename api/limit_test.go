@@ -0,0 +1,252 @@
+package api_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mohammad19khodaei/simple_bank/api"
+	mockdb "github.com/mohammad19khodaei/simple_bank/db/mock"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetAccountLimits(t *testing.T) {
+	account := createRandomAccount("USD")
+
+	testCases := []struct {
+		name          string
+		username      string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: account.Owner,
+			role:     utils.RoleCustomer,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetAccountLimits(gomock.Any(), gomock.Eq(account)).
+					Times(1).
+					Return(db.AccountLimits{
+						PerTransaction: pgtype.Int8{Int64: 50000, Valid: true},
+						Daily:          pgtype.Int8{Int64: 100000, Valid: true},
+						DailySpent:     120000,
+						MonthlySpent:   120000,
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp api.AccountLimitsResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				require.NoError(t, err)
+				require.Equal(t, account.ID, resp.AccountID)
+				require.Equal(t, "500.00", resp.PerTransaction.FormattedLimit)
+				require.Equal(t, int64(100000), resp.Daily.Limit)
+				require.Zero(t, resp.Daily.Remaining)
+				require.Equal(t, "0.00", resp.Daily.FormattedRemaining)
+				require.Nil(t, resp.Monthly)
+			},
+		},
+		{
+			name:     "staff",
+			username: utils.RandomOwner(),
+			role:     utils.RoleSupport,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountLimits(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountLimits{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "account of another user",
+			username: utils.RandomOwner(),
+			role:     utils.RoleCustomer,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountLimits(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "not found",
+			username: account.Owner,
+			role:     utils.RoleCustomer,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, pgx.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fmt.Sprintf("/accounts/%d/limits", account.ID)
+			recorder := serveHoldRequest(t, tc.username, tc.role, http.MethodGet, path, nil, tc.buildStubs)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAdminSetTransferLimit(t *testing.T) {
+	account := createRandomAccount("USD")
+
+	testCases := []struct {
+		name          string
+		role          string
+		body          map[string]any
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "account",
+			role: utils.RoleAdmin,
+			body: map[string]any{
+				"currency":   "USD",
+				"account_id": account.ID,
+				"daily":      100000,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					UpsertTransferLimit(gomock.Any(), gomock.Eq(db.UpsertTransferLimitParams{
+						Currency:  "USD",
+						AccountID: pgtype.Int4{Int32: account.ID, Valid: true},
+						Daily:     pgtype.Int8{Int64: 100000, Valid: true},
+					})).
+					Times(1).
+					Return(db.TransferLimit{ID: 1}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "currency",
+			role: utils.RoleAdmin,
+			body: map[string]any{
+				"currency":        "EUR",
+				"per_transaction": 500000,
+				"monthly":         5000000,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertTransferLimit(gomock.Any(), gomock.Eq(db.UpsertTransferLimitParams{
+						Currency:       "EUR",
+						PerTransaction: pgtype.Int8{Int64: 500000, Valid: true},
+						Monthly:        pgtype.Int8{Int64: 5000000, Valid: true},
+					})).
+					Times(1).
+					Return(db.TransferLimit{ID: 1}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "unknown owner",
+			role: utils.RoleAdmin,
+			body: map[string]any{
+				"currency": "USD",
+				"owner":    "nobody",
+				"daily":    100,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq("nobody")).Times(1).Return(db.User{}, pgx.ErrNoRows)
+				store.EXPECT().UpsertTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "account in another currency",
+			role: utils.RoleAdmin,
+			body: map[string]any{
+				"currency":   "EUR",
+				"account_id": account.ID,
+				"daily":      100,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().UpsertTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "owner and account",
+			role: utils.RoleAdmin,
+			body: map[string]any{
+				"currency":   "USD",
+				"owner":      account.Owner,
+				"account_id": account.ID,
+				"daily":      100,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "support",
+			role: utils.RoleSupport,
+			body: map[string]any{
+				"currency": "USD",
+				"daily":    100,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := serveHoldRequest(t, utils.RandomOwner(), tc.role, http.MethodPut, "/admin/transfer-limits", tc.body, tc.buildStubs)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestTransferLimitExceeded(t *testing.T) {
+	fromAccount := createRandomAccount("USD")
+	toAccount := createRandomAccount("USD")
+
+	buildStubs := func(store *mockdb.MockStore) {
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+		store.EXPECT().
+			TransferTx(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(db.TransferTxResult{}, fmt.Errorf("%w: 50 left of the daily limit", db.ErrTransferLimitExceeded))
+	}
+
+	body := map[string]any{
+		"from_account_id": fromAccount.ID,
+		"to_account_id":   toAccount.ID,
+		"amount":          100,
+	}
+	recorder := serveHoldRequest(t, fromAccount.Owner, utils.RoleCustomer, http.MethodPost, "/transfer", body, buildStubs)
+	require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+}
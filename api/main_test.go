@@ -7,8 +7,11 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mohammad19khodaei/simple_bank/currency"
+	mockdb "github.com/mohammad19khodaei/simple_bank/db/mock"
 	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
 	"github.com/mohammad19khodaei/simple_bank/utils"
+	"go.uber.org/mock/gomock"
 )
 
 var (
@@ -26,12 +29,52 @@ func TestMain(m *testing.M) {
 	os.Exit(m.Run())
 }
 
+// newMockStore returns a store on which no user ever changed their password,
+// so the tokens of the tests pass AuthMiddleware, and whose currencies table
+// agrees with the default currencies.
+func newMockStore(ctrl *gomock.Controller) *mockdb.MockStore {
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetUserPasswordChangedAt(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		Return(pgtype.Timestamptz{}, nil)
+	store.EXPECT().
+		ListCurrencies(gomock.Any()).
+		AnyTimes().
+		Return(currencyRows(currency.Defaults()), nil)
+	return store
+}
+
+func currencyRows(currencies []currency.Currency) []db.Currency {
+	rows := make([]db.Currency, 0, len(currencies))
+	for _, c := range currencies {
+		rows = append(rows, db.Currency{
+			Code:        c.Code,
+			NumericCode: int32(c.NumericCode),
+			MinorUnits:  int32(c.MinorUnits),
+			Symbol:      c.Symbol,
+			Name:        c.Name,
+			Enabled:     c.Enabled,
+		})
+	}
+	return rows
+}
+
 func createRandomAccount(currency ...string) db.Account {
 	acc := db.Account{
 		ID:      int32(utils.RandomInt(1, 1000)),
 		Owner:   utils.RandomOwner(),
 		Balance: utils.RandomMoney(),
+		Status:  utils.AccountStatusActive,
+		Kind:    utils.AccountKindCustomer,
+	}
+	acc.AvailableBalance = acc.Balance
+
+	number, err := utils.NewAccountNumber()
+	if err != nil {
+		log.Fatal("could not generate account number", err)
 	}
+	acc.Number = number
 
 	// Use provided currency if specified, otherwise random
 	if len(currency) > 0 {
@@ -50,6 +93,7 @@ func createRandomUser(password string) db.User {
 		HashedPassword:    hashedPassword,
 		FullName:          utils.RandomOwner(),
 		Email:             utils.RandomEmail(),
+		Role:              utils.RoleCustomer,
 		PasswordChangedAt: pgtype.Timestamptz{},
 		CreatedAt:         pgtype.Timestamptz{},
 	}
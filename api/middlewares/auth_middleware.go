@@ -1,19 +1,35 @@
 package middlewares
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mohammad19khodaei/simple_bank/revocation"
 	"github.com/mohammad19khodaei/simple_bank/token"
 )
 
 const (
 	AuthUsernameKey         = "auth_username"
+	AuthPayloadKey          = "auth_payload"
 	AuthorizationTypeBearer = "Bearer"
 )
 
-func AuthMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
+// UserStore is the part of the store AuthMiddleware reads.
+type UserStore interface {
+	GetUserPasswordChangedAt(ctx context.Context, username string) (pgtype.Timestamptz, error)
+}
+
+// AuthMiddleware lets through requests bearing a valid access token that was
+// neither revoked nor issued before the last password change of its user.
+// The password change is checked against the users table, so it holds
+// whichever revocation store is configured.
+func AuthMiddleware(tokenMaker token.Maker, revocationStore revocation.Store, userStore UserStore) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authHeader := ctx.GetHeader("Authorization")
 
@@ -34,13 +50,56 @@ func AuthMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 
 		accessToken := parts[1]
 		payload, err := tokenMaker.VerifyToken(accessToken)
+		// a refresh token outlives the access tokens and must only reach
+		// the renewal endpoint
+		if err == nil && payload.Type != token.TokenTypeAccess {
+			err = token.ErrInvalidToken
+		}
 		if err != nil {
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 				"error": "Invalid or expired token",
 			})
 			return
 		}
+
+		revoked, err := revocationStore.IsRevoked(ctx, payload)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		if revoked {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Token has been revoked",
+			})
+			return
+		}
+
+		passwordChangedAt, err := userStore.GetUserPasswordChangedAt(ctx, payload.Username)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+					"error": "User not found",
+				})
+				return
+			}
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		// IssuedAt only carries second precision, so tokens minted within the
+		// second of the change stay valid
+		if payload.IssuedAt.Time.Before(passwordChangedAt.Time.Truncate(time.Second)) {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Token was issued before the last password change",
+			})
+			return
+		}
+
 		ctx.Set(AuthUsernameKey, payload.Username)
+		ctx.Set(AuthPayloadKey, payload)
 		ctx.Next()
 	}
 }
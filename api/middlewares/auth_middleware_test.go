@@ -1,18 +1,25 @@
 package middlewares_test
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mohammad19khodaei/simple_bank/api/middlewares"
+	"github.com/mohammad19khodaei/simple_bank/revocation"
 	"github.com/mohammad19khodaei/simple_bank/token"
+	"github.com/mohammad19khodaei/simple_bank/utils"
 	"github.com/stretchr/testify/require"
 )
 
 func TestAuthMiddleware(t *testing.T) {
+	revocationStore := revocation.NewMemoryStore()
+	users := userStore{}
+
 	testCases := []struct {
 		name          string
 		setAuthHeader func(t *testing.T, maker token.Maker, req *http.Request)
@@ -37,7 +44,7 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name: "with invalid header",
 			setAuthHeader: func(t *testing.T, tokenMaker token.Maker, req *http.Request) {
-				token, err := tokenMaker.GenerateToken("username", config.TokenDuration)
+				token, _, err := tokenMaker.GenerateToken("username", utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
 				require.NoError(t, err)
 				req.Header.Set("Authorization", fmt.Sprintf("%s %s", "unsupported", token))
 			},
@@ -48,7 +55,78 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name: "with expired token",
 			setAuthHeader: func(t *testing.T, tokenMaker token.Maker, req *http.Request) {
-				token, err := tokenMaker.GenerateToken("username", -config.TokenDuration)
+				token, _, err := tokenMaker.GenerateToken("username", utils.RoleCustomer, -config.TokenDuration, token.TokenTypeAccess)
+				require.NoError(t, err)
+				req.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, token))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "with refresh token",
+			setAuthHeader: func(t *testing.T, tokenMaker token.Maker, req *http.Request) {
+				token, _, err := tokenMaker.GenerateToken("username", utils.RoleCustomer, config.RefreshTokenDuration, token.TokenTypeRefresh)
+				require.NoError(t, err)
+				req.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, token))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "with revoked token",
+			setAuthHeader: func(t *testing.T, tokenMaker token.Maker, req *http.Request) {
+				token, payload, err := tokenMaker.GenerateToken("username", utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
+				require.NoError(t, err)
+				require.NoError(t, revocationStore.Revoke(context.Background(), payload))
+				req.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, token))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "with token issued before user revocation",
+			setAuthHeader: func(t *testing.T, tokenMaker token.Maker, req *http.Request) {
+				token, _, err := tokenMaker.GenerateToken("revoked", utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
+				require.NoError(t, err)
+				require.NoError(t, revocationStore.RevokeUser(context.Background(), "revoked", time.Now().Add(time.Second)))
+				req.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, token))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "with token issued before password change",
+			setAuthHeader: func(t *testing.T, tokenMaker token.Maker, req *http.Request) {
+				token, _, err := tokenMaker.GenerateToken("changed", utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
+				require.NoError(t, err)
+				users["changed"] = time.Now().Add(time.Second)
+				req.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, token))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "with token issued in the second of password change",
+			setAuthHeader: func(t *testing.T, tokenMaker token.Maker, req *http.Request) {
+				// the token is minted right after the change, within its second
+				users["changed-now"] = time.Now()
+				token, _, err := tokenMaker.GenerateToken("changed-now", utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
+				require.NoError(t, err)
+				req.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, token))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "with token of unknown user",
+			setAuthHeader: func(t *testing.T, tokenMaker token.Maker, req *http.Request) {
+				token, _, err := tokenMaker.GenerateToken(unknownUser, utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
 				require.NoError(t, err)
 				req.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, token))
 			},
@@ -59,7 +137,7 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name: "ok",
 			setAuthHeader: func(t *testing.T, tokenMaker token.Maker, req *http.Request) {
-				token, err := tokenMaker.GenerateToken("username", config.TokenDuration)
+				token, _, err := tokenMaker.GenerateToken("username", utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
 				require.NoError(t, err)
 				req.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, token))
 			},
@@ -73,7 +151,7 @@ func TestAuthMiddleware(t *testing.T) {
 	require.NoError(t, err)
 	r := gin.Default()
 	authUrl := "/auth"
-	r.GET(authUrl, middlewares.AuthMiddleware(tokenMaker), func(ctx *gin.Context) {
+	r.GET(authUrl, middlewares.AuthMiddleware(tokenMaker, revocationStore, users), func(ctx *gin.Context) {
 		ctx.JSON(http.StatusOK, gin.H{})
 	})
 
@@ -1,11 +1,15 @@
 package middlewares_test
 
 import (
+	"context"
 	"log"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/mohammad19khodaei/simple_bank/utils"
 )
 
@@ -23,3 +27,17 @@ func TestMain(m *testing.M) {
 	gin.SetMode(gin.TestMode)
 	os.Exit(m.Run())
 }
+
+// unknownUser has no row in userStore.
+const unknownUser = "unknown"
+
+// userStore fakes the password changes of the users table, users missing
+// from it never changed their password.
+type userStore map[string]time.Time
+
+func (s userStore) GetUserPasswordChangedAt(_ context.Context, username string) (pgtype.Timestamptz, error) {
+	if username == unknownUser {
+		return pgtype.Timestamptz{}, pgx.ErrNoRows
+	}
+	return pgtype.Timestamptz{Time: s[username], Valid: true}, nil
+}
@@ -0,0 +1,394 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mohammad19khodaei/simple_bank/api/middlewares"
+	"github.com/mohammad19khodaei/simple_bank/api/policies"
+	"github.com/mohammad19khodaei/simple_bank/currency"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/token"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+)
+
+// The responses below keep the raw integer amounts of the db models, which
+// are in the minor unit of their currency, and add them rendered as decimal
+// strings in the major unit, e.g. balance 1250 and formatted_balance "12.50".
+
+type AccountResponse struct {
+	db.Account
+	FormattedBalance          string `json:"formatted_balance"`
+	FormattedAvailableBalance string `json:"formatted_available_balance"`
+}
+
+type TransferResponse struct {
+	db.Transfer
+	FormattedAmount string `json:"formatted_amount"`
+	FormattedFee    string `json:"formatted_fee"`
+}
+
+type EntryResponse struct {
+	db.Entry
+	FormattedAmount string `json:"formatted_amount"`
+}
+
+type HoldResponse struct {
+	db.Hold
+	FormattedAmount         string `json:"formatted_amount"`
+	FormattedFee            string `json:"formatted_fee"`
+	FormattedCapturedAmount string `json:"formatted_captured_amount"`
+}
+
+type ScheduledTransferResponse struct {
+	db.ScheduledTransfer
+	FormattedAmount string `json:"formatted_amount"`
+}
+
+type TransferBatchResponse struct {
+	db.TransferBatch
+	FormattedTotalAmount string                      `json:"formatted_total_amount"`
+	FormattedTotalFee    string                      `json:"formatted_total_fee"`
+	Items                []TransferBatchItemResponse `json:"items"`
+}
+
+type TransferBatchItemResponse struct {
+	db.TransferBatchItem
+	FormattedAmount string `json:"formatted_amount"`
+}
+
+// AccountLimitsResponse shows the limits of an account, a null limit is no
+// limit.
+type AccountLimitsResponse struct {
+	AccountID      int32                `json:"account_id"`
+	Currency       string               `json:"currency"`
+	PerTransaction *LimitResponse       `json:"per_transaction"`
+	Daily          *PeriodLimitResponse `json:"daily"`
+	Monthly        *PeriodLimitResponse `json:"monthly"`
+}
+
+type LimitResponse struct {
+	Limit          int64  `json:"limit"`
+	FormattedLimit string `json:"formatted_limit"`
+}
+
+type PeriodLimitResponse struct {
+	LimitResponse
+	Remaining          int64  `json:"remaining"`
+	FormattedRemaining string `json:"formatted_remaining"`
+}
+
+type FeeScheduleResponse struct {
+	db.FeeSchedule
+	FormattedFlat string `json:"formatted_flat"`
+	// empty when the schedule has no minimum or maximum
+	FormattedMinFee string `json:"formatted_min_fee,omitempty"`
+	FormattedMaxFee string `json:"formatted_max_fee,omitempty"`
+}
+
+// FeeQuoteResponse previews what a transfer of Amount costs the sender.
+type FeeQuoteResponse struct {
+	Currency        string `json:"currency"`
+	Amount          int64  `json:"amount"`
+	Fee             int64  `json:"fee"`
+	Total           int64  `json:"total"`
+	FormattedAmount string `json:"formatted_amount"`
+	FormattedFee    string `json:"formatted_fee"`
+	FormattedTotal  string `json:"formatted_total"`
+}
+
+type StatementLineResponse struct {
+	db.ListAccountStatementRow
+	FormattedAmount         string `json:"formatted_amount"`
+	FormattedRunningBalance string `json:"formatted_running_balance"`
+}
+
+// TransferTxResponse mirrors db.TransferTxResult, field names included. A
+// party of the transfer only sees its own account, the one of the other party
+// is shown as Counterparty.
+type TransferTxResponse struct {
+	Transfer     TransferResponse
+	FromAccount  *AccountResponse   `json:"FromAccount,omitempty"`
+	ToAccount    *AccountResponse   `json:"ToAccount,omitempty"`
+	Counterparty *RecipientResponse `json:"Counterparty,omitempty"`
+	FromEntry    EntryResponse
+	ToEntry      EntryResponse
+	FeeEntry     *EntryResponse
+}
+
+// ReverseTxResponse mirrors db.ReverseTxResult.
+type ReverseTxResponse struct {
+	TransferTxResponse
+	OriginalTransfer TransferResponse
+}
+
+// HoldTxResponse mirrors db.HoldTxResult.
+type HoldTxResponse struct {
+	Hold    HoldResponse
+	Account AccountResponse
+}
+
+// CaptureTxResponse mirrors db.CaptureTxResult.
+type CaptureTxResponse struct {
+	TransferTxResponse
+	Hold HoldResponse
+}
+
+// formatAmount renders an amount of the given currency, an unknown currency
+// renders as a plain integer.
+func (s *server) formatAmount(amount int64, code string) string {
+	c, _ := s.currencies.Get(code)
+	return currency.Money{Amount: amount, Currency: c}.String()
+}
+
+// parseAmount reads a decimal amount in the major unit of the currency and
+// returns it in the minor unit. Only positive amounts are accepted.
+func (s *server) parseAmount(value string, code string) (int64, error) {
+	c, ok := s.currencies.Get(code)
+	if !ok {
+		return 0, fmt.Errorf("unknown currency %s", code)
+	}
+
+	money, err := currency.ParseMoney(value, c)
+	if err != nil {
+		return 0, err
+	}
+	if money.Amount <= 0 {
+		return 0, fmt.Errorf("%w: amount must be positive", currency.ErrInvalidAmount)
+	}
+
+	return money.Amount, nil
+}
+
+func (s *server) newAccountResponse(account db.Account) AccountResponse {
+	return AccountResponse{
+		Account:                   account,
+		FormattedBalance:          s.formatAmount(account.Balance, account.Currency),
+		FormattedAvailableBalance: s.formatAmount(account.AvailableBalance, account.Currency),
+	}
+}
+
+func (s *server) newAccountsResponse(accounts []db.Account) []AccountResponse {
+	resp := make([]AccountResponse, 0, len(accounts))
+	for _, account := range accounts {
+		resp = append(resp, s.newAccountResponse(account))
+	}
+	return resp
+}
+
+func (s *server) newTransferResponse(transfer db.Transfer) TransferResponse {
+	return TransferResponse{
+		Transfer:        transfer,
+		FormattedAmount: s.formatAmount(transfer.Amount, transfer.Currency),
+		FormattedFee:    s.formatAmount(transfer.Fee, transfer.Currency),
+	}
+}
+
+func (s *server) newTransfersResponse(transfers []db.Transfer) []TransferResponse {
+	resp := make([]TransferResponse, 0, len(transfers))
+	for _, transfer := range transfers {
+		resp = append(resp, s.newTransferResponse(transfer))
+	}
+	return resp
+}
+
+func (s *server) newScheduledTransferResponse(scheduled db.ScheduledTransfer) ScheduledTransferResponse {
+	return ScheduledTransferResponse{
+		ScheduledTransfer: scheduled,
+		FormattedAmount:   s.formatAmount(scheduled.Amount, scheduled.Currency),
+	}
+}
+
+func (s *server) newScheduledTransfersResponse(scheduled []db.ScheduledTransfer) []ScheduledTransferResponse {
+	resp := make([]ScheduledTransferResponse, 0, len(scheduled))
+	for _, item := range scheduled {
+		resp = append(resp, s.newScheduledTransferResponse(item))
+	}
+	return resp
+}
+
+// newTransferBatchResponse formats a batch with its items, which are in the
+// currency of the batch.
+func (s *server) newTransferBatchResponse(batch db.TransferBatch, items []db.TransferBatchItem) TransferBatchResponse {
+	resp := TransferBatchResponse{
+		TransferBatch:        batch,
+		FormattedTotalAmount: s.formatAmount(batch.TotalAmount, batch.Currency),
+		FormattedTotalFee:    s.formatAmount(batch.TotalFee, batch.Currency),
+		Items:                make([]TransferBatchItemResponse, 0, len(items)),
+	}
+	for _, item := range items {
+		resp.Items = append(resp.Items, TransferBatchItemResponse{
+			TransferBatchItem: item,
+			FormattedAmount:   s.formatAmount(item.Amount, batch.Currency),
+		})
+	}
+	return resp
+}
+
+func (s *server) newAccountLimitsResponse(account db.Account, limits db.AccountLimits) AccountLimitsResponse {
+	resp := AccountLimitsResponse{
+		AccountID: account.ID,
+		Currency:  account.Currency,
+	}
+
+	if limits.PerTransaction.Valid {
+		resp.PerTransaction = &LimitResponse{
+			Limit:          limits.PerTransaction.Int64,
+			FormattedLimit: s.formatAmount(limits.PerTransaction.Int64, account.Currency),
+		}
+	}
+
+	periodLimit := func(limit int64, remaining int64) *PeriodLimitResponse {
+		return &PeriodLimitResponse{
+			LimitResponse: LimitResponse{
+				Limit:          limit,
+				FormattedLimit: s.formatAmount(limit, account.Currency),
+			},
+			Remaining:          remaining,
+			FormattedRemaining: s.formatAmount(remaining, account.Currency),
+		}
+	}
+	if remaining, ok := limits.DailyRemaining(); ok {
+		resp.Daily = periodLimit(limits.Daily.Int64, remaining)
+	}
+	if remaining, ok := limits.MonthlyRemaining(); ok {
+		resp.Monthly = periodLimit(limits.Monthly.Int64, remaining)
+	}
+
+	return resp
+}
+
+// newEntriesResponse formats entries of a single account, the one whose
+// currency is given.
+func (s *server) newEntriesResponse(entries []db.Entry, currency string) []EntryResponse {
+	resp := make([]EntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		resp = append(resp, EntryResponse{
+			Entry:           entry,
+			FormattedAmount: s.formatAmount(entry.Amount, currency),
+		})
+	}
+	return resp
+}
+
+func (s *server) newTransferTxResponse(result db.TransferTxResult) TransferTxResponse {
+	fromAccount := s.newAccountResponse(result.FromAccount)
+	toAccount := s.newAccountResponse(result.ToAccount)
+
+	resp := TransferTxResponse{
+		Transfer:    s.newTransferResponse(result.Transfer),
+		FromAccount: &fromAccount,
+		ToAccount:   &toAccount,
+		FromEntry: EntryResponse{
+			Entry:           result.FromEntry,
+			FormattedAmount: s.formatAmount(result.FromEntry.Amount, result.FromAccount.Currency),
+		},
+		ToEntry: EntryResponse{
+			Entry:           result.ToEntry,
+			FormattedAmount: s.formatAmount(result.ToEntry.Amount, result.ToAccount.Currency),
+		},
+	}
+
+	// the fee is in the currency of the transfer
+	if result.FeeEntry != nil {
+		resp.FeeEntry = &EntryResponse{
+			Entry:           *result.FeeEntry,
+			FormattedAmount: s.formatAmount(result.FeeEntry.Amount, result.Transfer.Currency),
+		}
+	}
+
+	return resp
+}
+
+// newPartyTransferTxResponse is the response of a transfer for the party that
+// made the call, the sender or else the recipient. The account of the other
+// party is left out for its number and the masked name of its owner, so
+// neither learns the balance of the other.
+func (s *server) newPartyTransferTxResponse(ctx *gin.Context, result db.TransferTxResult, sender bool) (TransferTxResponse, error) {
+	resp := s.newTransferTxResponse(result)
+
+	counterparty := result.FromAccount
+	if sender {
+		counterparty = result.ToAccount
+	}
+	// nothing is hidden from a caller who can read both accounts anyway
+	payload := ctx.MustGet(middlewares.AuthPayloadKey).(*token.Payload)
+	if policies.CanReadAccount(payload, counterparty) {
+		return resp, nil
+	}
+
+	owner, err := s.store.GetUser(ctx, counterparty.Owner)
+	if err != nil {
+		return resp, err
+	}
+
+	if sender {
+		resp.ToAccount = nil
+	} else {
+		resp.FromAccount = nil
+	}
+	resp.Counterparty = &RecipientResponse{
+		AccountNumber: counterparty.Number,
+		Currency:      counterparty.Currency,
+		Name:          utils.MaskName(owner.FullName),
+	}
+	return resp, nil
+}
+
+func (s *server) newFeeScheduleResponse(schedule db.FeeSchedule) FeeScheduleResponse {
+	resp := FeeScheduleResponse{
+		FeeSchedule:   schedule,
+		FormattedFlat: s.formatAmount(schedule.Flat, schedule.Currency),
+	}
+	if schedule.MinFee.Valid {
+		resp.FormattedMinFee = s.formatAmount(schedule.MinFee.Int64, schedule.Currency)
+	}
+	if schedule.MaxFee.Valid {
+		resp.FormattedMaxFee = s.formatAmount(schedule.MaxFee.Int64, schedule.Currency)
+	}
+	return resp
+}
+
+func (s *server) newFeeSchedulesResponse(schedules []db.FeeSchedule) []FeeScheduleResponse {
+	resp := make([]FeeScheduleResponse, 0, len(schedules))
+	for _, schedule := range schedules {
+		resp = append(resp, s.newFeeScheduleResponse(schedule))
+	}
+	return resp
+}
+
+// newReverseTxResponse is seen by the recipient of the original transfer, the
+// sender of the reversal.
+func (s *server) newReverseTxResponse(ctx *gin.Context, result db.ReverseTxResult) (ReverseTxResponse, error) {
+	resp, err := s.newPartyTransferTxResponse(ctx, result.TransferTxResult, true)
+	return ReverseTxResponse{
+		TransferTxResponse: resp,
+		OriginalTransfer:   s.newTransferResponse(result.OriginalTransfer),
+	}, err
+}
+
+func (s *server) newHoldResponse(hold db.Hold) HoldResponse {
+	return HoldResponse{
+		Hold:                    hold,
+		FormattedAmount:         s.formatAmount(hold.Amount, hold.Currency),
+		FormattedFee:            s.formatAmount(hold.Fee, hold.Currency),
+		FormattedCapturedAmount: s.formatAmount(hold.CapturedAmount, hold.Currency),
+	}
+}
+
+func (s *server) newHoldTxResponse(result db.HoldTxResult) HoldTxResponse {
+	return HoldTxResponse{
+		Hold:    s.newHoldResponse(result.Hold),
+		Account: s.newAccountResponse(result.Account),
+	}
+}
+
+// newCaptureTxResponse is seen by the to account of the hold, the recipient of
+// the capture.
+func (s *server) newCaptureTxResponse(ctx *gin.Context, result db.CaptureTxResult) (CaptureTxResponse, error) {
+	resp, err := s.newPartyTransferTxResponse(ctx, result.TransferTxResult, false)
+	return CaptureTxResponse{
+		TransferTxResponse: resp,
+		Hold:               s.newHoldResponse(result.Hold),
+	}, err
+}
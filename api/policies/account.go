@@ -0,0 +1,60 @@
+package policies
+
+import (
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/token"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+)
+
+// IsStaff reports whether the user works for the bank rather than being one
+// of its customers.
+func IsStaff(payload *token.Payload) bool {
+	return payload.Role == utils.RoleAdmin || payload.Role == utils.RoleSupport
+}
+
+func IsOwner(payload *token.Payload, account db.Account) bool {
+	return account.Owner == payload.Username
+}
+
+// CanReadAccount lets customers read their own accounts and staff read any account.
+func CanReadAccount(payload *token.Payload, account db.Account) bool {
+	return IsOwner(payload, account) || IsStaff(payload)
+}
+
+// CanDebitAccount only lets the owner move money out of an account.
+func CanDebitAccount(payload *token.Payload, account db.Account) bool {
+	return IsOwner(payload, account)
+}
+
+// CanCloseAccount only lets the owner close an account.
+func CanCloseAccount(payload *token.Payload, account db.Account) bool {
+	return IsOwner(payload, account)
+}
+
+// CanReadStatement only lets the owner read the entries of an account. Staff
+// go through the admin API instead.
+func CanReadStatement(payload *token.Payload, account db.Account) bool {
+	return IsOwner(payload, account)
+}
+
+// CanReadTransfer lets the owner of either side of a transfer read it.
+func CanReadTransfer(payload *token.Payload, fromAccount db.Account, toAccount db.Account) bool {
+	return IsOwner(payload, fromAccount) || IsOwner(payload, toAccount)
+}
+
+// CanReverseTransfer lets the owner of the credited account refund a transfer
+// and admins reverse any transfer. The sender cannot take the money back.
+func CanReverseTransfer(payload *token.Payload, toAccount db.Account) bool {
+	return IsOwner(payload, toAccount) || payload.Role == utils.RoleAdmin
+}
+
+// CanReadHold lets the owner of either side of a hold read it.
+func CanReadHold(payload *token.Payload, account db.Account, toAccount db.Account) bool {
+	return IsOwner(payload, account) || IsOwner(payload, toAccount)
+}
+
+// CanSettleHold lets the owner of the account a hold pays into capture or
+// void it, and admins settle any hold. The payer cannot cancel a hold.
+func CanSettleHold(payload *token.Payload, toAccount db.Account) bool {
+	return IsOwner(payload, toAccount) || payload.Role == utils.RoleAdmin
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+)
+
+var errRecipientRequired = errors.New("exactly one of to_account_id, to_account_number or to_username is required")
+
+type lookupRecipientQuery struct {
+	Number   string `form:"number" binding:"omitempty,account_number"`
+	Username string `form:"username" binding:"omitempty,alphanum"`
+	Currency string `form:"currency" binding:"omitempty,currency"`
+}
+
+type RecipientResponse struct {
+	AccountNumber string `json:"account_number"`
+	Currency      string `json:"currency"`
+	Name          string `json:"name"`
+}
+
+// lookupRecipientHandler lets a sender confirm who they are about to pay. It
+// only reveals the masked name of the owner of an active customer account.
+func (s *server) lookupRecipientHandler(ctx *gin.Context) {
+	var query lookupRecipientQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	if (query.Number == "") == (query.Username == "") {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(errors.New("exactly one of number or username is required")))
+		return
+	}
+	if query.Username != "" && query.Currency == "" {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(errors.New("currency is required with username")))
+		return
+	}
+
+	account, err := s.findRecipient(ctx, query.Number, query.Username, query.Currency)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, s.errorResponse(errors.New("recipient not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	owner, err := s.store.GetUser(ctx, account.Owner)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, RecipientResponse{
+		AccountNumber: account.Number,
+		Currency:      account.Currency,
+		Name:          utils.MaskName(owner.FullName),
+	})
+}
+
+// findRecipient resolves an account by its number, or by the username of its
+// owner and its currency. Accounts that cannot receive money are reported as
+// pgx.ErrNoRows so their existence is not disclosed.
+func (s *server) findRecipient(ctx *gin.Context, number string, username string, currency string) (db.Account, error) {
+	var account db.Account
+	var err error
+	if number != "" {
+		account, err = s.store.GetAccountByNumber(ctx, utils.NormalizeAccountNumber(number))
+	} else {
+		account, err = s.store.GetAccountByOwnerAndCurrency(ctx, db.GetAccountByOwnerAndCurrencyParams{
+			Owner:    username,
+			Currency: currency,
+		})
+	}
+	if err != nil {
+		return db.Account{}, err
+	}
+
+	if account.Kind != utils.AccountKindCustomer || account.Status != utils.AccountStatusActive {
+		return db.Account{}, pgx.ErrNoRows
+	}
+
+	return account, nil
+}
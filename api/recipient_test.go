@@ -0,0 +1,355 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/mohammad19khodaei/simple_bank/api"
+	"github.com/mohammad19khodaei/simple_bank/api/middlewares"
+	mockdb "github.com/mohammad19khodaei/simple_bank/db/mock"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/token"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestLookupRecipient(t *testing.T) {
+	account := createRandomAccount("USD")
+	owner := db.User{Username: account.Owner, FullName: "John Smith"}
+	frozenAccount := createRandomAccount("USD")
+	frozenAccount.Status = utils.AccountStatusFrozen
+
+	printed := account.Number[:4] + " " + account.Number[4:8] + " " + account.Number[8:12] + " " + account.Number[12:]
+
+	testCases := []struct {
+		name          string
+		query         url.Values
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "by number",
+			query: url.Values{"number": {printed}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(account.Owner)).
+					Times(1).
+					Return(owner, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var recipient api.RecipientResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &recipient)
+				require.NoError(t, err)
+				require.Equal(t, account.Number, recipient.AccountNumber)
+				require.Equal(t, "USD", recipient.Currency)
+				require.Equal(t, "J*** S****", recipient.Name)
+			},
+		},
+		{
+			name:  "by username",
+			query: url.Values{"username": {account.Owner}, "currency": {"USD"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountByOwnerAndCurrency(gomock.Any(), gomock.Eq(db.GetAccountByOwnerAndCurrencyParams{
+						Owner:    account.Owner,
+						Currency: "USD",
+					})).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(account.Owner)).
+					Times(1).
+					Return(owner, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "invalid check digits",
+			query: url.Values{"number": {"SB00" + account.Number[4:]}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountByNumber(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "username without currency",
+			query: url.Values{"username": {account.Owner}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountByOwnerAndCurrency(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "not found",
+			query: url.Values{"number": {account.Number}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountByNumber(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, pgx.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:  "frozen account is hidden",
+			query: url.Values{"number": {frozenAccount.Number}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountByNumber(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(frozenAccount, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	tokenMaker, err := token.NewPasetoMaker(config.SecretKey)
+	require.NoError(t, err)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := newMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := api.NewServer(config, store)
+			require.NoError(t, err)
+
+			accessToken, _, err := tokenMaker.GenerateToken(utils.RandomOwner(), utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/accounts/lookup?"+tc.query.Encode(), nil)
+			request.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, accessToken))
+
+			server.Router().ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestTransferToRecipient(t *testing.T) {
+	fromAccount := createRandomAccount("USD")
+	toAccount := createRandomAccount("USD")
+	// a balance no other field of the response can have by chance
+	toAccount.Balance = 987_654_321
+	toAccount.AvailableBalance = 987_654_000
+	toOwner := db.User{Username: toAccount.Owner, FullName: "John Smith"}
+	result := db.TransferTxResult{
+		Transfer:    db.Transfer{ID: 1, FromAccountID: fromAccount.ID, ToAccountID: toAccount.ID, Amount: 10, Currency: "USD"},
+		FromAccount: fromAccount,
+		ToAccount:   toAccount,
+	}
+
+	testCases := []struct {
+		name          string
+		body          map[string]any
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "by account number",
+			body: map[string]any{
+				"from_account_id":   fromAccount.ID,
+				"to_account_number": toAccount.Number,
+				"amount":            10,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().
+					GetAccountByNumber(gomock.Any(), gomock.Eq(toAccount.Number)).
+					Times(1).
+					Return(toAccount, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(db.TransferTxParams{
+						FromAccountID: fromAccount.ID,
+						ToAccountID:   toAccount.ID,
+						Amount:        10,
+					})).
+					Times(1).
+					Return(result, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(toAccount.Owner)).Times(1).Return(toOwner, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				// the recipient is shown by number and masked name only
+				var resp api.TransferTxResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				require.NoError(t, err)
+				require.Nil(t, resp.ToAccount)
+				require.Equal(t, &api.RecipientResponse{
+					AccountNumber: toAccount.Number,
+					Currency:      "USD",
+					Name:          "J*** S****",
+				}, resp.Counterparty)
+				require.Equal(t, fromAccount.ID, resp.FromAccount.ID)
+
+				require.NotContains(t, recorder.Body.String(), "987654321")
+				require.NotContains(t, recorder.Body.String(), "987654000")
+			},
+		},
+		{
+			name: "by username in the currency of the from account",
+			body: map[string]any{
+				"from_account_id": fromAccount.ID,
+				"to_username":     toAccount.Owner,
+				"amount":          10,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().
+					GetAccountByOwnerAndCurrency(gomock.Any(), gomock.Eq(db.GetAccountByOwnerAndCurrencyParams{
+						Owner:    toAccount.Owner,
+						Currency: "USD",
+					})).
+					Times(1).
+					Return(toAccount, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(db.TransferTxParams{
+						FromAccountID: fromAccount.ID,
+						ToAccountID:   toAccount.ID,
+						Amount:        10,
+					})).
+					Times(1).
+					Return(result, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(toAccount.Owner)).Times(1).Return(toOwner, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				require.NotContains(t, recorder.Body.String(), "987654321")
+			},
+		},
+		{
+			name: "recipient not found",
+			body: map[string]any{
+				"from_account_id": fromAccount.ID,
+				"to_username":     toAccount.Owner,
+				"to_currency":     "EUR",
+				"amount":          10,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().
+					GetAccountByOwnerAndCurrency(gomock.Any(), gomock.Eq(db.GetAccountByOwnerAndCurrencyParams{
+						Owner:    toAccount.Owner,
+						Currency: "EUR",
+					})).
+					Times(1).
+					Return(db.Account{}, pgx.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "recipient is the from account",
+			body: map[string]any{
+				"from_account_id":   fromAccount.ID,
+				"to_account_number": fromAccount.Number,
+				"amount":            10,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().
+					GetAccountByNumber(gomock.Any(), gomock.Eq(fromAccount.Number)).
+					Times(1).
+					Return(fromAccount, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "several recipients",
+			body: map[string]any{
+				"from_account_id":   fromAccount.ID,
+				"to_account_id":     toAccount.ID,
+				"to_account_number": toAccount.Number,
+				"amount":            10,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "no recipient",
+			body: map[string]any{
+				"from_account_id": fromAccount.ID,
+				"amount":          10,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	tokenMaker, err := token.NewPasetoMaker(config.SecretKey)
+	require.NoError(t, err)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := newMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := api.NewServer(config, store)
+			require.NoError(t, err)
+
+			accessToken, _, err := tokenMaker.GenerateToken(fromAccount.Owner, utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
+			require.NoError(t, err)
+
+			jsonData, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/transfer", bytes.NewReader(jsonData))
+			request.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, accessToken))
+
+			server.Router().ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/mohammad19khodaei/simple_bank/api/middlewares"
+	"github.com/mohammad19khodaei/simple_bank/api/policies"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/token"
+)
+
+type reverseTransferRequest struct {
+	// Amount and AmountDecimal are in the currency of the transfer, at most
+	// one of them can be sent. Without either the whole amount left to
+	// reverse is given back.
+	Amount        int64  `json:"amount,omitempty" binding:"omitempty,gt=0"`
+	AmountDecimal string `json:"amount_decimal,omitempty" binding:"excluded_with=Amount"`
+	Description   string `json:"description,omitempty" binding:"omitempty,max=255"`
+}
+
+// reverseTransferHandler gives back all or part of a transfer to its sender.
+func (s *server) reverseTransferHandler(ctx *gin.Context) {
+	var params getTransferParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	// the body is optional, without one the whole transfer is reversed
+	var request reverseTransferRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	transfer, err := s.store.GetTransfer(ctx, params.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, s.errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	fromAccount, err := s.store.GetAccount(ctx, transfer.FromAccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	toAccount, err := s.store.GetAccount(ctx, transfer.ToAccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	payload := ctx.MustGet(middlewares.AuthPayloadKey).(*token.Payload)
+	if !policies.CanReverseTransfer(payload, toAccount) {
+		if !policies.CanReadTransfer(payload, fromAccount, toAccount) {
+			// do not reveal that a transfer with this id exists
+			ctx.JSON(http.StatusNotFound, s.errorResponse(pgx.ErrNoRows))
+			return
+		}
+		ctx.JSON(http.StatusForbidden, s.errorResponse(errors.New("forbidden: only the recipient can reverse a transfer")))
+		return
+	}
+
+	amount := request.Amount
+	if request.AmountDecimal != "" {
+		amount, err = s.parseAmount(request.AmountDecimal, transfer.Currency)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+			return
+		}
+	}
+
+	// the amount left to reverse and the balance are checked by ReverseTx
+	// with the transfer and both accounts locked
+	result, err := s.store.ReverseTx(ctx, db.ReverseTxParams{
+		TransferID:  transfer.ID,
+		Amount:      amount,
+		Description: request.Description,
+	})
+	if err != nil {
+		ctx.JSON(transferErrorStatus(err), s.errorResponse(err))
+		return
+	}
+
+	resp, err := s.newReverseTxResponse(ctx, result)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, resp)
+}
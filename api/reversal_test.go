@@ -0,0 +1,250 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/mohammad19khodaei/simple_bank/api"
+	"github.com/mohammad19khodaei/simple_bank/api/middlewares"
+	mockdb "github.com/mohammad19khodaei/simple_bank/db/mock"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/token"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestReverseTransfer(t *testing.T) {
+	fromAccount := createRandomAccount("USD")
+	toAccount := createRandomAccount("USD")
+	transfer := db.Transfer{
+		ID:            int32(utils.RandomInt(1, 1000)),
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        1000,
+		Currency:      "USD",
+	}
+
+	stubTransfer := func(store *mockdb.MockStore) {
+		store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		role          string
+		body          map[string]any
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "full refund by the recipient",
+			username: toAccount.Owner,
+			role:     utils.RoleCustomer,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubTransfer(store)
+				store.EXPECT().
+					ReverseTx(gomock.Any(), gomock.Eq(db.ReverseTxParams{TransferID: transfer.ID})).
+					Times(1).
+					Return(db.ReverseTxResult{
+						TransferTxResult: db.TransferTxResult{
+							Transfer:    db.Transfer{ID: transfer.ID + 1, Amount: 1000, Currency: "USD"},
+							FromAccount: toAccount,
+							ToAccount:   fromAccount,
+						},
+						OriginalTransfer: db.Transfer{ID: transfer.ID, Amount: 1000, ReversedAmount: 1000, Currency: "USD"},
+					}, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(fromAccount.Owner)).
+					Times(1).
+					Return(db.User{Username: fromAccount.Owner, FullName: "Jane Doe"}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var resp api.ReverseTxResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				require.NoError(t, err)
+				require.Equal(t, "10.00", resp.Transfer.FormattedAmount)
+				require.Equal(t, int64(1000), resp.OriginalTransfer.ReversedAmount)
+
+				// the original sender is only shown by number and masked name
+				require.Nil(t, resp.ToAccount)
+				require.Equal(t, fromAccount.Number, resp.Counterparty.AccountNumber)
+				require.Equal(t, "J*** D**", resp.Counterparty.Name)
+				require.Equal(t, toAccount.ID, resp.FromAccount.ID)
+			},
+		},
+		{
+			name:     "partial refund in decimal",
+			username: toAccount.Owner,
+			role:     utils.RoleCustomer,
+			body:     map[string]any{"amount_decimal": "2.50", "description": "damaged item"},
+			buildStubs: func(store *mockdb.MockStore) {
+				stubTransfer(store)
+				store.EXPECT().
+					ReverseTx(gomock.Any(), gomock.Eq(db.ReverseTxParams{
+						TransferID:  transfer.ID,
+						Amount:      250,
+						Description: "damaged item",
+					})).
+					Times(1).
+					Return(db.ReverseTxResult{
+						TransferTxResult: db.TransferTxResult{FromAccount: toAccount, ToAccount: fromAccount},
+					}, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(fromAccount.Owner)).Times(1).Return(db.User{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name:     "admin",
+			username: utils.RandomOwner(),
+			role:     utils.RoleAdmin,
+			body:     map[string]any{"amount": 100},
+			buildStubs: func(store *mockdb.MockStore) {
+				stubTransfer(store)
+				store.EXPECT().
+					ReverseTx(gomock.Any(), gomock.Eq(db.ReverseTxParams{TransferID: transfer.ID, Amount: 100})).
+					Times(1).
+					Return(db.ReverseTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name:     "sender cannot take the money back",
+			username: fromAccount.Owner,
+			role:     utils.RoleCustomer,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubTransfer(store)
+				store.EXPECT().ReverseTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "transfer of other customers",
+			username: utils.RandomOwner(),
+			role:     utils.RoleCustomer,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubTransfer(store)
+				store.EXPECT().ReverseTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "already reversed",
+			username: toAccount.Owner,
+			role:     utils.RoleCustomer,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubTransfer(store)
+				store.EXPECT().
+					ReverseTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReverseTxResult{}, db.ErrReversalExceedsTransfer)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "reversal of a reversal",
+			username: toAccount.Owner,
+			role:     utils.RoleCustomer,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubTransfer(store)
+				store.EXPECT().
+					ReverseTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReverseTxResult{}, db.ErrTransferNotReversible)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:     "insufficient balance",
+			username: toAccount.Owner,
+			role:     utils.RoleCustomer,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubTransfer(store)
+				store.EXPECT().
+					ReverseTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReverseTxResult{}, db.ErrInsufficientBalance)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPaymentRequired, recorder.Code)
+			},
+		},
+		{
+			name:     "both amounts",
+			username: toAccount.Owner,
+			role:     utils.RoleCustomer,
+			body:     map[string]any{"amount": 100, "amount_decimal": "1.00"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "not found",
+			username: toAccount.Owner,
+			role:     utils.RoleCustomer,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(db.Transfer{}, pgx.ErrNoRows)
+				store.EXPECT().ReverseTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	tokenMaker, err := token.NewPasetoMaker(config.SecretKey)
+	require.NoError(t, err)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := newMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := api.NewServer(config, store)
+			require.NoError(t, err)
+
+			accessToken, _, err := tokenMaker.GenerateToken(tc.username, tc.role, config.TokenDuration, token.TokenTypeAccess)
+			require.NoError(t, err)
+
+			var body bytes.Buffer
+			if tc.body != nil {
+				err = json.NewEncoder(&body).Encode(tc.body)
+				require.NoError(t, err)
+			}
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/transfers/%d/reversals", transfer.ID), &body)
+			request.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, accessToken))
+
+			server.Router().ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
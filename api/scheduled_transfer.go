@@ -0,0 +1,295 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mohammad19khodaei/simple_bank/api/middlewares"
+	"github.com/mohammad19khodaei/simple_bank/api/policies"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/token"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+)
+
+type createScheduledTransferRequest struct {
+	FromAccountID int32 `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int32 `json:"to_account_id" binding:"required,min=1,nefield=FromAccountID"`
+	// Amount and AmountDecimal work as on POST /transfer, exactly one of
+	// them must be sent.
+	Amount        int64  `json:"amount,omitempty" binding:"omitempty,gt=0"`
+	AmountDecimal string `json:"amount_decimal,omitempty" binding:"required_without=Amount,excluded_with=Amount"`
+	Description   string `json:"description,omitempty" binding:"omitempty,max=255"`
+	Reference     string `json:"reference,omitempty" binding:"omitempty,max=64"`
+	// Schedule is a cron expression in UTC, e.g. "0 9 1 * *" for 09:00 on
+	// the first of every month. Without it the transfer runs once at StartAt.
+	// With both the first run is at StartAt and the next ones follow
+	// Schedule.
+	Schedule string    `json:"schedule,omitempty" binding:"omitempty,cron"`
+	StartAt  time.Time `json:"start_at,omitempty" binding:"required_without=Schedule"`
+}
+
+// createScheduledTransferHandler sets up a transfer made later by the
+// scheduler, once or on a recurring schedule.
+func (s *server) createScheduledTransferHandler(ctx *gin.Context) {
+	var request createScheduledTransferRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	now := time.Now()
+	if !request.StartAt.IsZero() && !request.StartAt.After(now) {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(errors.New("start_at must be in the future")))
+		return
+	}
+
+	fromAccount, err := s.store.GetAccount(ctx, request.FromAccountID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, s.errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	payload := ctx.MustGet(middlewares.AuthPayloadKey).(*token.Payload)
+	if !policies.CanDebitAccount(payload, fromAccount) {
+		ctx.JSON(http.StatusForbidden, s.errorResponse(errors.New("forbidden: account does not belong to you")))
+		return
+	}
+
+	toAccount, err := s.store.GetAccount(ctx, request.ToAccountID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, s.errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	// the scheduler has no quote to convert with
+	if fromAccount.Currency != toAccount.Currency {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(fmt.Errorf("%w: scheduled transfers need both accounts in the same currency", db.ErrCurrencyMismatch)))
+		return
+	}
+
+	amount := request.Amount
+	if request.AmountDecimal != "" {
+		amount, err = s.parseAmount(request.AmountDecimal, fromAccount.Currency)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+			return
+		}
+	}
+
+	nextRunAt := request.StartAt
+	if nextRunAt.IsZero() {
+		// validated by the cron binding
+		schedule, _ := utils.ParseCron(request.Schedule)
+		nextRunAt = schedule.Next(now.UTC())
+		if nextRunAt.IsZero() {
+			ctx.JSON(http.StatusBadRequest, s.errorResponse(errors.New("schedule never runs")))
+			return
+		}
+	}
+
+	scheduled, err := s.store.CreateScheduledTransfer(ctx, db.CreateScheduledTransferParams{
+		Owner:         payload.Username,
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        amount,
+		Currency:      fromAccount.Currency,
+		Description:   request.Description,
+		Reference:     request.Reference,
+		Schedule:      request.Schedule,
+		NextRunAt:     pgtype.Timestamptz{Time: nextRunAt, Valid: true},
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, s.newScheduledTransferResponse(scheduled))
+}
+
+type listScheduledTransfersQuery struct {
+	Page    int32 `form:"page" binding:"omitempty,min=1"`
+	PerPage int32 `form:"per_page" binding:"omitempty,min=5,max=50"`
+}
+
+// listScheduledTransfersHandler lists the scheduled transfers of the caller,
+// newest first.
+func (s *server) listScheduledTransfersHandler(ctx *gin.Context) {
+	var query listScheduledTransfersQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	page := int32(1)
+	if query.Page != 0 {
+		page = query.Page
+	}
+
+	perPage := int32(20)
+	if query.PerPage != 0 {
+		perPage = query.PerPage
+	}
+
+	scheduled, err := s.store.ListScheduledTransfers(ctx, db.ListScheduledTransfersParams{
+		Owner:  ctx.MustGet(middlewares.AuthUsernameKey).(string),
+		Limit:  perPage,
+		Offset: (page - 1) * perPage,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, s.newScheduledTransfersResponse(scheduled))
+}
+
+type getScheduledTransferParams struct {
+	ID int32 `uri:"id" binding:"required,min=1"`
+}
+
+func (s *server) getScheduledTransferHandler(ctx *gin.Context) {
+	scheduled, ok := s.loadScheduledTransfer(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, s.newScheduledTransferResponse(scheduled))
+}
+
+// listScheduledTransferRunsHandler lists the outcome of the runs of a
+// scheduled transfer, newest first.
+func (s *server) listScheduledTransferRunsHandler(ctx *gin.Context) {
+	var query listScheduledTransfersQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	scheduled, ok := s.loadScheduledTransfer(ctx)
+	if !ok {
+		return
+	}
+
+	page := int32(1)
+	if query.Page != 0 {
+		page = query.Page
+	}
+
+	perPage := int32(20)
+	if query.PerPage != 0 {
+		perPage = query.PerPage
+	}
+
+	runs, err := s.store.ListScheduledTransferRuns(ctx, db.ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduled.ID,
+		Limit:               perPage,
+		Offset:              (page - 1) * perPage,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, runs)
+}
+
+func (s *server) pauseScheduledTransferHandler(ctx *gin.Context) {
+	s.setScheduledTransferStatus(ctx, utils.ScheduledTransferStatusPaused)
+}
+
+func (s *server) resumeScheduledTransferHandler(ctx *gin.Context) {
+	s.setScheduledTransferStatus(ctx, utils.ScheduledTransferStatusActive)
+}
+
+func (s *server) cancelScheduledTransferHandler(ctx *gin.Context) {
+	s.setScheduledTransferStatus(ctx, utils.ScheduledTransferStatusCancelled)
+}
+
+func (s *server) setScheduledTransferStatus(ctx *gin.Context, status string) {
+	scheduled, ok := s.loadScheduledTransfer(ctx)
+	if !ok {
+		return
+	}
+
+	if !utils.CanTransitionScheduledTransferStatus(scheduled.Status, status) {
+		ctx.JSON(http.StatusConflict, s.errorResponse(fmt.Errorf("cannot change scheduled transfer status from %s to %s", scheduled.Status, status)))
+		return
+	}
+
+	// a recurring transfer resumed after its next run passed skips the runs
+	// missed while paused, a one-off transfer runs right away
+	var nextRunAt pgtype.Timestamptz
+	if status == utils.ScheduledTransferStatusActive && scheduled.Schedule != "" && scheduled.NextRunAt.Time.Before(time.Now()) {
+		schedule, err := utils.ParseCron(scheduled.Schedule)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+			return
+		}
+		next := schedule.Next(time.Now().UTC())
+		if next.IsZero() {
+			ctx.JSON(http.StatusUnprocessableEntity, s.errorResponse(errors.New("schedule never runs")))
+			return
+		}
+		nextRunAt = pgtype.Timestamptz{Time: next, Valid: true}
+	}
+
+	scheduled, err := s.store.UpdateScheduledTransferStatus(ctx, db.UpdateScheduledTransferStatusParams{
+		Status:        status,
+		NextRunAt:     nextRunAt,
+		ID:            scheduled.ID,
+		CurrentStatus: scheduled.Status,
+	})
+	if err != nil {
+		// the scheduler or another request changed the status after we read it
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusConflict, s.errorResponse(errors.New("scheduled transfer status changed, try again")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, s.newScheduledTransferResponse(scheduled))
+}
+
+// loadScheduledTransfer fetches the scheduled transfer of the id in the uri
+// when it belongs to the caller. It writes the error response and reports
+// false otherwise.
+func (s *server) loadScheduledTransfer(ctx *gin.Context) (db.ScheduledTransfer, bool) {
+	var params getScheduledTransferParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return db.ScheduledTransfer{}, false
+	}
+
+	scheduled, err := s.store.GetScheduledTransfer(ctx, params.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, s.errorResponse(err))
+			return db.ScheduledTransfer{}, false
+		}
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return db.ScheduledTransfer{}, false
+	}
+
+	// do not reveal that a scheduled transfer with this id exists
+	if scheduled.Owner != ctx.MustGet(middlewares.AuthUsernameKey).(string) {
+		ctx.JSON(http.StatusNotFound, s.errorResponse(pgx.ErrNoRows))
+		return db.ScheduledTransfer{}, false
+	}
+
+	return scheduled, true
+}
@@ -0,0 +1,360 @@
+package api_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mohammad19khodaei/simple_bank/api"
+	mockdb "github.com/mohammad19khodaei/simple_bank/db/mock"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateScheduledTransfer(t *testing.T) {
+	fromAccount := createRandomAccount("USD")
+	toAccount := createRandomAccount("USD")
+	eurAccount := createRandomAccount("EUR")
+	startAt := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+
+	stubAccounts := func(store *mockdb.MockStore) {
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		body          map[string]any
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "monthly",
+			username: fromAccount.Owner,
+			body: map[string]any{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount_decimal":  "850.00",
+				"description":     "rent",
+				"schedule":        "0 9 1 * *",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().
+					CreateScheduledTransfer(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, params db.CreateScheduledTransferParams) (db.ScheduledTransfer, error) {
+						require.Equal(t, fromAccount.Owner, params.Owner)
+						require.Equal(t, int64(85000), params.Amount)
+						require.Equal(t, "USD", params.Currency)
+						require.Equal(t, "0 9 1 * *", params.Schedule)
+						require.True(t, params.NextRunAt.Time.After(time.Now()))
+						require.Equal(t, 1, params.NextRunAt.Time.Day())
+						require.Equal(t, 9, params.NextRunAt.Time.Hour())
+
+						return db.ScheduledTransfer{
+							ID:        1,
+							Amount:    params.Amount,
+							Currency:  params.Currency,
+							Schedule:  params.Schedule,
+							NextRunAt: params.NextRunAt,
+							Status:    utils.ScheduledTransferStatusActive,
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var resp api.ScheduledTransferResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				require.NoError(t, err)
+				require.Equal(t, "850.00", resp.FormattedAmount)
+			},
+		},
+		{
+			name:     "one-off",
+			username: fromAccount.Owner,
+			body: map[string]any{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          100,
+				"start_at":        startAt,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().
+					CreateScheduledTransfer(gomock.Any(), gomock.Eq(db.CreateScheduledTransferParams{
+						Owner:         fromAccount.Owner,
+						FromAccountID: fromAccount.ID,
+						ToAccountID:   toAccount.ID,
+						Amount:        100,
+						Currency:      "USD",
+						NextRunAt:     pgtype.Timestamptz{Time: startAt, Valid: true},
+					})).
+					Times(1).
+					Return(db.ScheduledTransfer{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name:     "start in the past",
+			username: fromAccount.Owner,
+			body: map[string]any{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          100,
+				"start_at":        time.Now().Add(-time.Hour),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "invalid schedule",
+			username: fromAccount.Owner,
+			body: map[string]any{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          100,
+				"schedule":        "every month",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "no schedule nor start",
+			username: fromAccount.Owner,
+			body: map[string]any{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          100,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "account of another user",
+			username: toAccount.Owner,
+			body: map[string]any{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          100,
+				"schedule":        "@weekly",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "currency mismatch",
+			username: fromAccount.Owner,
+			body: map[string]any{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   eurAccount.ID,
+				"amount":          100,
+				"schedule":        "@weekly",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(eurAccount.ID)).Times(1).Return(eurAccount, nil)
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := serveHoldRequest(t, tc.username, utils.RoleCustomer, http.MethodPost, "/scheduled-transfers", tc.body, tc.buildStubs)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestSetScheduledTransferStatus(t *testing.T) {
+	owner := utils.RandomOwner()
+	scheduled := db.ScheduledTransfer{
+		ID:        int32(utils.RandomInt(1, 1000)),
+		Owner:     owner,
+		Amount:    100,
+		Currency:  "USD",
+		Schedule:  "@daily",
+		NextRunAt: pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+		Status:    utils.ScheduledTransferStatusActive,
+	}
+
+	paused := scheduled
+	paused.Status = utils.ScheduledTransferStatusPaused
+	paused.NextRunAt = pgtype.Timestamptz{Time: time.Now().Add(-48 * time.Hour), Valid: true}
+
+	cancelled := scheduled
+	cancelled.Status = utils.ScheduledTransferStatusCancelled
+
+	exhausted := paused
+	exhausted.Schedule = "0 0 30 2 *"
+
+	testCases := []struct {
+		name          string
+		username      string
+		action        string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "pause",
+			username: owner,
+			action:   "pause",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(scheduled, nil)
+				store.EXPECT().
+					UpdateScheduledTransferStatus(gomock.Any(), gomock.Eq(db.UpdateScheduledTransferStatusParams{
+						ID:            scheduled.ID,
+						Status:        utils.ScheduledTransferStatusPaused,
+						CurrentStatus: utils.ScheduledTransferStatusActive,
+					})).
+					Times(1).
+					Return(paused, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "resume skips missed runs",
+			username: owner,
+			action:   "resume",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(paused, nil)
+				store.EXPECT().
+					UpdateScheduledTransferStatus(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, params db.UpdateScheduledTransferStatusParams) (db.ScheduledTransfer, error) {
+						require.Equal(t, utils.ScheduledTransferStatusActive, params.Status)
+						require.Equal(t, utils.ScheduledTransferStatusPaused, params.CurrentStatus)
+						require.True(t, params.NextRunAt.Valid)
+						require.True(t, params.NextRunAt.Time.After(time.Now()))
+						return scheduled, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "resume without future runs",
+			username: owner,
+			action:   "resume",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(exhausted, nil)
+				store.EXPECT().UpdateScheduledTransferStatus(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:     "cancelled is final",
+			username: owner,
+			action:   "resume",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(cancelled, nil)
+				store.EXPECT().UpdateScheduledTransferStatus(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "changed concurrently",
+			username: owner,
+			action:   "cancel",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(scheduled, nil)
+				store.EXPECT().
+					UpdateScheduledTransferStatus(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ScheduledTransfer{}, pgx.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "scheduled transfer of another user",
+			username: utils.RandomOwner(),
+			action:   "cancel",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(scheduled, nil)
+				store.EXPECT().UpdateScheduledTransferStatus(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fmt.Sprintf("/scheduled-transfers/%d/%s", scheduled.ID, tc.action)
+			recorder := serveHoldRequest(t, tc.username, utils.RoleCustomer, http.MethodPost, path, nil, tc.buildStubs)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListScheduledTransferRuns(t *testing.T) {
+	owner := utils.RandomOwner()
+	scheduled := db.ScheduledTransfer{ID: int32(utils.RandomInt(1, 1000)), Owner: owner}
+	runs := []db.ScheduledTransferRun{
+		{ID: 2, ScheduledTransferID: scheduled.ID, Status: utils.ScheduledRunStatusFailed, Error: "insufficient balance"},
+		{ID: 1, ScheduledTransferID: scheduled.ID, Status: utils.ScheduledRunStatusSucceeded, TransferID: pgtype.Int4{Int32: 5, Valid: true}},
+	}
+
+	buildStubs := func(store *mockdb.MockStore) {
+		store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(scheduled, nil)
+		store.EXPECT().
+			ListScheduledTransferRuns(gomock.Any(), gomock.Eq(db.ListScheduledTransferRunsParams{
+				ScheduledTransferID: scheduled.ID,
+				Limit:               20,
+				Offset:              0,
+			})).
+			Times(1).
+			Return(runs, nil)
+	}
+
+	path := fmt.Sprintf("/scheduled-transfers/%d/runs", scheduled.ID)
+	recorder := serveHoldRequest(t, owner, utils.RoleCustomer, http.MethodGet, path, nil, buildStubs)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var gotRuns []db.ScheduledTransferRun
+	err := json.Unmarshal(recorder.Body.Bytes(), &gotRuns)
+	require.NoError(t, err)
+	require.Equal(t, runs, gotRuns)
+}
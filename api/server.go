@@ -1,36 +1,66 @@
 package api
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 	"github.com/mohammad19khodaei/simple_bank/api/middlewares"
 	"github.com/mohammad19khodaei/simple_bank/api/validators"
+	"github.com/mohammad19khodaei/simple_bank/currency"
 	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/fx"
+	"github.com/mohammad19khodaei/simple_bank/revocation"
 	"github.com/mohammad19khodaei/simple_bank/token"
 	"github.com/mohammad19khodaei/simple_bank/utils"
 )
 
 type server struct {
-	store      db.Store
-	tokenMaker token.Maker
-	config     utils.Config
-	router     *gin.Engine
+	store           db.Store
+	tokenMaker      token.Maker
+	revocationStore revocation.Store
+	rateProvider    fx.RateProvider
+	currencies      *currency.Registry
+	config          utils.Config
+	router          *gin.Engine
 }
 
 func NewServer(config utils.Config, store db.Store) (*server, error) {
-	tokenMaker, err := token.NewPasetoMaker(config.SecretKey)
+	tokenMaker, err := token.NewMaker(config)
+	if err != nil {
+		return nil, err
+	}
+
+	revocationStore, err := newRevocationStore(config, store)
 	if err != nil {
 		return nil, err
 	}
+
+	rateProvider, err := newRateProvider(config, store)
+	if err != nil {
+		return nil, err
+	}
+
+	currencies, err := newCurrencyRegistry(config, store)
+	if err != nil {
+		return nil, err
+	}
+
 	server := &server{
-		tokenMaker: tokenMaker,
-		config:     config,
-		store:      store,
+		tokenMaker:      tokenMaker,
+		revocationStore: revocationStore,
+		rateProvider:    rateProvider,
+		currencies:      currencies,
+		config:          config,
+		store:           store,
 	}
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
-		v.RegisterValidation("currency", validators.CurrencyValidator)
+		v.RegisterValidation("currency", validators.CurrencyValidator(currencies))
+		v.RegisterValidation("account_number", validators.AccountNumberValidator)
+		v.RegisterValidation("cron", validators.CronValidator)
 	}
 
 	server.registerRouter()
@@ -43,17 +73,131 @@ func (s *server) registerRouter() {
 
 	r.POST("/users", s.createUserHandler)
 	r.POST("/users/login", s.login)
+	r.POST("/tokens/renew", s.renewAccessTokenHandler)
+	r.GET("/.well-known/keys", s.publicKeysHandler)
+	r.GET("/currencies", s.listCurrenciesHandler)
+	r.GET("/fees", s.listFeeSchedulesHandler)
+	r.GET("/fees/quote", s.feeQuoteHandler)
 
-	authRoutes := r.Group("/").Use(middlewares.AuthMiddleware(s.tokenMaker))
+	authRoutes := r.Group("/").Use(middlewares.AuthMiddleware(s.tokenMaker, s.revocationStore, s.store))
 
+	authRoutes.POST("/users/logout", s.logoutHandler)
+	authRoutes.POST("/users/logout/all", s.logoutAllHandler)
+	authRoutes.PATCH("/users/me/password", s.changePasswordHandler)
 	authRoutes.POST("/accounts", s.createAccountHandler)
+	authRoutes.GET("/accounts/lookup", s.lookupRecipientHandler)
 	authRoutes.GET("/accounts/:id", s.getAccountHandler)
+	authRoutes.POST("/accounts/:id/close", s.closeAccountHandler)
+	authRoutes.GET("/accounts/:id/entries", s.listAccountEntriesHandler)
+	authRoutes.GET("/accounts/:id/limits", s.getAccountLimitsHandler)
 	authRoutes.GET("/accounts", s.ListAccountsHandler)
 	authRoutes.POST("/transfer", s.transferHandler)
+	authRoutes.GET("/transfers", s.listTransfersHandler)
+	authRoutes.POST("/transfers/batch", s.batchTransferHandler)
+	authRoutes.GET("/transfers/batch/:id", s.getTransferBatchHandler)
+	authRoutes.GET("/transfers/:id", s.getTransferHandler)
+	authRoutes.POST("/transfers/:id/reversals", s.reverseTransferHandler)
+	authRoutes.POST("/fx/quotes", s.createFxQuoteHandler)
+	authRoutes.POST("/holds", s.createHoldHandler)
+	authRoutes.GET("/holds/:id", s.getHoldHandler)
+	authRoutes.POST("/holds/:id/capture", s.captureHoldHandler)
+	authRoutes.POST("/holds/:id/void", s.voidHoldHandler)
+	authRoutes.POST("/scheduled-transfers", s.createScheduledTransferHandler)
+	authRoutes.GET("/scheduled-transfers", s.listScheduledTransfersHandler)
+	authRoutes.GET("/scheduled-transfers/:id", s.getScheduledTransferHandler)
+	authRoutes.GET("/scheduled-transfers/:id/runs", s.listScheduledTransferRunsHandler)
+	authRoutes.POST("/scheduled-transfers/:id/pause", s.pauseScheduledTransferHandler)
+	authRoutes.POST("/scheduled-transfers/:id/resume", s.resumeScheduledTransferHandler)
+	authRoutes.POST("/scheduled-transfers/:id/cancel", s.cancelScheduledTransferHandler)
+
+	staffRoutes := r.Group("/admin").Use(
+		middlewares.AuthMiddleware(s.tokenMaker, s.revocationStore, s.store),
+		middlewares.RoleMiddleware(utils.RoleSupport, utils.RoleAdmin),
+	)
+
+	staffRoutes.GET("/users", s.adminListUsersHandler)
+	staffRoutes.GET("/users/:username", s.adminGetUserHandler)
+	staffRoutes.POST("/users/:username/freeze", s.adminFreezeUserHandler)
+	staffRoutes.POST("/users/:username/unfreeze", s.adminUnfreezeUserHandler)
+	staffRoutes.POST("/users/:username/sessions/revoke", s.adminRevokeSessionsHandler)
+	staffRoutes.GET("/accounts/:id", s.adminGetAccountHandler)
+	staffRoutes.POST("/accounts/:id/freeze", s.adminFreezeAccountHandler)
+	staffRoutes.POST("/accounts/:id/unfreeze", s.adminUnfreezeAccountHandler)
+
+	adminRoutes := r.Group("/admin").Use(
+		middlewares.AuthMiddleware(s.tokenMaker, s.revocationStore, s.store),
+		middlewares.RoleMiddleware(utils.RoleAdmin),
+	)
+
+	adminRoutes.POST("/users/:username/password", s.adminResetPasswordHandler)
+	adminRoutes.PUT("/transfer-limits", s.adminSetTransferLimitHandler)
+	adminRoutes.PUT("/fee-schedules", s.adminSetFeeScheduleHandler)
+	adminRoutes.DELETE("/fee-schedules/:currency", s.adminDeleteFeeScheduleHandler)
+
+	// deposits and withdrawals move money in and out of the bank, so only
+	// admins can post them
+	cashRoutes := r.Group("/").Use(
+		middlewares.AuthMiddleware(s.tokenMaker, s.revocationStore, s.store),
+		middlewares.RoleMiddleware(utils.RoleAdmin),
+	)
+
+	cashRoutes.POST("/accounts/:id/deposits", s.depositHandler)
+	cashRoutes.POST("/accounts/:id/withdrawals", s.withdrawHandler)
 
 	s.router = r
 }
 
+func newRevocationStore(config utils.Config, store db.Store) (revocation.Store, error) {
+	switch config.RevocationStore {
+	case "", "memory":
+		return revocation.NewMemoryStore(), nil
+	case "postgres":
+		return revocation.NewPostgresStore(store), nil
+	default:
+		return nil, fmt.Errorf("unsupported REVOCATION_STORE %q, must be memory or postgres", config.RevocationStore)
+	}
+}
+
+func newCurrencyRegistry(config utils.Config, store db.Store) (*currency.Registry, error) {
+	switch config.CurrencySource {
+	case "", "static":
+		var registry *currency.Registry
+		var err error
+		if config.CurrenciesFile == "" {
+			registry, err = currency.NewRegistry(currency.Defaults())
+		} else {
+			registry, err = currency.LoadFile(config.CurrenciesFile)
+		}
+		if err != nil {
+			return nil, err
+		}
+		// the store converts amounts by the minor units of the currencies
+		// table, refuse to start rather than disagree with it
+		if err := currency.CheckPostgres(context.Background(), store, registry); err != nil {
+			return nil, err
+		}
+		return registry, nil
+	case "postgres":
+		return currency.LoadPostgres(context.Background(), store)
+	default:
+		return nil, fmt.Errorf("unsupported CURRENCY_SOURCE %q, must be static or postgres", config.CurrencySource)
+	}
+}
+
+func newRateProvider(config utils.Config, store db.Store) (fx.RateProvider, error) {
+	switch config.FxProvider {
+	case "", "static":
+		if config.FxRatesFile == "" {
+			return fx.NewStaticProvider(nil)
+		}
+		return fx.NewStaticProviderFromFile(config.FxRatesFile)
+	case "postgres":
+		return fx.NewPostgresProvider(store), nil
+	default:
+		return nil, fmt.Errorf("unsupported FX_PROVIDER %q, must be static or postgres", config.FxProvider)
+	}
+}
+
 func (s *server) Start(address string) error {
 	return s.router.Run(address)
 }
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mohammad19khodaei/simple_bank/token"
+)
+
+type renewAccessTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type RenewAccessTokenResponse struct {
+	AccessToken          string    `json:"access_token"`
+	AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
+}
+
+func (s *server) renewAccessTokenHandler(ctx *gin.Context) {
+	var request renewAccessTokenRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	refreshPayload, err := s.tokenMaker.VerifyToken(request.RefreshToken)
+	if err == nil && refreshPayload.Type != token.TokenTypeRefresh {
+		err = token.ErrInvalidToken
+	}
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, s.errorResponse(err))
+		return
+	}
+
+	sessionID, err := uuid.Parse(refreshPayload.ID)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, s.errorResponse(err))
+		return
+	}
+
+	session, err := s.store.GetSession(ctx, pgtype.UUID{Bytes: sessionID, Valid: true})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, s.errorResponse(errors.New("session not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	if session.IsBlocked {
+		ctx.JSON(http.StatusUnauthorized, s.errorResponse(errors.New("session is blocked")))
+		return
+	}
+
+	if session.Username != refreshPayload.Username {
+		ctx.JSON(http.StatusUnauthorized, s.errorResponse(errors.New("session does not belong to the token owner")))
+		return
+	}
+
+	if session.RefreshToken != request.RefreshToken {
+		ctx.JSON(http.StatusUnauthorized, s.errorResponse(errors.New("refresh token does not match the session")))
+		return
+	}
+
+	if time.Now().After(session.ExpiresAt.Time) {
+		ctx.JSON(http.StatusUnauthorized, s.errorResponse(errors.New("session has expired")))
+		return
+	}
+
+	accessToken, accessPayload, err := s.tokenMaker.GenerateToken(refreshPayload.Username, refreshPayload.Role, s.config.TokenDuration, token.TokenTypeAccess)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, RenewAccessTokenResponse{
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: accessPayload.ExpiresAt.Time,
+	})
+}
@@ -0,0 +1,179 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mohammad19khodaei/simple_bank/api"
+	mockdb "github.com/mohammad19khodaei/simple_bank/db/mock"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/token"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRenewAccessToken(t *testing.T) {
+	tokenMaker, err := token.NewPasetoMaker(config.SecretKey)
+	require.NoError(t, err)
+
+	username := utils.RandomOwner()
+	refreshToken, refreshPayload, err := tokenMaker.GenerateToken(username, utils.RoleCustomer, config.RefreshTokenDuration, token.TokenTypeRefresh)
+	require.NoError(t, err)
+
+	accessToken, _, err := tokenMaker.GenerateToken(username, utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
+	require.NoError(t, err)
+
+	session := db.Session{
+		ID:           pgtype.UUID{Bytes: uuid.MustParse(refreshPayload.ID), Valid: true},
+		Username:     username,
+		RefreshToken: refreshToken,
+		ExpiresAt:    pgtype.Timestamptz{Time: refreshPayload.ExpiresAt.Time, Valid: true},
+	}
+
+	testCases := []struct {
+		name          string
+		params        renewAccessTokenParams
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "without refresh token",
+			params: renewAccessTokenParams{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "invalid refresh token",
+			params: renewAccessTokenParams{RefreshToken: "invalid-token"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "access token",
+			params: renewAccessTokenParams{RefreshToken: accessToken},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "session not found",
+			params: renewAccessTokenParams{RefreshToken: refreshToken},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(db.Session{}, pgx.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "blocked session",
+			params: renewAccessTokenParams{RefreshToken: refreshToken},
+			buildStubs: func(store *mockdb.MockStore) {
+				blockedSession := session
+				blockedSession.IsBlocked = true
+
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(blockedSession, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "mismatched refresh token",
+			params: renewAccessTokenParams{RefreshToken: refreshToken},
+			buildStubs: func(store *mockdb.MockStore) {
+				otherSession := session
+				otherSession.RefreshToken = utils.RandomString(32)
+
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(otherSession, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "ok",
+			params: renewAccessTokenParams{RefreshToken: refreshToken},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp api.RenewAccessTokenResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				require.NoError(t, err)
+				require.NotEmpty(t, resp.AccessToken)
+				require.WithinDuration(t, time.Now().Add(config.TokenDuration), resp.AccessTokenExpiresAt, time.Second)
+
+				payload, err := tokenMaker.VerifyToken(resp.AccessToken)
+				require.NoError(t, err)
+				require.Equal(t, username, payload.Username)
+				require.Equal(t, token.TokenTypeAccess, payload.Type)
+			},
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := newMockStore(ctrl)
+
+	server, err := api.NewServer(config, store)
+	require.NoError(t, err)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.buildStubs(store)
+
+			recorder := httptest.NewRecorder()
+			jsonData, err := json.Marshal(tc.params)
+			require.NoError(t, err)
+			request := httptest.NewRequest(http.MethodPost, "/tokens/renew", bytes.NewReader(jsonData))
+
+			server.Router().ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+type renewAccessTokenParams struct {
+	RefreshToken string `json:"refresh_token"`
+}
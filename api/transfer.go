@@ -1,20 +1,47 @@
 package api
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
-	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/mohammad19khodaei/simple_bank/api/middlewares"
+	"github.com/mohammad19khodaei/simple_bank/api/policies"
 	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/token"
 )
 
+// IdempotencyKeyHeader lets clients retry POST /transfer safely: a request
+// replayed with the same key gets the original response back until the key
+// expires after IDEMPOTENCY_KEY_DURATION.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
 type transferRequest struct {
 	FromAccountID int32 `json:"from_account_id" binding:"required,min=1"`
-	ToAccountID   int32 `json:"to_account_id" binding:"required,min=1"`
-	Amount        int64 `json:"amount" binding:"required,gt=0"`
+	// the recipient is addressed by exactly one of ToAccountID,
+	// ToAccountNumber or ToUsername, the latter with ToCurrency which
+	// defaults to the currency of the from account
+	ToAccountID     int32  `json:"to_account_id,omitempty" binding:"omitempty,min=1"`
+	ToAccountNumber string `json:"to_account_number,omitempty" binding:"omitempty,account_number"`
+	ToUsername      string `json:"to_username,omitempty" binding:"omitempty,alphanum"`
+	ToCurrency      string `json:"to_currency,omitempty" binding:"omitempty,currency"`
+	// Amount is in the minor unit of the from account currency, 1250 is
+	// 12.50 USD. AmountDecimal is the same amount as a decimal string in the
+	// major unit, "12.50". Exactly one of them must be sent.
+	Amount        int64  `json:"amount,omitempty" binding:"omitempty,gt=0"`
+	AmountDecimal string `json:"amount_decimal,omitempty" binding:"required_without=Amount,excluded_with=Amount"`
+	Description   string `json:"description,omitempty" binding:"omitempty,max=255"`
+	Reference     string `json:"reference,omitempty" binding:"omitempty,max=64"`
+	// QuoteID is the quote from POST /fx/quotes, it is required when the
+	// accounts hold different currencies.
+	QuoteID string `json:"quote_id,omitempty" binding:"omitempty,uuid"`
 }
 
 func (s *server) transferHandler(ctx *gin.Context) {
@@ -24,6 +51,51 @@ func (s *server) transferHandler(ctx *gin.Context) {
 		return
 	}
 
+	recipients := 0
+	for _, set := range []bool{request.ToAccountID != 0, request.ToAccountNumber != "", request.ToUsername != ""} {
+		if set {
+			recipients++
+		}
+	}
+	if recipients != 1 {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(errRecipientRequired))
+		return
+	}
+
+	var quoteID pgtype.UUID
+	if request.QuoteID != "" {
+		id, err := uuid.Parse(request.QuoteID)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+			return
+		}
+		quoteID = pgtype.UUID{Bytes: id, Valid: true}
+	}
+
+	var idempotency *db.TransferIdempotency
+	if key := ctx.GetHeader(IdempotencyKeyHeader); key != "" {
+		if len(key) > 255 {
+			ctx.JSON(http.StatusBadRequest, s.errorResponse(errors.New("idempotency key must be at most 255 characters")))
+			return
+		}
+
+		requestHash, err := hashTransferRequest(request)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+			return
+		}
+
+		idempotency = &db.TransferIdempotency{
+			Username:    ctx.MustGet(middlewares.AuthUsernameKey).(string),
+			Key:         key,
+			RequestHash: requestHash,
+			Duration:    s.config.IdempotencyKeyDuration,
+		}
+		if s.replayTransfer(ctx, idempotency) {
+			return
+		}
+	}
+
 	fromAccount, err := s.store.GetAccount(ctx, request.FromAccountID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -34,42 +106,247 @@ func (s *server) transferHandler(ctx *gin.Context) {
 		return
 	}
 
-	username := ctx.MustGet(middlewares.AuthUsernameKey).(string)
-	if fromAccount.Owner != username {
+	payload := ctx.MustGet(middlewares.AuthPayloadKey).(*token.Payload)
+	if !policies.CanDebitAccount(payload, fromAccount) {
 		ctx.JSON(http.StatusForbidden, s.errorResponse(errors.New("forbidden: account does not belong to you")))
 		return
 	}
 
-	if fromAccount.Balance < request.Amount {
-		ctx.JSON(http.StatusPaymentRequired, s.errorResponse(errors.New("insufficient balance")))
+	toAccountID := request.ToAccountID
+	if toAccountID == 0 {
+		toCurrency := request.ToCurrency
+		if toCurrency == "" {
+			toCurrency = fromAccount.Currency
+		}
+
+		toAccount, err := s.findRecipient(ctx, request.ToAccountNumber, request.ToUsername, toCurrency)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				ctx.JSON(http.StatusNotFound, s.errorResponse(errors.New("recipient not found")))
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+			return
+		}
+		toAccountID = toAccount.ID
+	}
+
+	if toAccountID == fromAccount.ID {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(db.ErrSameAccount))
+		return
+	}
+
+	amount := request.Amount
+	if request.AmountDecimal != "" {
+		amount, err = s.parseAmount(request.AmountDecimal, fromAccount.Currency)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+			return
+		}
+	}
+
+	// balance, currency, quote and status are checked by TransferTx on the locked
+	// accounts, a check made here could be stale by the time money moves
+	transfer, err := s.store.TransferTx(ctx, db.TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccountID,
+		Amount:        amount,
+		Description:   request.Description,
+		Reference:     request.Reference,
+		QuoteID:       quoteID,
+		Idempotency:   idempotency,
+	})
+
+	if err != nil {
+		// a concurrent request with the same key won the race
+		if errors.Is(err, db.ErrIdempotencyKeyExists) && s.replayTransfer(ctx, idempotency) {
+			return
+		}
+		ctx.JSON(transferErrorStatus(err), s.errorResponse(err))
 		return
 	}
 
-	toAccount, err := s.store.GetAccount(ctx, request.ToAccountID)
+	resp, err := s.newPartyTransferTxResponse(ctx, transfer, true)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, resp)
+}
+
+// transferErrorStatus maps the errors of the money moving transactions of
+// db.Store to the status code of the response.
+func transferErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return http.StatusNotFound
+	case errors.Is(err, db.ErrInsufficientBalance):
+		return http.StatusPaymentRequired
+	case errors.Is(err, db.ErrCurrencyMismatch), errors.Is(err, db.ErrSameAccount):
+		return http.StatusBadRequest
+	case errors.Is(err, db.ErrAccountNotActive), errors.Is(err, db.ErrNotCustomerAccount):
+		return http.StatusForbidden
+	case errors.Is(err, db.ErrInvalidQuote), errors.Is(err, db.ErrTransferNotReversible), errors.Is(err, db.ErrHoldExpired),
+		errors.Is(err, db.ErrTransferLimitExceeded):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, db.ErrReversalExceedsTransfer), errors.Is(err, db.ErrHoldNotPending), errors.Is(err, db.ErrCaptureExceedsHold):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// replayTransfer writes the stored response of an earlier transfer made with
+// the same idempotency key. It reports whether a response was written.
+func (s *server) replayTransfer(ctx *gin.Context, idempotency *db.TransferIdempotency) bool {
+	stored, err := s.store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{
+		Username: idempotency.Username,
+		Key:      idempotency.Key,
+	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			ctx.JSON(http.StatusNotFound, s.errorResponse(err))
-			return
+			return false
 		}
 		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return true
+	}
+
+	if stored.RequestHash != idempotency.RequestHash {
+		ctx.JSON(http.StatusUnprocessableEntity, s.errorResponse(errors.New("idempotency key was already used with a different request")))
+		return true
+	}
+
+	var result db.TransferTxResult
+	if err := json.Unmarshal(stored.Response, &result); err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return true
+	}
+
+	resp, err := s.newPartyTransferTxResponse(ctx, result, true)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return true
+	}
+
+	ctx.Header("Idempotent-Replayed", "true")
+	ctx.JSON(http.StatusCreated, resp)
+	return true
+}
+
+func hashTransferRequest(request transferRequest) (string, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+type listTransfersQuery struct {
+	AccountID int32     `form:"account_id" binding:"omitempty,min=1"`
+	Direction string    `form:"direction" binding:"omitempty,oneof=in out"`
+	MinAmount int64     `form:"min_amount" binding:"omitempty,gt=0"`
+	MaxAmount int64     `form:"max_amount" binding:"omitempty,gt=0"`
+	From      time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To        time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Reference string    `form:"reference" binding:"omitempty,max=64"`
+	Page      int32     `form:"page" binding:"omitempty,min=1"`
+	PerPage   int32     `form:"per_page" binding:"omitempty,min=5,max=50"`
+}
+
+// listTransfersHandler lists the transfers that touch any account of the
+// caller, newest first. Direction is relative to the caller: "out" keeps the
+// transfers debiting one of their accounts and "in" the ones crediting it.
+func (s *server) listTransfersHandler(ctx *gin.Context) {
+	var query listTransfersQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
 		return
 	}
 
-	if fromAccount.Currency != toAccount.Currency {
-		ctx.JSON(http.StatusBadRequest, s.errorResponse(errors.New(fmt.Sprintf("from account currency %s mismatch to account currency %s", fromAccount.Currency, toAccount.Currency))))
+	if query.MinAmount != 0 && query.MaxAmount != 0 && query.MinAmount > query.MaxAmount {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(errors.New("min_amount must not be greater than max_amount")))
 		return
 	}
 
-	transfer, err := s.store.TransferTx(ctx, db.TransferTxParams{
-		FromAccountID: fromAccount.ID,
-		ToAccountID:   toAccount.ID,
-		Amount:        request.Amount,
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(errors.New("from must be before to")))
+		return
+	}
+
+	page := int32(1)
+	if query.Page != 0 {
+		page = query.Page
+	}
+
+	perPage := int32(20)
+	if query.PerPage != 0 {
+		perPage = query.PerPage
+	}
+
+	username := ctx.MustGet(middlewares.AuthUsernameKey).(string)
+
+	transfers, err := s.store.ListOwnerTransfers(ctx, db.ListOwnerTransfersParams{
+		Direction: query.Direction,
+		Owner:     username,
+		AccountID: pgtype.Int4{Int32: query.AccountID, Valid: query.AccountID != 0},
+		MinAmount: pgtype.Int8{Int64: query.MinAmount, Valid: query.MinAmount != 0},
+		MaxAmount: pgtype.Int8{Int64: query.MaxAmount, Valid: query.MaxAmount != 0},
+		FromTime:  pgtype.Timestamptz{Time: query.From, Valid: !query.From.IsZero()},
+		ToTime:    pgtype.Timestamptz{Time: query.To, Valid: !query.To.IsZero()},
+		Reference: pgtype.Text{String: query.Reference, Valid: query.Reference != ""},
+		Limit:     perPage,
+		Offset:    (page - 1) * perPage,
 	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, s.newTransfersResponse(transfers))
+}
+
+type getTransferParams struct {
+	ID int32 `uri:"id" binding:"required,min=1"`
+}
 
+func (s *server) getTransferHandler(ctx *gin.Context) {
+	var params getTransferParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	transfer, err := s.store.GetTransfer(ctx, params.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, s.errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	fromAccount, err := s.store.GetAccount(ctx, transfer.FromAccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	toAccount, err := s.store.GetAccount(ctx, transfer.ToAccountID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusCreated, transfer)
+	payload := ctx.MustGet(middlewares.AuthPayloadKey).(*token.Payload)
+	if !policies.CanReadTransfer(payload, fromAccount, toAccount) {
+		// do not reveal that a transfer with this id exists
+		ctx.JSON(http.StatusNotFound, s.errorResponse(pgx.ErrNoRows))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, s.newTransferResponse(transfer))
 }
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/mohammad19khodaei/simple_bank/api/middlewares"
+	"github.com/mohammad19khodaei/simple_bank/api/policies"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/token"
+)
+
+type batchTransferItem struct {
+	// the recipient is addressed by exactly one of ToAccountID or
+	// ToAccountNumber
+	ToAccountID     int32  `json:"to_account_id,omitempty" binding:"omitempty,min=1"`
+	ToAccountNumber string `json:"to_account_number,omitempty" binding:"omitempty,account_number"`
+	// Amount and AmountDecimal work as on POST /transfer, exactly one of
+	// them must be sent.
+	Amount        int64  `json:"amount,omitempty" binding:"omitempty,gt=0"`
+	AmountDecimal string `json:"amount_decimal,omitempty" binding:"required_without=Amount,excluded_with=Amount"`
+	Description   string `json:"description,omitempty" binding:"omitempty,max=255"`
+	Reference     string `json:"reference,omitempty" binding:"omitempty,max=64"`
+}
+
+type batchTransferRequest struct {
+	FromAccountID int32  `json:"from_account_id" binding:"required,min=1"`
+	Mode          string `json:"mode" binding:"required,oneof=atomic best_effort"`
+	// Items are posted in the order they are sent. Their number is bounded
+	// as the batch keeps every account it touches locked until it is done.
+	Items []batchTransferItem `json:"items" binding:"required,min=1,max=1000,dive"`
+}
+
+// batchTransferHandler pays many recipients from one account in a single
+// transaction. Recipients are resolved and amounts parsed before any money
+// moves, a bad item rejects the request whatever the mode. The mode only
+// decides what happens to items the locked accounts refuse: an atomic batch
+// fails as a whole, a best effort batch records them as failed.
+func (s *server) batchTransferHandler(ctx *gin.Context) {
+	var request batchTransferRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	fromAccount, err := s.store.GetAccount(ctx, request.FromAccountID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, s.errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	payload := ctx.MustGet(middlewares.AuthPayloadKey).(*token.Payload)
+	if !policies.CanDebitAccount(payload, fromAccount) {
+		ctx.JSON(http.StatusForbidden, s.errorResponse(errors.New("forbidden: account does not belong to you")))
+		return
+	}
+
+	items := make([]db.BatchTransferItem, 0, len(request.Items))
+	for i, item := range request.Items {
+		if (item.ToAccountID == 0) == (item.ToAccountNumber == "") {
+			ctx.JSON(http.StatusBadRequest, s.errorResponse(fmt.Errorf("item %d: exactly one of to_account_id or to_account_number is required", i)))
+			return
+		}
+
+		toAccountID := item.ToAccountID
+		if toAccountID == 0 {
+			toAccount, err := s.findRecipient(ctx, item.ToAccountNumber, "", "")
+			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					ctx.JSON(http.StatusNotFound, s.errorResponse(fmt.Errorf("item %d: recipient not found", i)))
+					return
+				}
+				ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+				return
+			}
+			toAccountID = toAccount.ID
+		}
+
+		if toAccountID == fromAccount.ID {
+			ctx.JSON(http.StatusBadRequest, s.errorResponse(fmt.Errorf("item %d: %w", i, db.ErrSameAccount)))
+			return
+		}
+
+		amount := item.Amount
+		if item.AmountDecimal != "" {
+			amount, err = s.parseAmount(item.AmountDecimal, fromAccount.Currency)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, s.errorResponse(fmt.Errorf("item %d: %w", i, err)))
+				return
+			}
+		}
+
+		items = append(items, db.BatchTransferItem{
+			ToAccountID: toAccountID,
+			Amount:      amount,
+			Description: item.Description,
+			Reference:   item.Reference,
+		})
+	}
+
+	result, err := s.store.BatchTransferTx(ctx, db.BatchTransferTxParams{
+		Owner:         payload.Username,
+		FromAccountID: fromAccount.ID,
+		Mode:          request.Mode,
+		Items:         items,
+	})
+	if err != nil {
+		ctx.JSON(transferErrorStatus(err), s.errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, s.newTransferBatchResponse(result.Batch, result.Items))
+}
+
+type getTransferBatchParams struct {
+	ID int32 `uri:"id" binding:"required,min=1"`
+}
+
+// getTransferBatchHandler returns a batch with the outcome of every item to
+// the user who sent it.
+func (s *server) getTransferBatchHandler(ctx *gin.Context) {
+	var params getTransferBatchParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	batch, err := s.store.GetTransferBatch(ctx, params.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, s.errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	// do not reveal that a batch with this id exists
+	if batch.Owner != ctx.MustGet(middlewares.AuthUsernameKey).(string) {
+		ctx.JSON(http.StatusNotFound, s.errorResponse(pgx.ErrNoRows))
+		return
+	}
+
+	items, err := s.store.ListTransferBatchItems(ctx, batch.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, s.newTransferBatchResponse(batch, items))
+}
@@ -0,0 +1,303 @@
+package api_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mohammad19khodaei/simple_bank/api"
+	mockdb "github.com/mohammad19khodaei/simple_bank/db/mock"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestBatchTransfer(t *testing.T) {
+	fromAccount := createRandomAccount("USD")
+	toAccount1 := createRandomAccount("USD")
+	toAccount1.ID = fromAccount.ID + 1
+	toAccount2 := createRandomAccount("USD")
+	toAccount2.ID = fromAccount.ID + 2
+
+	items := []map[string]any{
+		{"to_account_id": toAccount1.ID, "amount": 1500, "reference": "salary-1"},
+		{"to_account_number": toAccount2.Number, "amount_decimal": "20.00"},
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		body          map[string]any
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: fromAccount.Owner,
+			body: map[string]any{
+				"from_account_id": fromAccount.ID,
+				"mode":            utils.TransferBatchModeAtomic,
+				"items":           items,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(toAccount2.Number)).Times(1).Return(toAccount2, nil)
+				store.EXPECT().
+					BatchTransferTx(gomock.Any(), gomock.Eq(db.BatchTransferTxParams{
+						Owner:         fromAccount.Owner,
+						FromAccountID: fromAccount.ID,
+						Mode:          utils.TransferBatchModeAtomic,
+						Items: []db.BatchTransferItem{
+							{ToAccountID: toAccount1.ID, Amount: 1500, Reference: "salary-1"},
+							{ToAccountID: toAccount2.ID, Amount: 2000},
+						},
+					})).
+					Times(1).
+					Return(db.BatchTransferTxResult{
+						Batch: db.TransferBatch{
+							ID:             1,
+							Currency:       "USD",
+							Mode:           utils.TransferBatchModeAtomic,
+							Status:         utils.TransferBatchStatusCompleted,
+							ItemCount:      2,
+							SucceededCount: 2,
+							TotalAmount:    3500,
+						},
+						Items: []db.TransferBatchItem{
+							{ID: 1, BatchID: 1, Position: 0, ToAccountID: toAccount1.ID, Amount: 1500, Status: utils.TransferBatchItemStatusSucceeded},
+							{ID: 2, BatchID: 1, Position: 1, ToAccountID: toAccount2.ID, Amount: 2000, Status: utils.TransferBatchItemStatusSucceeded},
+						},
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var resp api.TransferBatchResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				require.NoError(t, err)
+				require.Equal(t, int32(1), resp.ID)
+				require.Equal(t, "35.00", resp.FormattedTotalAmount)
+				require.Len(t, resp.Items, 2)
+				require.Equal(t, "15.00", resp.Items[0].FormattedAmount)
+				require.Equal(t, "20.00", resp.Items[1].FormattedAmount)
+			},
+		},
+		{
+			name:     "atomic batch fails",
+			username: fromAccount.Owner,
+			body: map[string]any{
+				"from_account_id": fromAccount.ID,
+				"mode":            utils.TransferBatchModeAtomic,
+				"items":           items[:1],
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().
+					BatchTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.BatchTransferTxResult{}, fmt.Errorf("item 0: %w", db.ErrInsufficientBalance))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPaymentRequired, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "item 0")
+			},
+		},
+		{
+			name:     "recipient not found",
+			username: fromAccount.Owner,
+			body: map[string]any{
+				"from_account_id": fromAccount.ID,
+				"mode":            utils.TransferBatchModeBestEffort,
+				"items":           items,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, pgx.ErrNoRows)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "item 1")
+			},
+		},
+		{
+			name:     "pays the from account",
+			username: fromAccount.Owner,
+			body: map[string]any{
+				"from_account_id": fromAccount.ID,
+				"mode":            utils.TransferBatchModeBestEffort,
+				"items":           []map[string]any{{"to_account_id": fromAccount.ID, "amount": 10}},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "no recipient",
+			username: fromAccount.Owner,
+			body: map[string]any{
+				"from_account_id": fromAccount.ID,
+				"mode":            utils.TransferBatchModeBestEffort,
+				"items":           []map[string]any{{"amount": 10}},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "account of another user",
+			username: toAccount1.Owner,
+			body: map[string]any{
+				"from_account_id": fromAccount.ID,
+				"mode":            utils.TransferBatchModeAtomic,
+				"items":           items,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "invalid mode",
+			username: fromAccount.Owner,
+			body: map[string]any{
+				"from_account_id": fromAccount.ID,
+				"mode":            "eventually",
+				"items":           items,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "invalid item",
+			username: fromAccount.Owner,
+			body: map[string]any{
+				"from_account_id": fromAccount.ID,
+				"mode":            utils.TransferBatchModeAtomic,
+				"items":           []map[string]any{{"to_account_id": toAccount1.ID, "amount": -5}},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "no items",
+			username: fromAccount.Owner,
+			body: map[string]any{
+				"from_account_id": fromAccount.ID,
+				"mode":            utils.TransferBatchModeAtomic,
+				"items":           []map[string]any{},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := serveHoldRequest(t, tc.username, utils.RoleCustomer, http.MethodPost, "/transfers/batch", tc.body, tc.buildStubs)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetTransferBatch(t *testing.T) {
+	owner := utils.RandomOwner()
+	batch := db.TransferBatch{
+		ID:             int32(utils.RandomInt(1, 1000)),
+		Owner:          owner,
+		Currency:       "USD",
+		Mode:           utils.TransferBatchModeBestEffort,
+		Status:         utils.TransferBatchStatusPartiallyCompleted,
+		ItemCount:      2,
+		SucceededCount: 1,
+		TotalAmount:    100,
+	}
+	items := []db.TransferBatchItem{
+		{ID: 1, BatchID: batch.ID, Position: 0, Amount: 100, Status: utils.TransferBatchItemStatusSucceeded, TransferID: pgtype.Int4{Int32: 7, Valid: true}},
+		{ID: 2, BatchID: batch.ID, Position: 1, Amount: 5000, Status: utils.TransferBatchItemStatusFailed, Error: "insufficient balance"},
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferBatch(gomock.Any(), gomock.Eq(batch.ID)).Times(1).Return(batch, nil)
+				store.EXPECT().ListTransferBatchItems(gomock.Any(), gomock.Eq(batch.ID)).Times(1).Return(items, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp api.TransferBatchResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				require.NoError(t, err)
+				require.Equal(t, batch, resp.TransferBatch)
+				require.Len(t, resp.Items, 2)
+				require.Equal(t, items[1], resp.Items[1].TransferBatchItem)
+				require.Equal(t, "50.00", resp.Items[1].FormattedAmount)
+			},
+		},
+		{
+			name:     "batch of another user",
+			username: utils.RandomOwner(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferBatch(gomock.Any(), gomock.Eq(batch.ID)).Times(1).Return(batch, nil)
+				store.EXPECT().ListTransferBatchItems(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "not found",
+			username: owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferBatch(gomock.Any(), gomock.Eq(batch.ID)).Times(1).Return(db.TransferBatch{}, pgx.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := fmt.Sprintf("/transfers/batch/%d", batch.ID)
+			recorder := serveHoldRequest(t, tc.username, utils.RoleCustomer, http.MethodGet, path, nil, tc.buildStubs)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
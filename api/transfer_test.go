@@ -3,12 +3,16 @@ package api_test
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/mohammad19khodaei/simple_bank/api"
@@ -16,6 +20,7 @@ import (
 	mockdb "github.com/mohammad19khodaei/simple_bank/db/mock"
 	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
 	"github.com/mohammad19khodaei/simple_bank/token"
+	"github.com/mohammad19khodaei/simple_bank/utils"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
@@ -24,6 +29,9 @@ func TestTransfer(t *testing.T) {
 	fromAccount := createRandomAccount("USD")
 	toAccount := createRandomAccount("USD")
 	toEURAccount := createRandomAccount("EUR")
+	frozenAccount := createRandomAccount("USD")
+	frozenAccount.Status = utils.AccountStatusFrozen
+	quoteID := uuid.New()
 
 	testCases := []struct {
 		name          string
@@ -40,7 +48,7 @@ func TestTransfer(t *testing.T) {
 				Amount:        10,
 			},
 			setAuthHeader: func(tokenMaker token.Maker, req *http.Request) {
-				token, err := tokenMaker.GenerateToken(fromAccount.Owner, config.TokenDuration)
+				token, _, err := tokenMaker.GenerateToken(fromAccount.Owner, utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
 				require.NoError(t, err)
 				req.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, token))
 			},
@@ -54,6 +62,80 @@ func TestTransfer(t *testing.T) {
 				require.Equal(t, http.StatusNotFound, recorder.Code)
 			},
 		},
+		{
+			name: "admin cannot debit another user's account",
+			params: transferRequest{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   toAccount.ID,
+				Amount:        10,
+			},
+			setAuthHeader: func(tokenMaker token.Maker, req *http.Request) {
+				token, _, err := tokenMaker.GenerateToken(utils.RandomOwner(), utils.RoleAdmin, config.TokenDuration, token.TokenTypeAccess)
+				require.NoError(t, err)
+				req.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, token))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), fromAccount.ID).
+					Times(1).
+					Return(fromAccount, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, _ transferRequest) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "from account is frozen",
+			params: transferRequest{
+				FromAccountID: frozenAccount.ID,
+				ToAccountID:   toAccount.ID,
+				Amount:        10,
+			},
+			setAuthHeader: func(tokenMaker token.Maker, req *http.Request) {
+				token, _, err := tokenMaker.GenerateToken(frozenAccount.Owner, utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
+				require.NoError(t, err)
+				req.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, token))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), frozenAccount.ID).
+					Times(1).
+					Return(frozenAccount, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrAccountNotActive)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, _ transferRequest) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "to account is frozen",
+			params: transferRequest{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   frozenAccount.ID,
+				Amount:        10,
+			},
+			setAuthHeader: func(tokenMaker token.Maker, req *http.Request) {
+				token, _, err := tokenMaker.GenerateToken(fromAccount.Owner, utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
+				require.NoError(t, err)
+				req.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, token))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), fromAccount.ID).
+					Times(1).
+					Return(fromAccount, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrAccountNotActive)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, _ transferRequest) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
 		{
 			name: "amount is greater than from account balance",
 			params: transferRequest{
@@ -62,7 +144,7 @@ func TestTransfer(t *testing.T) {
 				Amount:        fromAccount.Balance + 10,
 			},
 			setAuthHeader: func(tokenMaker token.Maker, req *http.Request) {
-				token, err := tokenMaker.GenerateToken(fromAccount.Owner, config.TokenDuration)
+				token, _, err := tokenMaker.GenerateToken(fromAccount.Owner, utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
 				require.NoError(t, err)
 				req.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, token))
 			},
@@ -71,11 +153,41 @@ func TestTransfer(t *testing.T) {
 					GetAccount(gomock.Any(), fromAccount.ID).
 					Times(1).
 					Return(fromAccount, nil)
+
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrInsufficientBalance)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, _ transferRequest) {
 				require.Equal(t, http.StatusPaymentRequired, recorder.Code)
 			},
 		},
+		{
+			name: "to account is the from account",
+			params: transferRequest{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   fromAccount.ID,
+				Amount:        10,
+			},
+			setAuthHeader: func(tokenMaker token.Maker, req *http.Request) {
+				token, _, err := tokenMaker.GenerateToken(fromAccount.Owner, utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
+				require.NoError(t, err)
+				req.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, token))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), fromAccount.ID).
+					Times(1).
+					Return(fromAccount, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, _ transferRequest) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 		{
 			name: "to account id does not exists",
 			params: transferRequest{
@@ -84,7 +196,7 @@ func TestTransfer(t *testing.T) {
 				Amount:        10,
 			},
 			setAuthHeader: func(tokenMaker token.Maker, req *http.Request) {
-				token, err := tokenMaker.GenerateToken(fromAccount.Owner, config.TokenDuration)
+				token, _, err := tokenMaker.GenerateToken(fromAccount.Owner, utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
 				require.NoError(t, err)
 				req.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, token))
 			},
@@ -95,9 +207,9 @@ func TestTransfer(t *testing.T) {
 					Return(fromAccount, nil)
 
 				store.EXPECT().
-					GetAccount(gomock.Any(), toAccount.ID).
+					TransferTx(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(db.Account{}, pgx.ErrNoRows)
+					Return(db.TransferTxResult{}, pgx.ErrNoRows)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, _ transferRequest) {
 				require.Equal(t, http.StatusNotFound, recorder.Code)
@@ -111,7 +223,7 @@ func TestTransfer(t *testing.T) {
 				Amount:        10,
 			},
 			setAuthHeader: func(tokenMaker token.Maker, req *http.Request) {
-				token, err := tokenMaker.GenerateToken(fromAccount.Owner, config.TokenDuration)
+				token, _, err := tokenMaker.GenerateToken(fromAccount.Owner, utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
 				require.NoError(t, err)
 				req.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, token))
 			},
@@ -122,23 +234,162 @@ func TestTransfer(t *testing.T) {
 					Return(fromAccount, nil)
 
 				store.EXPECT().
-					GetAccount(gomock.Any(), toEURAccount.ID).
+					TransferTx(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(toEURAccount, nil)
+					Return(db.TransferTxResult{}, db.ErrCurrencyMismatch)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, _ transferRequest) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
 		{
-			name: "ok",
+			name: "decimal amount",
+			params: transferRequest{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   toAccount.ID,
+				AmountDecimal: "12.5",
+			},
+			setAuthHeader: func(tokenMaker token.Maker, req *http.Request) {
+				token, _, err := tokenMaker.GenerateToken(fromAccount.Owner, utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
+				require.NoError(t, err)
+				req.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, token))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), fromAccount.ID).
+					Times(1).
+					Return(fromAccount, nil)
+
+				store.EXPECT().
+					TransferTx(gomock.Any(), db.TransferTxParams{
+						FromAccountID: fromAccount.ID,
+						ToAccountID:   toAccount.ID,
+						Amount:        1250,
+					}).
+					Times(1).
+					Return(db.TransferTxResult{
+						Transfer: db.Transfer{
+							ID:            1,
+							FromAccountID: fromAccount.ID,
+							ToAccountID:   toAccount.ID,
+							Amount:        1250,
+							Currency:      fromAccount.Currency,
+						},
+						FromAccount: fromAccount,
+						ToAccount:   toAccount,
+					}, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(toAccount.Owner)).Times(1).Return(db.User{Username: toAccount.Owner}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, _ transferRequest) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var resp api.TransferTxResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				require.NoError(t, err)
+				require.Equal(t, int64(1250), resp.Transfer.Amount)
+				require.Equal(t, "12.50", resp.Transfer.FormattedAmount)
+			},
+		},
+		{
+			name: "decimal amount with too many decimals",
 			params: transferRequest{
 				FromAccountID: fromAccount.ID,
 				ToAccountID:   toAccount.ID,
+				AmountDecimal: "12.505",
+			},
+			setAuthHeader: func(tokenMaker token.Maker, req *http.Request) {
+				token, _, err := tokenMaker.GenerateToken(fromAccount.Owner, utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
+				require.NoError(t, err)
+				req.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, token))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), fromAccount.ID).
+					Times(1).
+					Return(fromAccount, nil)
+
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, _ transferRequest) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "both amount and decimal amount",
+			params: transferRequest{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   toAccount.ID,
+				Amount:        1250,
+				AmountDecimal: "12.50",
+			},
+			setAuthHeader: func(tokenMaker token.Maker, req *http.Request) {
+				token, _, err := tokenMaker.GenerateToken(fromAccount.Owner, utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
+				require.NoError(t, err)
+				req.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, token))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, _ transferRequest) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "missing amount",
+			params: transferRequest{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   toAccount.ID,
+			},
+			setAuthHeader: func(tokenMaker token.Maker, req *http.Request) {
+				token, _, err := tokenMaker.GenerateToken(fromAccount.Owner, utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
+				require.NoError(t, err)
+				req.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, token))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, _ transferRequest) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "invalid quote id",
+			params: transferRequest{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   toEURAccount.ID,
+				Amount:        10,
+				QuoteID:       "not-a-uuid",
+			},
+			setAuthHeader: func(tokenMaker token.Maker, req *http.Request) {
+				token, _, err := tokenMaker.GenerateToken(fromAccount.Owner, utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
+				require.NoError(t, err)
+				req.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, token))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, _ transferRequest) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "expired quote",
+			params: transferRequest{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   toEURAccount.ID,
 				Amount:        10,
+				QuoteID:       quoteID.String(),
 			},
 			setAuthHeader: func(tokenMaker token.Maker, req *http.Request) {
-				token, err := tokenMaker.GenerateToken(fromAccount.Owner, config.TokenDuration)
+				token, _, err := tokenMaker.GenerateToken(fromAccount.Owner, utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
 				require.NoError(t, err)
 				req.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, token))
 			},
@@ -149,15 +400,95 @@ func TestTransfer(t *testing.T) {
 					Return(fromAccount, nil)
 
 				store.EXPECT().
-					GetAccount(gomock.Any(), toAccount.ID).
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrInvalidQuote)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, _ transferRequest) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "cross currency with quote",
+			params: transferRequest{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   toEURAccount.ID,
+				Amount:        100,
+				QuoteID:       quoteID.String(),
+			},
+			setAuthHeader: func(tokenMaker token.Maker, req *http.Request) {
+				token, _, err := tokenMaker.GenerateToken(fromAccount.Owner, utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
+				require.NoError(t, err)
+				req.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, token))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), fromAccount.ID).
 					Times(1).
-					Return(toAccount, nil)
+					Return(fromAccount, nil)
+
+				store.EXPECT().
+					TransferTx(gomock.Any(), db.TransferTxParams{
+						FromAccountID: fromAccount.ID,
+						ToAccountID:   toEURAccount.ID,
+						Amount:        100,
+						QuoteID:       pgtype.UUID{Bytes: quoteID, Valid: true},
+					}).
+					Times(1).
+					Return(db.TransferTxResult{
+						Transfer: db.Transfer{
+							ID:            1,
+							FromAccountID: fromAccount.ID,
+							ToAccountID:   toEURAccount.ID,
+							Amount:        100,
+						},
+						FromAccount: fromAccount,
+						ToAccount:   toEURAccount,
+						ToEntry: db.Entry{
+							ID:        2,
+							AccountID: toEURAccount.ID,
+							Amount:    92,
+						},
+					}, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(toEURAccount.Owner)).Times(1).Return(db.User{Username: toEURAccount.Owner}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, _ transferRequest) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var resp db.TransferTxResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				require.NoError(t, err)
+				require.Equal(t, int64(100), resp.Transfer.Amount)
+				require.Equal(t, int64(92), resp.ToEntry.Amount)
+			},
+		},
+		{
+			name: "ok",
+			params: transferRequest{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   toAccount.ID,
+				Amount:        10,
+				Description:   "rent",
+				Reference:     "INV-42",
+			},
+			setAuthHeader: func(tokenMaker token.Maker, req *http.Request) {
+				token, _, err := tokenMaker.GenerateToken(fromAccount.Owner, utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
+				require.NoError(t, err)
+				req.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, token))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), fromAccount.ID).
+					Times(1).
+					Return(fromAccount, nil)
 
 				store.EXPECT().
 					TransferTx(gomock.Any(), db.TransferTxParams{
 						FromAccountID: fromAccount.ID,
 						ToAccountID:   toAccount.ID,
 						Amount:        10,
+						Description:   "rent",
+						Reference:     "INV-42",
 					}).
 					Times(1).
 					DoAndReturn(func(_ context.Context, arg db.TransferTxParams) (db.TransferTxResult, error) {
@@ -167,6 +498,8 @@ func TestTransfer(t *testing.T) {
 								FromAccountID: arg.FromAccountID,
 								ToAccountID:   arg.ToAccountID,
 								Amount:        arg.Amount,
+								Description:   arg.Description,
+								Reference:     arg.Reference,
 								CreatedAt:     pgtype.Timestamptz{},
 							},
 							FromAccount: fromAccount,
@@ -185,6 +518,7 @@ func TestTransfer(t *testing.T) {
 							},
 						}, nil
 					})
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(toAccount.Owner)).Times(1).Return(db.User{Username: toAccount.Owner}, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, param transferRequest) {
 				require.Equal(t, http.StatusCreated, recorder.Code)
@@ -196,6 +530,8 @@ func TestTransfer(t *testing.T) {
 				require.Equal(t, resp.Transfer.FromAccountID, param.FromAccountID)
 				require.Equal(t, resp.Transfer.ToAccountID, param.ToAccountID)
 				require.Equal(t, resp.Transfer.Amount, param.Amount)
+				require.Equal(t, param.Description, resp.Transfer.Description)
+				require.Equal(t, param.Reference, resp.Transfer.Reference)
 			},
 		},
 	}
@@ -206,7 +542,7 @@ func TestTransfer(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	store := mockdb.NewMockStore(ctrl)
+	store := newMockStore(ctrl)
 
 	server, err := api.NewServer(config, store)
 	require.NoError(t, err)
@@ -228,8 +564,435 @@ func TestTransfer(t *testing.T) {
 	}
 }
 
+func TestTransferIdempotency(t *testing.T) {
+	fromAccount := createRandomAccount("USD")
+	toAccount := createRandomAccount("USD")
+	idempotencyKey := utils.RandomString(16)
+
+	params := transferRequest{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        10,
+	}
+	requestHash := hashTransferRequest(t, params)
+
+	result := db.TransferTxResult{
+		Transfer: db.Transfer{
+			ID:            1,
+			FromAccountID: fromAccount.ID,
+			ToAccountID:   toAccount.ID,
+			Amount:        10,
+			Currency:      fromAccount.Currency,
+		},
+		FromAccount: fromAccount,
+		ToAccount:   toAccount,
+	}
+	response, err := json.Marshal(result)
+	require.NoError(t, err)
+
+	stored := db.IdempotencyKey{
+		Username:    fromAccount.Owner,
+		Key:         idempotencyKey,
+		RequestHash: requestHash,
+		Response:    response,
+	}
+
+	stubRecipient := func(store *mockdb.MockStore) {
+		store.EXPECT().GetUser(gomock.Any(), gomock.Eq(toAccount.Owner)).Times(1).Return(db.User{Username: toAccount.Owner}, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		params        transferRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "first request",
+			params: params,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Eq(db.GetIdempotencyKeyParams{
+						Username: fromAccount.Owner,
+						Key:      idempotencyKey,
+					})).
+					Times(1).
+					Return(db.IdempotencyKey{}, pgx.ErrNoRows)
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(db.TransferTxParams{
+						FromAccountID: fromAccount.ID,
+						ToAccountID:   toAccount.ID,
+						Amount:        10,
+						Idempotency: &db.TransferIdempotency{
+							Username:    fromAccount.Owner,
+							Key:         idempotencyKey,
+							RequestHash: requestHash,
+							Duration:    config.IdempotencyKeyDuration,
+						},
+					})).
+					Times(1).
+					Return(result, nil)
+				stubRecipient(store)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				require.Empty(t, recorder.Header().Get("Idempotent-Replayed"))
+			},
+		},
+		{
+			name:   "replayed request",
+			params: params,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(stored, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+				stubRecipient(store)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				require.Equal(t, "true", recorder.Header().Get("Idempotent-Replayed"))
+
+				var replayed api.TransferTxResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &replayed)
+				require.NoError(t, err)
+				require.Equal(t, result.Transfer, replayed.Transfer.Transfer)
+				require.Equal(t, "0.10", replayed.Transfer.FormattedAmount)
+				require.Equal(t, result.FromAccount, replayed.FromAccount.Account)
+				require.Nil(t, replayed.ToAccount)
+			},
+		},
+		{
+			name: "key reused with a different request",
+			params: transferRequest{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   toAccount.ID,
+				Amount:        20,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(stored, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:   "concurrent request with the same key",
+			params: params,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().
+						GetIdempotencyKey(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(db.IdempotencyKey{}, pgx.ErrNoRows),
+					store.EXPECT().
+						GetIdempotencyKey(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(stored, nil),
+				)
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrIdempotencyKeyExists)
+				stubRecipient(store)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var replayed api.TransferTxResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &replayed)
+				require.NoError(t, err)
+				require.Equal(t, result.Transfer, replayed.Transfer.Transfer)
+				require.Equal(t, result.FromAccount, replayed.FromAccount.Account)
+			},
+		},
+	}
+
+	tokenMaker, err := token.NewPasetoMaker(config.SecretKey)
+	require.NoError(t, err)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := newMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := api.NewServer(config, store)
+			require.NoError(t, err)
+
+			accessToken, _, err := tokenMaker.GenerateToken(fromAccount.Owner, utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			jsonData, err := json.Marshal(tc.params)
+			require.NoError(t, err)
+			request := httptest.NewRequest(http.MethodPost, "/transfer", bytes.NewReader(jsonData))
+			request.Header.Set("Content-Type", "application/json")
+			request.Header.Set(api.IdempotencyKeyHeader, idempotencyKey)
+			request.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, accessToken))
+
+			server.Router().ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func hashTransferRequest(t *testing.T, request transferRequest) string {
+	data, err := json.Marshal(request)
+	require.NoError(t, err)
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestListTransfers(t *testing.T) {
+	username := utils.RandomOwner()
+
+	testCases := []struct {
+		name          string
+		query         url.Values
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			query: url.Values{
+				"account_id": {"7"},
+				"direction":  {"out"},
+				"min_amount": {"10"},
+				"max_amount": {"100"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListOwnerTransfers(gomock.Any(), gomock.Eq(db.ListOwnerTransfersParams{
+						Direction: "out",
+						Owner:     username,
+						AccountID: pgtype.Int4{Int32: 7, Valid: true},
+						MinAmount: pgtype.Int8{Int64: 10, Valid: true},
+						MaxAmount: pgtype.Int8{Int64: 100, Valid: true},
+						Limit:     20,
+						Offset:    0,
+					})).
+					Times(1).
+					Return([]db.Transfer{{ID: 1, FromAccountID: 7, ToAccountID: 8, Amount: 50}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var transfers []db.Transfer
+				err := json.Unmarshal(recorder.Body.Bytes(), &transfers)
+				require.NoError(t, err)
+				require.Len(t, transfers, 1)
+			},
+		},
+		{
+			name:  "by reference",
+			query: url.Values{"reference": {"INV-42"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListOwnerTransfers(gomock.Any(), gomock.Eq(db.ListOwnerTransfersParams{
+						Owner:     username,
+						Reference: pgtype.Text{String: "INV-42", Valid: true},
+						Limit:     20,
+						Offset:    0,
+					})).
+					Times(1).
+					Return([]db.Transfer{{ID: 1, FromAccountID: 7, ToAccountID: 8, Amount: 50, Reference: "INV-42"}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var transfers []db.Transfer
+				err := json.Unmarshal(recorder.Body.Bytes(), &transfers)
+				require.NoError(t, err)
+				require.Len(t, transfers, 1)
+				require.Equal(t, "INV-42", transfers[0].Reference)
+			},
+		},
+		{
+			name:  "invalid direction",
+			query: url.Values{"direction": {"sideways"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListOwnerTransfers(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "invalid amount range",
+			query: url.Values{
+				"min_amount": {"100"},
+				"max_amount": {"10"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListOwnerTransfers(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "internal error",
+			query: url.Values{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListOwnerTransfers(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrTxClosed)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	tokenMaker, err := token.NewPasetoMaker(config.SecretKey)
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := newMockStore(ctrl)
+
+	server, err := api.NewServer(config, store)
+	require.NoError(t, err)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.buildStubs(store)
+
+			accessToken, _, err := tokenMaker.GenerateToken(username, utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/transfers?"+tc.query.Encode(), nil)
+			request.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, accessToken))
+
+			server.Router().ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetTransfer(t *testing.T) {
+	fromAccount := createRandomAccount("USD")
+	toAccount := createRandomAccount("USD")
+	transfer := db.Transfer{
+		ID:            int32(utils.RandomInt(1, 1000)),
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        10,
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "sender",
+			username: fromAccount.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var gotTransfer db.Transfer
+				err := json.Unmarshal(recorder.Body.Bytes(), &gotTransfer)
+				require.NoError(t, err)
+				require.Equal(t, transfer, gotTransfer)
+			},
+		},
+		{
+			name:     "recipient",
+			username: toAccount.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "transfer of other customers",
+			username: utils.RandomOwner(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "not found",
+			username: fromAccount.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(db.Transfer{}, pgx.ErrNoRows)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	tokenMaker, err := token.NewPasetoMaker(config.SecretKey)
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := newMockStore(ctrl)
+
+	server, err := api.NewServer(config, store)
+	require.NoError(t, err)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.buildStubs(store)
+
+			accessToken, _, err := tokenMaker.GenerateToken(tc.username, utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/transfers/%d", transfer.ID), nil)
+			request.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, accessToken))
+
+			server.Router().ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
 type transferRequest struct {
-	FromAccountID int32 `json:"from_account_id"`
-	ToAccountID   int32 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int32  `json:"from_account_id"`
+	ToAccountID   int32  `json:"to_account_id"`
+	Amount        int64  `json:"amount,omitempty"`
+	AmountDecimal string `json:"amount_decimal,omitempty"`
+	Description   string `json:"description,omitempty"`
+	Reference     string `json:"reference,omitempty"`
+	QuoteID       string `json:"quote_id,omitempty"`
 }
@@ -2,14 +2,19 @@ package api
 
 import (
 	"errors"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mohammad19khodaei/simple_bank/api/middlewares"
 	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/token"
 	"github.com/mohammad19khodaei/simple_bank/utils"
 )
 
@@ -24,6 +29,8 @@ type UserResponse struct {
 	Username          string             `json:"username"`
 	FullName          string             `json:"full_name"`
 	Email             string             `json:"email"`
+	Role              string             `json:"role"`
+	IsFrozen          bool               `json:"is_frozen"`
 	PasswordChangedAt pgtype.Timestamptz `json:"password_changed_at"`
 	CreatedAt         pgtype.Timestamptz `json:"created_at"`
 }
@@ -70,8 +77,12 @@ type loginRequest struct {
 }
 
 type LoginResponse struct {
-	AccessToken string       `json:"access_token"`
-	User        UserResponse `json:"user"`
+	SessionID             string       `json:"session_id"`
+	AccessToken           string       `json:"access_token"`
+	AccessTokenExpiresAt  time.Time    `json:"access_token_expires_at"`
+	RefreshToken          string       `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time    `json:"refresh_token_expires_at"`
+	User                  UserResponse `json:"user"`
 }
 
 func (s *server) login(ctx *gin.Context) {
@@ -101,23 +112,192 @@ func (s *server) login(ctx *gin.Context) {
 		return
 	}
 
-	token, err := s.tokenMaker.GenerateToken(user.Username, s.config.TokenDuration)
+	if user.IsFrozen {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": "user is frozen",
+		})
+		return
+	}
+
+	accessToken, accessPayload, err := s.tokenMaker.GenerateToken(user.Username, user.Role, s.config.TokenDuration, token.TokenTypeAccess)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	refreshToken, refreshPayload, err := s.tokenMaker.GenerateToken(user.Username, user.Role, s.config.RefreshTokenDuration, token.TokenTypeRefresh)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	sessionID, err := uuid.Parse(refreshPayload.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	session, err := s.store.CreateSession(ctx, db.CreateSessionParams{
+		ID:           pgtype.UUID{Bytes: sessionID, Valid: true},
+		Username:     user.Username,
+		RefreshToken: refreshToken,
+		UserAgent:    ctx.Request.UserAgent(),
+		ClientIp:     ctx.ClientIP(),
+		IsBlocked:    false,
+		ExpiresAt:    pgtype.Timestamptz{Time: refreshPayload.ExpiresAt.Time, Valid: true},
+	})
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
 		return
 	}
 
 	ctx.JSON(http.StatusOK, LoginResponse{
-		AccessToken: token,
-		User:        createUserResponse(user),
+		SessionID:             uuid.UUID(session.ID.Bytes).String(),
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessPayload.ExpiresAt.Time,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshPayload.ExpiresAt.Time,
+		User:                  createUserResponse(user),
 	})
 }
 
+type logoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (s *server) logoutHandler(ctx *gin.Context) {
+	payload := ctx.MustGet(middlewares.AuthPayloadKey).(*token.Payload)
+
+	var request logoutRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	if request.RefreshToken != "" {
+		refreshPayload, err := s.tokenMaker.VerifyToken(request.RefreshToken)
+		if err == nil && refreshPayload.Type != token.TokenTypeRefresh {
+			err = token.ErrInvalidToken
+		}
+		if err != nil {
+			ctx.JSON(http.StatusUnauthorized, s.errorResponse(err))
+			return
+		}
+
+		if refreshPayload.Username != payload.Username {
+			ctx.JSON(http.StatusForbidden, s.errorResponse(errors.New("forbidden: refresh token does not belong to you")))
+			return
+		}
+
+		sessionID, err := uuid.Parse(refreshPayload.ID)
+		if err != nil {
+			ctx.JSON(http.StatusUnauthorized, s.errorResponse(err))
+			return
+		}
+
+		if err := s.store.BlockSession(ctx, pgtype.UUID{Bytes: sessionID, Valid: true}); err != nil {
+			ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+			return
+		}
+
+		if err := s.revocationStore.Revoke(ctx, refreshPayload); err != nil {
+			ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+			return
+		}
+	}
+
+	if err := s.revocationStore.Revoke(ctx, payload); err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (s *server) logoutAllHandler(ctx *gin.Context) {
+	username := ctx.MustGet(middlewares.AuthUsernameKey).(string)
+
+	if err := s.revokeUserSessions(ctx, username, time.Now()); err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// revokeUserSessions blocks every refresh session of username and rejects all
+// of their access tokens issued before the given time.
+func (s *server) revokeUserSessions(ctx *gin.Context, username string, before time.Time) error {
+	if err := s.store.BlockUserSessions(ctx, username); err != nil {
+		return err
+	}
+
+	return s.revocationStore.RevokeUser(ctx, username, before)
+}
+
+type changePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
+
+func (s *server) changePasswordHandler(ctx *gin.Context) {
+	username := ctx.MustGet(middlewares.AuthUsernameKey).(string)
+
+	var request changePasswordRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, s.errorResponse(err))
+		return
+	}
+
+	user, err := s.store.GetUser(ctx, username)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, s.errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	if !utils.IsHashPasswordValid(user.HashedPassword, request.OldPassword) {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "old password is incorrect",
+		})
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(request.NewPassword)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	user, err = s.store.UpdateUserPassword(ctx, db.UpdateUserPasswordParams{
+		HashedPassword:    hashedPassword,
+		PasswordChangedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		Username:          username,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	// tokens issued with the old password must not outlive the change
+	if err := s.revokeUserSessions(ctx, username, user.PasswordChangedAt.Time); err != nil {
+		ctx.JSON(http.StatusInternalServerError, s.errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, createUserResponse(user))
+}
+
 func createUserResponse(user db.User) UserResponse {
 	return UserResponse{
 		Username:          user.Username,
 		FullName:          user.FullName,
 		Email:             user.Email,
+		Role:              user.Role,
+		IsFrozen:          user.IsFrozen,
 		PasswordChangedAt: user.PasswordChangedAt,
 		CreatedAt:         user.CreatedAt,
 	}
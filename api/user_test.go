@@ -4,15 +4,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/mohammad19khodaei/simple_bank/api"
+	"github.com/mohammad19khodaei/simple_bank/api/middlewares"
 	mockdb "github.com/mohammad19khodaei/simple_bank/db/mock"
 	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/token"
 	"github.com/mohammad19khodaei/simple_bank/utils"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
@@ -98,7 +102,7 @@ func TestCreateUser(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
-		store := mockdb.NewMockStore(ctrl)
+		store := newMockStore(ctrl)
 		tc.buildStubs(store, tc.params)
 		server, err := api.NewServer(config, store)
 		require.NoError(t, err)
@@ -123,6 +127,8 @@ type createUserParams struct {
 func TestLogin(t *testing.T) {
 	password := utils.RandomString(6)
 	user := createRandomUser(password)
+	frozenUser := createRandomUser(password)
+	frozenUser.IsFrozen = true
 	testCases := []struct {
 		name          string
 		params        loginParams
@@ -161,6 +167,26 @@ func TestLogin(t *testing.T) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
+		{
+			name: "user is frozen",
+			params: loginParams{
+				Username: frozenUser.Username,
+				Password: password,
+			},
+			buildStubs: func(store *mockdb.MockStore, params loginParams) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(params.Username)).
+					Times(1).
+					Return(frozenUser, nil)
+
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, _ loginParams) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
 		{
 			name: "ok",
 			params: loginParams{
@@ -172,6 +198,25 @@ func TestLogin(t *testing.T) {
 					GetUser(gomock.Any(), gomock.Eq(params.Username)).
 					Times(1).
 					Return(user, nil)
+
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateSessionParams) (db.Session, error) {
+						require.Equal(t, params.Username, arg.Username)
+						require.NotEmpty(t, arg.RefreshToken)
+						require.False(t, arg.IsBlocked)
+
+						return db.Session{
+							ID:           arg.ID,
+							Username:     arg.Username,
+							RefreshToken: arg.RefreshToken,
+							UserAgent:    arg.UserAgent,
+							ClientIp:     arg.ClientIp,
+							IsBlocked:    arg.IsBlocked,
+							ExpiresAt:    arg.ExpiresAt,
+						}, nil
+					})
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, params loginParams) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -180,7 +225,10 @@ func TestLogin(t *testing.T) {
 				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
 				require.NoError(t, err)
 
+				require.NotEmpty(t, resp.SessionID)
 				require.NotEmpty(t, resp.AccessToken)
+				require.NotEmpty(t, resp.RefreshToken)
+				require.True(t, resp.RefreshTokenExpiresAt.After(resp.AccessTokenExpiresAt))
 				require.Equal(t, params.Username, resp.User.Username)
 			},
 		},
@@ -189,7 +237,7 @@ func TestLogin(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	store := mockdb.NewMockStore(ctrl)
+	store := newMockStore(ctrl)
 
 	server, err := api.NewServer(config, store)
 	require.NoError(t, err)
@@ -213,3 +261,240 @@ type loginParams struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 }
+
+func TestLogout(t *testing.T) {
+	tokenMaker, err := token.NewPasetoMaker(config.SecretKey)
+	require.NoError(t, err)
+
+	username := utils.RandomOwner()
+
+	testCases := []struct {
+		name          string
+		params        func(t *testing.T) logoutParams
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "refresh token of another user",
+			params: func(t *testing.T) logoutParams {
+				refreshToken, _, err := tokenMaker.GenerateToken(utils.RandomOwner(), utils.RoleCustomer, config.RefreshTokenDuration, token.TokenTypeRefresh)
+				require.NoError(t, err)
+				return logoutParams{RefreshToken: refreshToken}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					BlockSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "without refresh token",
+			params: func(t *testing.T) logoutParams {
+				return logoutParams{}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					BlockSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name: "with refresh token",
+			params: func(t *testing.T) logoutParams {
+				refreshToken, _, err := tokenMaker.GenerateToken(username, utils.RoleCustomer, config.RefreshTokenDuration, token.TokenTypeRefresh)
+				require.NoError(t, err)
+				return logoutParams{RefreshToken: refreshToken}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					BlockSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := newMockStore(ctrl)
+
+	server, err := api.NewServer(config, store)
+	require.NoError(t, err)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.buildStubs(store)
+
+			accessToken, _, err := tokenMaker.GenerateToken(username, utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			jsonData, err := json.Marshal(tc.params(t))
+			require.NoError(t, err)
+			request := httptest.NewRequest(http.MethodPost, "/users/logout", bytes.NewReader(jsonData))
+			request.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, accessToken))
+
+			server.Router().ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+
+			if recorder.Code != http.StatusNoContent {
+				return
+			}
+
+			// the access token must be rejected once the user logged out
+			recorder = httptest.NewRecorder()
+			request = httptest.NewRequest(http.MethodPost, "/users/logout", nil)
+			request.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, accessToken))
+
+			server.Router().ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusUnauthorized, recorder.Code)
+		})
+	}
+}
+
+type logoutParams struct {
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+func TestChangePassword(t *testing.T) {
+	tokenMaker, err := token.NewPasetoMaker(config.SecretKey)
+	require.NoError(t, err)
+
+	password := utils.RandomString(6)
+	user := createRandomUser(password)
+
+	testCases := []struct {
+		name          string
+		params        changePasswordParams
+		buildStubs    func(store *mockdb.MockStore, params changePasswordParams)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "new password is too short",
+			params: changePasswordParams{
+				OldPassword: password,
+				NewPassword: utils.RandomString(3),
+			},
+			buildStubs: func(store *mockdb.MockStore, _ changePasswordParams) {
+				store.EXPECT().
+					UpdateUserPassword(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "old password is wrong",
+			params: changePasswordParams{
+				OldPassword: utils.RandomString(6),
+				NewPassword: utils.RandomString(6),
+			},
+			buildStubs: func(store *mockdb.MockStore, _ changePasswordParams) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+
+				store.EXPECT().
+					UpdateUserPassword(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ok",
+			params: changePasswordParams{
+				OldPassword: password,
+				NewPassword: utils.RandomString(6),
+			},
+			buildStubs: func(store *mockdb.MockStore, params changePasswordParams) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+
+				store.EXPECT().
+					UpdateUserPassword(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UpdateUserPasswordParams) (db.User, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.True(t, utils.IsHashPasswordValid(arg.HashedPassword, params.NewPassword))
+
+						updatedUser := user
+						updatedUser.HashedPassword = arg.HashedPassword
+						updatedUser.PasswordChangedAt = pgtype.Timestamptz{Time: time.Now().Add(time.Second), Valid: true}
+						return updatedUser, nil
+					})
+
+				store.EXPECT().
+					BlockUserSessions(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp api.UserResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				require.NoError(t, err)
+				require.Equal(t, user.Username, resp.Username)
+			},
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := newMockStore(ctrl)
+
+	server, err := api.NewServer(config, store)
+	require.NoError(t, err)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.buildStubs(store, tc.params)
+
+			accessToken, _, err := tokenMaker.GenerateToken(user.Username, utils.RoleCustomer, config.TokenDuration, token.TokenTypeAccess)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			jsonData, err := json.Marshal(tc.params)
+			require.NoError(t, err)
+			request := httptest.NewRequest(http.MethodPatch, "/users/me/password", bytes.NewReader(jsonData))
+			request.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, accessToken))
+
+			server.Router().ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+
+			if recorder.Code != http.StatusOK {
+				return
+			}
+
+			// tokens issued before the password change must be rejected
+			recorder = httptest.NewRecorder()
+			request = httptest.NewRequest(http.MethodPatch, "/users/me/password", bytes.NewReader(jsonData))
+			request.Header.Set("Authorization", fmt.Sprintf("%s %s", middlewares.AuthorizationTypeBearer, accessToken))
+
+			server.Router().ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusUnauthorized, recorder.Code)
+		})
+	}
+}
+
+type changePasswordParams struct {
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password"`
+}
@@ -0,0 +1,17 @@
+package validators
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+)
+
+// AccountNumberValidator accepts account numbers with valid check digits, in
+// their compact or printed form.
+var AccountNumberValidator validator.Func = func(fl validator.FieldLevel) bool {
+	number, ok := fl.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+
+	return utils.IsValidAccountNumber(utils.NormalizeAccountNumber(number))
+}
@@ -0,0 +1,17 @@
+package validators
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+)
+
+// CronValidator accepts the cron expressions utils.ParseCron understands.
+var CronValidator validator.Func = func(fl validator.FieldLevel) bool {
+	expr, ok := fl.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+
+	_, err := utils.ParseCron(expr)
+	return err == nil
+}
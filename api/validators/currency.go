@@ -2,14 +2,17 @@ package validators
 
 import (
 	"github.com/go-playground/validator/v10"
-	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/mohammad19khodaei/simple_bank/currency"
 )
 
-var CurrencyValidator validator.Func = func(fl validator.FieldLevel) bool {
-	inputCurrency, ok := fl.Field().Interface().(string)
-	if !ok {
-		return false
-	}
+// CurrencyValidator accepts the codes of the enabled currencies of registry.
+func CurrencyValidator(registry *currency.Registry) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		inputCurrency, ok := fl.Field().Interface().(string)
+		if !ok {
+			return false
+		}
 
-	return utils.IsValidCurrency(inputCurrency)
+		return registry.IsSupported(inputCurrency)
+	}
 }
@@ -0,0 +1,98 @@
+package currency
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var ErrInvalidCurrency = errors.New("invalid currency")
+
+// Currency describes an ISO 4217 currency. Amounts of a currency are stored
+// as integers in its minor unit, e.g. cents for USD.
+type Currency struct {
+	Code        string `json:"code"`
+	NumericCode int    `json:"numeric_code"`
+	MinorUnits  int    `json:"minor_units"`
+	Symbol      string `json:"symbol"`
+	Name        string `json:"name"`
+	Enabled     bool   `json:"enabled"`
+}
+
+// Defaults are the currencies the bank shipped with, they back the registry
+// when no other source is configured.
+func Defaults() []Currency {
+	return []Currency{
+		{Code: "EUR", NumericCode: 978, MinorUnits: 2, Symbol: "€", Name: "Euro", Enabled: true},
+		{Code: "IRR", NumericCode: 364, MinorUnits: 2, Symbol: "﷼", Name: "Iranian Rial", Enabled: true},
+		{Code: "USD", NumericCode: 840, MinorUnits: 2, Symbol: "$", Name: "US Dollar", Enabled: true},
+	}
+}
+
+// Registry is an in-memory snapshot of the known currencies. It is loaded
+// once at startup, a currency added later is only picked up on restart.
+type Registry struct {
+	currencies map[string]Currency
+	codes      []string
+}
+
+func NewRegistry(currencies []Currency) (*Registry, error) {
+	registry := &Registry{
+		currencies: make(map[string]Currency, len(currencies)),
+	}
+
+	for _, currency := range currencies {
+		if err := currency.validate(); err != nil {
+			return nil, err
+		}
+		if _, ok := registry.currencies[currency.Code]; ok {
+			return nil, fmt.Errorf("%w: %s is registered twice", ErrInvalidCurrency, currency.Code)
+		}
+
+		registry.currencies[currency.Code] = currency
+		registry.codes = append(registry.codes, currency.Code)
+	}
+	sort.Strings(registry.codes)
+
+	return registry, nil
+}
+
+// Get returns the currency with the given code, disabled ones included.
+func (r *Registry) Get(code string) (Currency, bool) {
+	currency, ok := r.currencies[code]
+	return currency, ok
+}
+
+// IsSupported reports whether new accounts and transfers may use the code.
+func (r *Registry) IsSupported(code string) bool {
+	currency, ok := r.currencies[code]
+	return ok && currency.Enabled
+}
+
+// List returns every registered currency ordered by code.
+func (r *Registry) List() []Currency {
+	currencies := make([]Currency, 0, len(r.codes))
+	for _, code := range r.codes {
+		currencies = append(currencies, r.currencies[code])
+	}
+	return currencies
+}
+
+func (c Currency) validate() error {
+	if len(c.Code) != 3 {
+		return fmt.Errorf("%w: code %q must have 3 letters", ErrInvalidCurrency, c.Code)
+	}
+	for _, r := range c.Code {
+		if r < 'A' || r > 'Z' {
+			return fmt.Errorf("%w: code %q must be upper case letters", ErrInvalidCurrency, c.Code)
+		}
+	}
+	if c.NumericCode <= 0 || c.NumericCode > 999 {
+		return fmt.Errorf("%w: %s numeric code %d is out of range", ErrInvalidCurrency, c.Code, c.NumericCode)
+	}
+	// int64 amounts overflow long before 18 decimals matter
+	if c.MinorUnits < 0 || c.MinorUnits > 18 {
+		return fmt.Errorf("%w: %s minor units %d is out of range", ErrInvalidCurrency, c.Code, c.MinorUnits)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package currency_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mohammad19khodaei/simple_bank/currency"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	registry, err := currency.NewRegistry([]currency.Currency{
+		{Code: "USD", NumericCode: 840, MinorUnits: 2, Symbol: "$", Name: "US Dollar", Enabled: true},
+		{Code: "JPY", NumericCode: 392, MinorUnits: 0, Symbol: "¥", Name: "Yen", Enabled: false},
+	})
+	require.NoError(t, err)
+
+	usd, ok := registry.Get("USD")
+	require.True(t, ok)
+	require.Equal(t, 2, usd.MinorUnits)
+	require.True(t, registry.IsSupported("USD"))
+
+	// disabled currencies are known but not supported
+	_, ok = registry.Get("JPY")
+	require.True(t, ok)
+	require.False(t, registry.IsSupported("JPY"))
+
+	_, ok = registry.Get("GBP")
+	require.False(t, ok)
+	require.False(t, registry.IsSupported("GBP"))
+
+	list := registry.List()
+	require.Len(t, list, 2)
+	require.Equal(t, "JPY", list[0].Code)
+	require.Equal(t, "USD", list[1].Code)
+}
+
+func TestNewRegistryInvalid(t *testing.T) {
+	testCases := []struct {
+		name     string
+		currency currency.Currency
+	}{
+		{
+			name:     "lower case code",
+			currency: currency.Currency{Code: "usd", NumericCode: 840, MinorUnits: 2},
+		},
+		{
+			name:     "long code",
+			currency: currency.Currency{Code: "USDT", NumericCode: 840, MinorUnits: 2},
+		},
+		{
+			name:     "numeric code out of range",
+			currency: currency.Currency{Code: "USD", NumericCode: 1000, MinorUnits: 2},
+		},
+		{
+			name:     "negative minor units",
+			currency: currency.Currency{Code: "USD", NumericCode: 840, MinorUnits: -1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := currency.NewRegistry([]currency.Currency{tc.currency})
+			require.ErrorIs(t, err, currency.ErrInvalidCurrency)
+		})
+	}
+
+	_, err := currency.NewRegistry([]currency.Currency{currency.Defaults()[0], currency.Defaults()[0]})
+	require.ErrorIs(t, err, currency.ErrInvalidCurrency)
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "currencies.json")
+	err := os.WriteFile(path, []byte(`[{"code":"GBP","numeric_code":826,"minor_units":2,"symbol":"£","name":"Pound Sterling","enabled":true}]`), 0o600)
+	require.NoError(t, err)
+
+	registry, err := currency.LoadFile(path)
+	require.NoError(t, err)
+	require.True(t, registry.IsSupported("GBP"))
+	require.False(t, registry.IsSupported("USD"))
+}
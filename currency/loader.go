@@ -0,0 +1,67 @@
+package currency
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+)
+
+// LoadFile builds a registry from a JSON array of currencies.
+func LoadFile(path string) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var currencies []Currency
+	if err := json.Unmarshal(data, &currencies); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+
+	return NewRegistry(currencies)
+}
+
+// LoadPostgres builds a registry from the currencies table.
+func LoadPostgres(ctx context.Context, querier db.Querier) (*Registry, error) {
+	rows, err := querier.ListCurrencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	currencies := make([]Currency, 0, len(rows))
+	for _, row := range rows {
+		currencies = append(currencies, Currency{
+			Code:        row.Code,
+			NumericCode: int(row.NumericCode),
+			MinorUnits:  int(row.MinorUnits),
+			Symbol:      row.Symbol,
+			Name:        row.Name,
+			Enabled:     row.Enabled,
+		})
+	}
+
+	return NewRegistry(currencies)
+}
+
+// CheckPostgres reports an error when the currencies table gives a currency of
+// the registry other minor units. Transfers between currencies are scaled by
+// the minor units of the table, so amounts would otherwise be converted
+// differently from how they are parsed and formatted.
+func CheckPostgres(ctx context.Context, querier db.Querier, registry *Registry) error {
+	rows, err := querier.ListCurrencies(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		currency, ok := registry.Get(row.Code)
+		if ok && currency.MinorUnits != int(row.MinorUnits) {
+			return fmt.Errorf("%w: %s has %d minor units but %d in the currencies table", ErrInvalidCurrency, row.Code, currency.MinorUnits, row.MinorUnits)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package currency
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// Money is an amount in the minor unit of its currency, 1250 USD is $12.50.
+type Money struct {
+	Amount   int64
+	Currency Currency
+}
+
+// ParseMoney reads a decimal string such as "12.50" in the major unit of the
+// currency. It rejects more decimals than the currency has minor units
+// instead of rounding them away.
+func ParseMoney(value string, currency Currency) (Money, error) {
+	digits := value
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign = "-"
+		digits = digits[1:]
+	}
+
+	whole, fraction, hasPoint := strings.Cut(digits, ".")
+	if whole == "" || (hasPoint && fraction == "") || !isDigits(whole) || !isDigits(fraction) {
+		return Money{}, fmt.Errorf("%w: %q is not a decimal number", ErrInvalidAmount, value)
+	}
+	if len(fraction) > currency.MinorUnits {
+		return Money{}, fmt.Errorf("%w: %s has at most %d decimals", ErrInvalidAmount, currency.Code, currency.MinorUnits)
+	}
+
+	fraction += strings.Repeat("0", currency.MinorUnits-len(fraction))
+	amount, err := strconv.ParseInt(sign+whole+fraction, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q is out of range", ErrInvalidAmount, value)
+	}
+
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// String renders the amount in the major unit with exactly as many decimals
+// as the currency has minor units, e.g. "12.50" or "-0.05".
+func (m Money) String() string {
+	digits := strconv.FormatInt(m.Amount, 10)
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign = "-"
+		digits = digits[1:]
+	}
+
+	units := m.Currency.MinorUnits
+	if units == 0 {
+		return sign + digits
+	}
+	if len(digits) <= units {
+		digits = strings.Repeat("0", units-len(digits)+1) + digits
+	}
+
+	point := len(digits) - units
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
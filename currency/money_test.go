@@ -0,0 +1,90 @@
+package currency_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mohammad19khodaei/simple_bank/currency"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	usd = currency.Currency{Code: "USD", NumericCode: 840, MinorUnits: 2, Symbol: "$", Enabled: true}
+	jpy = currency.Currency{Code: "JPY", NumericCode: 392, MinorUnits: 0, Symbol: "¥", Enabled: true}
+	bhd = currency.Currency{Code: "BHD", NumericCode: 48, MinorUnits: 3, Symbol: "BD", Enabled: true}
+)
+
+func TestParseMoney(t *testing.T) {
+	testCases := []struct {
+		value    string
+		currency currency.Currency
+		amount   int64
+	}{
+		{value: "12.50", currency: usd, amount: 1250},
+		{value: "12.5", currency: usd, amount: 1250},
+		{value: "12", currency: usd, amount: 1200},
+		{value: "0.01", currency: usd, amount: 1},
+		{value: "-3.07", currency: usd, amount: -307},
+		{value: "500", currency: jpy, amount: 500},
+		{value: "1.005", currency: bhd, amount: 1005},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.currency.Code+" "+tc.value, func(t *testing.T) {
+			money, err := currency.ParseMoney(tc.value, tc.currency)
+			require.NoError(t, err)
+			require.Equal(t, tc.amount, money.Amount)
+			require.Equal(t, tc.currency, money.Currency)
+		})
+	}
+}
+
+func TestParseMoneyInvalid(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		currency currency.Currency
+	}{
+		{name: "empty", value: "", currency: usd},
+		{name: "letters", value: "12a", currency: usd},
+		{name: "missing whole part", value: ".5", currency: usd},
+		{name: "missing decimals", value: "12.", currency: usd},
+		{name: "too many decimals", value: "12.505", currency: usd},
+		{name: "decimals without minor units", value: "12.5", currency: jpy},
+		{name: "exponent", value: "1e3", currency: usd},
+		{name: "overflow", value: "92233720368547758.08", currency: usd},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := currency.ParseMoney(tc.value, tc.currency)
+			require.ErrorIs(t, err, currency.ErrInvalidAmount)
+		})
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	testCases := []struct {
+		money    currency.Money
+		expected string
+	}{
+		{money: currency.Money{Amount: 1250, Currency: usd}, expected: "12.50"},
+		{money: currency.Money{Amount: 5, Currency: usd}, expected: "0.05"},
+		{money: currency.Money{Amount: 0, Currency: usd}, expected: "0.00"},
+		{money: currency.Money{Amount: -307, Currency: usd}, expected: "-3.07"},
+		{money: currency.Money{Amount: 500, Currency: jpy}, expected: "500"},
+		{money: currency.Money{Amount: 1005, Currency: bhd}, expected: "1.005"},
+		{money: currency.Money{Amount: math.MinInt64, Currency: usd}, expected: "-92233720368547758.08"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expected, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.money.String())
+
+			// rendering and parsing are symmetric
+			money, err := currency.ParseMoney(tc.expected, tc.money.Currency)
+			require.NoError(t, err)
+			require.Equal(t, tc.money, money)
+		})
+	}
+}
@@ -0,0 +1,13 @@
+CREATE TABLE revoked_tokens(
+    id uuid PRIMARY KEY,
+    username varchar NOT NULL,
+    expires_at timestamptz NOT NULL,
+    revoked_at timestamptz default now(),
+    FOREIGN KEY (username) REFERENCES users(username)
+);
+
+CREATE TABLE user_token_revocations(
+    username varchar PRIMARY KEY,
+    revoked_before timestamptz NOT NULL,
+    FOREIGN KEY (username) REFERENCES users(username)
+);
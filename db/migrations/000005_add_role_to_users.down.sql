@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS users DROP CONSTRAINT "users_role_check";
+
+ALTER TABLE IF EXISTS users DROP COLUMN role;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE IF EXISTS users DROP COLUMN is_frozen;
+
+ALTER TABLE IF EXISTS accounts DROP CONSTRAINT "accounts_status_check";
+
+ALTER TABLE IF EXISTS accounts DROP COLUMN status;
\ No newline at end of file
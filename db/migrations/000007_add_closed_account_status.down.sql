@@ -0,0 +1,5 @@
+UPDATE accounts SET status = 'frozen' WHERE status = 'closed';
+
+ALTER TABLE IF EXISTS accounts DROP CONSTRAINT "accounts_status_check";
+
+ALTER TABLE IF EXISTS accounts ADD CONSTRAINT "accounts_status_check" CHECK (status IN ('active', 'frozen'));
@@ -0,0 +1,9 @@
+CREATE TABLE idempotency_keys(
+    username varchar NOT NULL,
+    key varchar NOT NULL,
+    request_hash varchar NOT NULL,
+    response jsonb NOT NULL,
+    created_at timestamptz default now(),
+    PRIMARY KEY (username, key),
+    FOREIGN KEY (username) REFERENCES users(username)
+);
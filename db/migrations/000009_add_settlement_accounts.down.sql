@@ -0,0 +1,15 @@
+DELETE FROM entries WHERE account_id IN (SELECT id FROM accounts WHERE owner = 'system');
+
+DELETE FROM transfers
+WHERE from_account_id IN (SELECT id FROM accounts WHERE owner = 'system')
+   OR to_account_id IN (SELECT id FROM accounts WHERE owner = 'system');
+
+DELETE FROM accounts WHERE owner = 'system';
+
+DELETE FROM users WHERE username = 'system';
+
+DROP INDEX IF EXISTS "accounts_settlement_currency_key";
+
+ALTER TABLE IF EXISTS accounts DROP CONSTRAINT "accounts_kind_check";
+
+ALTER TABLE IF EXISTS accounts DROP COLUMN kind;
@@ -0,0 +1,14 @@
+ALTER TABLE accounts ADD COLUMN kind varchar NOT NULL DEFAULT 'customer';
+
+ALTER TABLE accounts ADD CONSTRAINT "accounts_kind_check" CHECK (kind IN ('customer', 'settlement'));
+
+CREATE UNIQUE INDEX "accounts_settlement_currency_key" ON accounts (currency) WHERE kind = 'settlement';
+
+-- the system user owns the settlement accounts, it can never log in
+INSERT INTO users (username, hashed_password, full_name, email, is_frozen)
+VALUES ('system', '', 'Simple Bank', 'system@simplebank.local', true);
+
+INSERT INTO accounts (owner, balance, currency, kind)
+VALUES ('system', 0, 'USD', 'settlement'),
+       ('system', 0, 'EUR', 'settlement'),
+       ('system', 0, 'IRR', 'settlement');
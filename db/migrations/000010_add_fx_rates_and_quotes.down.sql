@@ -0,0 +1,5 @@
+ALTER TABLE IF EXISTS transfers DROP COLUMN exchange_rate;
+
+DROP TABLE IF EXISTS fx_quotes;
+
+DROP TABLE IF EXISTS fx_rates;
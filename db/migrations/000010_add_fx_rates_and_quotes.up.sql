@@ -0,0 +1,23 @@
+CREATE TABLE fx_rates(
+    base_currency varchar NOT NULL,
+    quote_currency varchar NOT NULL,
+    rate numeric NOT NULL CHECK (rate > 0),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (base_currency, quote_currency)
+);
+
+CREATE TABLE fx_quotes(
+    id uuid PRIMARY KEY,
+    username varchar NOT NULL,
+    from_currency varchar NOT NULL,
+    to_currency varchar NOT NULL,
+    rate numeric NOT NULL CHECK (rate > 0),
+    expires_at timestamptz NOT NULL,
+    used_at timestamptz,
+    created_at timestamptz default now(),
+    FOREIGN KEY (username) REFERENCES users(username)
+);
+
+ALTER TABLE transfers ADD COLUMN exchange_rate numeric;
+
+COMMENT ON COLUMN transfers.exchange_rate IS 'set on cross-currency transfers, the to account is credited amount * exchange_rate';
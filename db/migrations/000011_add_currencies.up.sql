@@ -0,0 +1,17 @@
+CREATE TABLE currencies(
+    code varchar PRIMARY KEY,
+    numeric_code integer NOT NULL UNIQUE,
+    minor_units integer NOT NULL CHECK (minor_units >= 0),
+    symbol varchar NOT NULL,
+    name varchar NOT NULL,
+    enabled boolean NOT NULL DEFAULT true
+);
+
+COMMENT ON COLUMN currencies.minor_units IS 'ISO 4217 exponent, amounts are stored in 10^-minor_units of the currency';
+
+INSERT INTO currencies (code, numeric_code, minor_units, symbol, name)
+VALUES ('USD', 840, 2, '$', 'US Dollar'),
+       ('EUR', 978, 2, '€', 'Euro'),
+       ('IRR', 364, 2, '﷼', 'Iranian Rial');
+
+ALTER TABLE accounts ADD FOREIGN KEY (currency) REFERENCES currencies(code);
@@ -0,0 +1,12 @@
+ALTER TABLE transfers ADD COLUMN currency varchar;
+
+UPDATE transfers
+SET currency = accounts.currency
+FROM accounts
+WHERE accounts.id = transfers.from_account_id;
+
+ALTER TABLE transfers ALTER COLUMN currency SET NOT NULL;
+
+ALTER TABLE transfers ADD FOREIGN KEY (currency) REFERENCES currencies(code);
+
+COMMENT ON COLUMN transfers.currency IS 'currency of amount, the one of the from account';
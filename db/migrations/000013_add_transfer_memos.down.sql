@@ -0,0 +1,5 @@
+ALTER TABLE IF EXISTS entries DROP COLUMN transfer_id;
+
+ALTER TABLE IF EXISTS transfers DROP COLUMN reference;
+
+ALTER TABLE IF EXISTS transfers DROP COLUMN description;
@@ -0,0 +1,13 @@
+ALTER TABLE transfers ADD COLUMN description varchar NOT NULL DEFAULT '';
+
+ALTER TABLE transfers ADD COLUMN reference varchar NOT NULL DEFAULT '';
+
+CREATE INDEX ON transfers (reference) WHERE reference <> '';
+
+COMMENT ON COLUMN transfers.reference IS 'supplied by the client to match the transfer with its own records';
+
+ALTER TABLE entries ADD COLUMN transfer_id int REFERENCES transfers(id);
+
+CREATE INDEX ON entries (transfer_id);
+
+COMMENT ON COLUMN entries.transfer_id IS 'the transfer that posted the entry, null on entries older than the column';
@@ -0,0 +1,16 @@
+ALTER TABLE accounts ADD COLUMN number varchar;
+
+-- existing accounts get a random number with valid check digits, the same
+-- shape utils.NewAccountNumber generates: 'SB', check digits, 12 digits
+UPDATE accounts
+SET number = 'SB' || lpad((98 - (generated.bban || '281100')::numeric % 97)::int::text, 2, '0') || generated.bban
+FROM (
+    SELECT id, lpad(floor(random() * 1e12)::bigint::text, 12, '0') AS bban FROM accounts
+) AS generated
+WHERE generated.id = accounts.id;
+
+ALTER TABLE accounts ALTER COLUMN number SET NOT NULL;
+
+ALTER TABLE accounts ADD CONSTRAINT "accounts_number_key" UNIQUE (number);
+
+COMMENT ON COLUMN accounts.number IS 'public account number, clients address accounts by it instead of id';
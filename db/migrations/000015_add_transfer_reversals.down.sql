@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS transfers DROP COLUMN reversed_amount;
+
+ALTER TABLE IF EXISTS transfers DROP COLUMN reversal_of;
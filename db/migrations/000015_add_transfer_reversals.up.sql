@@ -0,0 +1,11 @@
+ALTER TABLE transfers ADD COLUMN reversal_of int REFERENCES transfers(id);
+
+ALTER TABLE transfers ADD COLUMN reversed_amount bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE transfers ADD CONSTRAINT transfers_reversed_amount_check CHECK (reversed_amount >= 0 AND reversed_amount <= amount);
+
+CREATE INDEX ON transfers (reversal_of);
+
+COMMENT ON COLUMN transfers.reversal_of IS 'set on reversals, the transfer they give the money back for';
+
+COMMENT ON COLUMN transfers.reversed_amount IS 'sum of the reversals of the transfer, in its currency';
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS holds;
+
+ALTER TABLE IF EXISTS accounts DROP COLUMN available_balance;
+
+ALTER TABLE IF EXISTS accounts DROP COLUMN held_amount;
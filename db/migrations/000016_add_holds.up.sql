@@ -0,0 +1,34 @@
+ALTER TABLE accounts ADD COLUMN held_amount bigint NOT NULL DEFAULT 0 CHECK (held_amount >= 0);
+
+ALTER TABLE accounts ADD COLUMN available_balance bigint NOT NULL GENERATED ALWAYS AS (balance - held_amount) STORED;
+
+COMMENT ON COLUMN accounts.held_amount IS 'sum of the pending holds on the account';
+
+COMMENT ON COLUMN accounts.available_balance IS 'balance minus the pending holds, what transfers can spend';
+
+CREATE TABLE holds(
+    id serial PRIMARY KEY,
+    account_id int NOT NULL,
+    to_account_id int NOT NULL,
+    amount bigint NOT NULL CHECK (amount > 0),
+    currency varchar NOT NULL,
+    status varchar NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'captured', 'voided', 'expired')),
+    captured_amount bigint NOT NULL DEFAULT 0 CHECK (captured_amount >= 0 AND captured_amount <= amount),
+    transfer_id int,
+    description varchar NOT NULL DEFAULT '',
+    reference varchar NOT NULL DEFAULT '',
+    expires_at timestamptz NOT NULL,
+    created_at timestamptz default now(),
+    FOREIGN KEY (account_id) REFERENCES accounts(id),
+    FOREIGN KEY (to_account_id) REFERENCES accounts(id),
+    FOREIGN KEY (currency) REFERENCES currencies(code),
+    FOREIGN KEY (transfer_id) REFERENCES transfers(id)
+);
+
+CREATE INDEX ON holds (account_id);
+CREATE INDEX ON holds (to_account_id);
+CREATE INDEX ON holds (expires_at) WHERE status = 'pending';
+
+COMMENT ON COLUMN holds.account_id IS 'the account the money is reserved on';
+COMMENT ON COLUMN holds.to_account_id IS 'the account credited when the hold is captured';
+COMMENT ON COLUMN holds.transfer_id IS 'the transfer that captured the hold';
@@ -0,0 +1,41 @@
+CREATE TABLE scheduled_transfers(
+    id serial PRIMARY KEY,
+    owner varchar NOT NULL,
+    from_account_id int NOT NULL,
+    to_account_id int NOT NULL,
+    amount bigint NOT NULL CHECK (amount > 0),
+    currency varchar NOT NULL,
+    description varchar NOT NULL DEFAULT '',
+    reference varchar NOT NULL DEFAULT '',
+    schedule varchar NOT NULL DEFAULT '',
+    next_run_at timestamptz,
+    status varchar NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'paused', 'cancelled', 'completed')),
+    created_at timestamptz default now(),
+    FOREIGN KEY (owner) REFERENCES users(username),
+    FOREIGN KEY (from_account_id) REFERENCES accounts(id),
+    FOREIGN KEY (to_account_id) REFERENCES accounts(id),
+    FOREIGN KEY (currency) REFERENCES currencies(code)
+);
+
+CREATE INDEX ON scheduled_transfers (owner);
+CREATE INDEX ON scheduled_transfers (next_run_at) WHERE status = 'active';
+
+COMMENT ON COLUMN scheduled_transfers.schedule IS 'cron expression of a recurring transfer, empty for a one-off transfer';
+COMMENT ON COLUMN scheduled_transfers.next_run_at IS 'null once a one-off transfer ran';
+
+CREATE TABLE scheduled_transfer_runs(
+    id serial PRIMARY KEY,
+    scheduled_transfer_id int NOT NULL,
+    scheduled_at timestamptz NOT NULL,
+    status varchar NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+    transfer_id int,
+    error varchar NOT NULL DEFAULT '',
+    created_at timestamptz default now(),
+    FOREIGN KEY (scheduled_transfer_id) REFERENCES scheduled_transfers(id),
+    FOREIGN KEY (transfer_id) REFERENCES transfers(id)
+);
+
+CREATE INDEX ON scheduled_transfer_runs (scheduled_transfer_id);
+
+COMMENT ON COLUMN scheduled_transfer_runs.scheduled_at IS 'the time the run was due';
+COMMENT ON COLUMN scheduled_transfer_runs.transfer_id IS 'the transfer made by a succeeded run';
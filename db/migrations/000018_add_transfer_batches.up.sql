@@ -0,0 +1,39 @@
+CREATE TABLE transfer_batches(
+    id serial PRIMARY KEY,
+    owner varchar NOT NULL,
+    from_account_id int NOT NULL,
+    currency varchar NOT NULL,
+    mode varchar NOT NULL CHECK (mode IN ('atomic', 'best_effort')),
+    status varchar NOT NULL CHECK (status IN ('completed', 'partially_completed', 'failed')),
+    item_count int NOT NULL,
+    succeeded_count int NOT NULL,
+    total_amount bigint NOT NULL,
+    created_at timestamptz default now(),
+    FOREIGN KEY (owner) REFERENCES users(username),
+    FOREIGN KEY (from_account_id) REFERENCES accounts(id),
+    FOREIGN KEY (currency) REFERENCES currencies(code)
+);
+
+CREATE INDEX ON transfer_batches (owner);
+
+COMMENT ON COLUMN transfer_batches.total_amount IS 'sum of the amounts of the succeeded items';
+
+CREATE TABLE transfer_batch_items(
+    id serial PRIMARY KEY,
+    batch_id int NOT NULL,
+    position int NOT NULL,
+    to_account_id int NOT NULL,
+    amount bigint NOT NULL CHECK (amount > 0),
+    description varchar NOT NULL DEFAULT '',
+    reference varchar NOT NULL DEFAULT '',
+    status varchar NOT NULL CHECK (status IN ('succeeded', 'failed')),
+    transfer_id int,
+    error varchar NOT NULL DEFAULT '',
+    FOREIGN KEY (batch_id) REFERENCES transfer_batches(id),
+    FOREIGN KEY (transfer_id) REFERENCES transfers(id),
+    UNIQUE (batch_id, position)
+);
+
+COMMENT ON COLUMN transfer_batch_items.position IS 'index of the item in the request, from 0';
+COMMENT ON COLUMN transfer_batch_items.to_account_id IS 'as requested, a failed item may name an account that does not exist';
+COMMENT ON COLUMN transfer_batch_items.transfer_id IS 'the transfer made by a succeeded item';
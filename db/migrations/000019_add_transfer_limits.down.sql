@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS entries_account_id_created_at_idx;
+
+DROP TABLE IF EXISTS transfer_limits;
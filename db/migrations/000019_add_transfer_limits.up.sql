@@ -0,0 +1,22 @@
+CREATE TABLE transfer_limits(
+    id serial PRIMARY KEY,
+    currency varchar NOT NULL,
+    owner varchar,
+    account_id int,
+    per_transaction bigint CHECK (per_transaction > 0),
+    daily bigint CHECK (daily > 0),
+    monthly bigint CHECK (monthly > 0),
+    created_at timestamptz default now(),
+    FOREIGN KEY (currency) REFERENCES currencies(code),
+    FOREIGN KEY (owner) REFERENCES users(username),
+    FOREIGN KEY (account_id) REFERENCES accounts(id),
+    CHECK (owner IS NULL OR account_id IS NULL),
+    UNIQUE NULLS NOT DISTINCT (currency, owner, account_id)
+);
+
+COMMENT ON TABLE transfer_limits IS 'outgoing limits of the currency when owner and account_id are null, of a user or of an account otherwise';
+COMMENT ON COLUMN transfer_limits.per_transaction IS 'null for no limit, as daily and monthly';
+COMMENT ON COLUMN transfer_limits.daily IS 'per UTC calendar day';
+COMMENT ON COLUMN transfer_limits.monthly IS 'per UTC calendar month';
+
+CREATE INDEX ON entries (account_id, created_at) WHERE amount < 0;
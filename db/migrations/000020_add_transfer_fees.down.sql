@@ -0,0 +1,17 @@
+DROP TABLE IF EXISTS fee_schedules;
+
+DELETE FROM entries WHERE account_id IN (SELECT id FROM accounts WHERE owner = 'fees');
+
+DELETE FROM accounts WHERE owner = 'fees';
+
+DELETE FROM users WHERE username = 'fees';
+
+DROP INDEX IF EXISTS "accounts_fee_currency_key";
+
+ALTER TABLE IF EXISTS accounts DROP CONSTRAINT "accounts_kind_check";
+
+ALTER TABLE IF EXISTS accounts ADD CONSTRAINT "accounts_kind_check" CHECK (kind IN ('customer', 'settlement'));
+
+ALTER TABLE IF EXISTS transfer_batches DROP COLUMN total_fee;
+
+ALTER TABLE IF EXISTS transfers DROP COLUMN fee;
@@ -0,0 +1,41 @@
+ALTER TABLE transfers ADD COLUMN fee bigint NOT NULL DEFAULT 0 CHECK (fee >= 0);
+
+COMMENT ON COLUMN transfers.fee IS 'charged to the from account on top of amount, in its currency';
+
+ALTER TABLE transfer_batches ADD COLUMN total_fee bigint NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN transfer_batches.total_fee IS 'sum of the fees of the succeeded items';
+
+ALTER TABLE accounts DROP CONSTRAINT "accounts_kind_check";
+
+ALTER TABLE accounts ADD CONSTRAINT "accounts_kind_check" CHECK (kind IN ('customer', 'settlement', 'fee'));
+
+CREATE UNIQUE INDEX "accounts_fee_currency_key" ON accounts (currency) WHERE kind = 'fee';
+
+-- a user holds one account per currency, so the fee accounts need an owner
+-- of their own besides system. It can never log in either.
+INSERT INTO users (username, hashed_password, full_name, email, is_frozen)
+VALUES ('fees', '', 'Simple Bank Fees', 'fees@simplebank.local', true);
+
+-- account numbers are built as in 000014_add_account_numbers
+INSERT INTO accounts (owner, balance, currency, kind, number)
+SELECT 'fees', 0, code, 'fee', 'SB' || lpad((98 - (bban || '281100')::numeric % 97)::int::text, 2, '0') || bban
+FROM (
+    SELECT code, lpad(floor(random() * 1e12)::bigint::text, 12, '0') AS bban FROM currencies
+) AS generated;
+
+CREATE TABLE fee_schedules(
+    currency varchar PRIMARY KEY,
+    flat bigint NOT NULL DEFAULT 0 CHECK (flat >= 0),
+    percentage_bps int NOT NULL DEFAULT 0 CHECK (percentage_bps >= 0 AND percentage_bps <= 10000),
+    min_fee bigint CHECK (min_fee >= 0),
+    max_fee bigint CHECK (max_fee >= 0),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    FOREIGN KEY (currency) REFERENCES currencies(code),
+    CHECK (max_fee >= min_fee)
+);
+
+COMMENT ON COLUMN fee_schedules.flat IS 'charged on every transfer, in the minor unit of the currency';
+COMMENT ON COLUMN fee_schedules.percentage_bps IS 'charged on the amount in basis points, 150 is 1.5%';
+COMMENT ON COLUMN fee_schedules.min_fee IS 'floor of flat plus percentage, null for none';
+COMMENT ON COLUMN fee_schedules.max_fee IS 'cap of flat plus percentage, null for none';
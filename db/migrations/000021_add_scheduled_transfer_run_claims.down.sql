@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS scheduled_transfer_runs DROP COLUMN claimed_at;
@@ -0,0 +1,5 @@
+ALTER TABLE scheduled_transfer_runs ADD COLUMN claimed_at timestamptz NOT NULL DEFAULT now();
+
+COMMENT ON COLUMN scheduled_transfer_runs.claimed_at IS 'the last time a scheduler took the run, a run still pending long after is taken again';
+
+CREATE INDEX ON scheduled_transfer_runs (claimed_at) WHERE status = 'pending';
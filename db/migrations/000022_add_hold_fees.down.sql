@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS holds DROP COLUMN fee;
@@ -0,0 +1,3 @@
+ALTER TABLE holds ADD COLUMN fee bigint NOT NULL DEFAULT 0 CHECK (fee >= 0);
+
+COMMENT ON COLUMN holds.fee IS 'reserved on top of amount for the fee of the capture, held_amount of the account counts both';
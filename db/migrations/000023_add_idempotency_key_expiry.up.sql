@@ -0,0 +1,6 @@
+ALTER TABLE idempotency_keys ADD COLUMN expires_at timestamptz NOT NULL DEFAULT now() + interval '24 hours';
+ALTER TABLE idempotency_keys ALTER COLUMN expires_at DROP DEFAULT;
+
+COMMENT ON COLUMN idempotency_keys.expires_at IS 'a request retried with the key is replayed until then, the key can be reused and is purged afterwards';
+
+CREATE INDEX ON idempotency_keys (expires_at);
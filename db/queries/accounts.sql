@@ -2,6 +2,14 @@
 SELECT * FROM accounts
 WHERE id = $1 LIMIT 1;
 
+-- name: GetAccountByNumber :one
+SELECT * FROM accounts
+WHERE number = $1 LIMIT 1;
+
+-- name: GetAccountByOwnerAndCurrency :one
+SELECT * FROM accounts
+WHERE owner = $1 AND currency = $2 LIMIT 1;
+
 -- name: GetAccountForUpdate :one
 SELECT * FROM accounts
 WHERE id = $1 LIMIT 1 
@@ -16,8 +24,8 @@ LIMIT $2
 OFFSET $3;
 
 -- name: CreateAccount :one
-INSERT INTO accounts (owner,balance,currency) 
-VALUES ($1,$2,$3) 
+INSERT INTO accounts (owner,balance,currency,number)
+VALUES ($1,$2,$3,$4)
 returning *;
 
 
@@ -33,9 +41,36 @@ SET balance = balance + sqlc.arg(amount)
 WHERE id=sqlc.arg(id)
 returning *;
 
+-- name: AddAccountHeldAmount :one
+UPDATE accounts
+SET held_amount = held_amount + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+returning *;
+
 
 -- name: DeleteAccount :exec
 DELETE FROM accounts WHERE id = $1;
 
 -- name: DeleteAllAccounts :exec
-DELETE FROM accounts;
\ No newline at end of file
+-- settlement and fee accounts are part of the schema and must survive a cleanup
+DELETE FROM accounts WHERE kind NOT IN ('settlement', 'fee');
+
+-- name: UpdateAccountStatus :one
+UPDATE accounts
+SET status = sqlc.arg(status)
+WHERE id = sqlc.arg(id) AND status = sqlc.arg(current_status)
+returning *;
+
+-- name: CloseAccount :one
+UPDATE accounts
+SET status = 'closed'
+WHERE id = $1 AND status = 'active' AND balance = 0
+returning *;
+
+-- name: GetSettlementAccount :one
+SELECT * FROM accounts
+WHERE kind = 'settlement' AND currency = $1 LIMIT 1;
+
+-- name: GetFeeAccount :one
+SELECT * FROM accounts
+WHERE kind = 'fee' AND currency = $1 LIMIT 1;
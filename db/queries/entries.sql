@@ -1,6 +1,6 @@
 -- name: CreateEntry :one
-INSERT INTO entries(account_id, amount)
-VALUES ($1, $2)
+INSERT INTO entries(account_id, amount, transfer_id)
+VALUES ($1, $2, $3)
 returning *;
 
 -- name: GetEntry :one
@@ -8,4 +8,29 @@ SELECT * FROM entries
 WHERE id = $1 LIMIT 1;
 
 -- name: DeleteAllEntries :exec
-DELETE FROM entries;
\ No newline at end of file
+DELETE FROM entries;
+
+-- name: ListEntries :many
+SELECT * FROM entries
+WHERE account_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: ListAccountStatement :many
+SELECT id, account_id, amount, created_at, transfer_id, description, reference, running_balance::bigint
+FROM (
+    SELECT entries.*,
+        COALESCE(transfers.description, '')::varchar AS description,
+        COALESCE(transfers.reference, '')::varchar AS reference,
+        accounts.balance - SUM(entries.amount) OVER () + SUM(entries.amount) OVER (ORDER BY entries.id) AS running_balance
+    FROM entries
+    JOIN accounts ON accounts.id = entries.account_id
+    LEFT JOIN transfers ON transfers.id = entries.transfer_id
+    WHERE entries.account_id = sqlc.arg(account_id)
+) AS statement
+WHERE (sqlc.narg(from_time)::timestamptz IS NULL OR created_at >= sqlc.narg(from_time))
+    AND (sqlc.narg(to_time)::timestamptz IS NULL OR created_at < sqlc.narg(to_time))
+ORDER BY id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
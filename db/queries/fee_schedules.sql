@@ -0,0 +1,18 @@
+-- name: UpsertFeeSchedule :one
+INSERT INTO fee_schedules (currency,flat,percentage_bps,min_fee,max_fee)
+VALUES ($1,$2,$3,$4,$5)
+ON CONFLICT (currency)
+DO UPDATE SET flat = EXCLUDED.flat, percentage_bps = EXCLUDED.percentage_bps, min_fee = EXCLUDED.min_fee, max_fee = EXCLUDED.max_fee, updated_at = now()
+returning *;
+
+-- name: GetFeeSchedule :one
+SELECT * FROM fee_schedules
+WHERE currency = $1 LIMIT 1;
+
+-- name: ListFeeSchedules :many
+SELECT * FROM fee_schedules
+ORDER BY currency;
+
+-- name: DeleteFeeSchedule :exec
+DELETE FROM fee_schedules
+WHERE currency = $1;
@@ -0,0 +1,25 @@
+-- name: GetFxRate :one
+SELECT * FROM fx_rates
+WHERE base_currency = $1 AND quote_currency = $2 LIMIT 1;
+
+-- name: UpsertFxRate :one
+INSERT INTO fx_rates (base_currency,quote_currency,rate)
+VALUES ($1,$2,$3)
+ON CONFLICT (base_currency, quote_currency)
+DO UPDATE SET rate = EXCLUDED.rate, updated_at = now()
+returning *;
+
+-- name: CreateFxQuote :one
+INSERT INTO fx_quotes (id,username,from_currency,to_currency,rate,expires_at)
+VALUES ($1,$2,$3,$4,$5,$6)
+returning *;
+
+-- name: GetFxQuoteForUpdate :one
+SELECT * FROM fx_quotes
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: MarkFxQuoteUsed :exec
+UPDATE fx_quotes
+SET used_at = now()
+WHERE id = $1;
@@ -0,0 +1,37 @@
+-- name: CreateHold :one
+INSERT INTO holds (account_id,to_account_id,amount,fee,currency,description,reference,expires_at)
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+returning *;
+
+-- name: GetHold :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1;
+
+-- name: GetHoldForUpdate :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: CaptureHold :one
+UPDATE holds
+SET status = 'captured', captured_amount = sqlc.arg(captured_amount), transfer_id = sqlc.arg(transfer_id)
+WHERE id = sqlc.arg(id)
+returning *;
+
+-- name: UpdateHoldStatus :one
+UPDATE holds
+SET status = sqlc.arg(status)
+WHERE id = sqlc.arg(id)
+returning *;
+
+-- name: ListExpiredHolds :many
+-- holds locked by a capture or a void in progress are left to them, the
+-- others come in the order their accounts are locked in
+SELECT * FROM holds
+WHERE status = 'pending' AND expires_at <= now()
+ORDER BY account_id, id
+LIMIT $1
+FOR NO KEY UPDATE SKIP LOCKED;
+
+-- name: DeleteAllHolds :exec
+DELETE FROM holds;
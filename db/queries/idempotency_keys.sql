@@ -0,0 +1,17 @@
+-- name: CreateIdempotencyKey :one
+-- an expired key is taken over, an unexpired one is left alone and no row is
+-- returned
+INSERT INTO idempotency_keys (username,key,request_hash,response,expires_at)
+VALUES ($1,$2,$3,$4,$5)
+ON CONFLICT (username,key) DO UPDATE
+SET request_hash = EXCLUDED.request_hash, response = EXCLUDED.response, created_at = now(), expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= now()
+returning *;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE username = $1 AND key = $2 AND expires_at > now() LIMIT 1;
+
+-- name: DeleteExpiredIdempotencyKeys :exec
+DELETE FROM idempotency_keys
+WHERE expires_at <= now();
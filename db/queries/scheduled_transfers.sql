@@ -0,0 +1,73 @@
+-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers (owner,from_account_id,to_account_id,amount,currency,description,reference,schedule,next_run_at)
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+returning *;
+
+-- name: GetScheduledTransfer :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1 LIMIT 1;
+
+-- name: ListScheduledTransfers :many
+SELECT * FROM scheduled_transfers
+WHERE owner = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: UpdateScheduledTransferStatus :one
+-- next_run_at is kept when null is given
+UPDATE scheduled_transfers
+SET status = sqlc.arg(status), next_run_at = COALESCE(sqlc.narg(next_run_at), next_run_at)
+WHERE id = sqlc.arg(id) AND status = sqlc.arg(current_status)
+returning *;
+
+-- name: ListDueScheduledTransfers :many
+-- rows claimed by another replica are skipped instead of waited for
+SELECT * FROM scheduled_transfers
+WHERE status = 'active' AND next_run_at <= now()
+ORDER BY next_run_at
+LIMIT $1
+FOR NO KEY UPDATE SKIP LOCKED;
+
+-- name: AdvanceScheduledTransfer :one
+UPDATE scheduled_transfers
+SET next_run_at = sqlc.narg(next_run_at), status = sqlc.arg(status)
+WHERE id = sqlc.arg(id)
+returning *;
+
+-- name: CreateScheduledTransferRun :one
+INSERT INTO scheduled_transfer_runs (scheduled_transfer_id,scheduled_at)
+VALUES ($1,$2)
+returning *;
+
+-- name: FinishScheduledTransferRun :one
+UPDATE scheduled_transfer_runs
+SET status = sqlc.arg(status), transfer_id = sqlc.narg(transfer_id), error = sqlc.arg(error)
+WHERE id = sqlc.arg(id)
+returning *;
+
+-- name: ClaimStaleScheduledTransferRuns :many
+-- runs taken by another replica are skipped instead of waited for
+UPDATE scheduled_transfer_runs
+SET claimed_at = now()
+WHERE id IN (
+    SELECT id FROM scheduled_transfer_runs
+    WHERE status = 'pending' AND claimed_at < sqlc.arg(claimed_before)
+    ORDER BY id
+    LIMIT sqlc.arg('limit')
+    FOR UPDATE SKIP LOCKED
+)
+returning *;
+
+-- name: ListScheduledTransferRuns :many
+SELECT * FROM scheduled_transfer_runs
+WHERE scheduled_transfer_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: DeleteAllScheduledTransferRuns :exec
+DELETE FROM scheduled_transfer_runs;
+
+-- name: DeleteAllScheduledTransfers :exec
+DELETE FROM scheduled_transfers;
@@ -0,0 +1,24 @@
+-- name: CreateTransferBatch :one
+INSERT INTO transfer_batches (owner,from_account_id,currency,mode,status,item_count,succeeded_count,total_amount,total_fee)
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+returning *;
+
+-- name: GetTransferBatch :one
+SELECT * FROM transfer_batches
+WHERE id = $1 LIMIT 1;
+
+-- name: CreateTransferBatchItem :one
+INSERT INTO transfer_batch_items (batch_id,position,to_account_id,amount,description,reference,status,transfer_id,error)
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+returning *;
+
+-- name: ListTransferBatchItems :many
+SELECT * FROM transfer_batch_items
+WHERE batch_id = $1
+ORDER BY position;
+
+-- name: DeleteAllTransferBatchItems :exec
+DELETE FROM transfer_batch_items;
+
+-- name: DeleteAllTransferBatches :exec
+DELETE FROM transfer_batches;
@@ -0,0 +1,30 @@
+-- name: UpsertTransferLimit :one
+INSERT INTO transfer_limits (currency,owner,account_id,per_transaction,daily,monthly)
+VALUES ($1,$2,$3,$4,$5,$6)
+ON CONFLICT (currency, owner, account_id) DO UPDATE
+SET per_transaction = EXCLUDED.per_transaction, daily = EXCLUDED.daily, monthly = EXCLUDED.monthly
+returning *;
+
+-- name: ListAccountTransferLimits :many
+-- the limits that apply to an account, least specific first: the one of its
+-- currency, the one of its owner in its currency and its own
+SELECT * FROM transfer_limits
+WHERE currency = sqlc.arg(currency)
+AND (
+    (owner IS NULL AND account_id IS NULL)
+    OR owner = sqlc.arg(owner)
+    OR account_id = sqlc.arg(account_id)
+)
+ORDER BY account_id IS NOT NULL, owner IS NOT NULL;
+
+-- name: GetAccountDebitTotals :one
+-- debits since day_start and since month_start, day_start must not be
+-- before month_start
+SELECT
+    COALESCE(SUM(-amount) FILTER (WHERE created_at >= sqlc.arg(day_start)), 0)::bigint AS daily,
+    COALESCE(SUM(-amount), 0)::bigint AS monthly
+FROM entries
+WHERE account_id = sqlc.arg(account_id) AND amount < 0 AND created_at >= sqlc.arg(month_start);
+
+-- name: DeleteAllTransferLimits :exec
+DELETE FROM transfer_limits;
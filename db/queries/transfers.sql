@@ -1,11 +1,55 @@
 -- name: CreateTransfer :one
-INSERT INTO transfers(from_account_id,to_account_id,amount)
-VALUES ($1,$2,$3)
+INSERT INTO transfers(from_account_id,to_account_id,amount,exchange_rate,currency,description,reference,reversal_of,fee)
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
 returning *;
 
 -- name: GetTransfer :one
 SELECT * FROM transfers
 WHERE id = $1 LIMIT 1;
 
+-- name: GetTransferForUpdate :one
+SELECT * FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: AddTransferReversedAmount :one
+UPDATE transfers
+SET reversed_amount = reversed_amount + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ListTransferReversals :many
+SELECT * FROM transfers
+WHERE reversal_of = $1
+ORDER BY id;
+
 -- name: DeleteAllTransfers :exec
-DELETE FROM transfers;
\ No newline at end of file
+DELETE FROM transfers;
+
+-- name: ListTransfers :many
+SELECT * FROM transfers
+WHERE from_account_id = sqlc.arg(account_id) OR to_account_id = sqlc.arg(account_id)
+ORDER BY id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: ListOwnerTransfers :many
+SELECT * FROM transfers
+WHERE (
+    (sqlc.arg(direction)::varchar <> 'in' AND from_account_id IN (
+        SELECT id FROM accounts
+        WHERE owner = sqlc.arg(owner) AND (sqlc.narg(account_id)::int IS NULL OR id = sqlc.narg(account_id))
+    ))
+    OR (sqlc.arg(direction)::varchar <> 'out' AND to_account_id IN (
+        SELECT id FROM accounts
+        WHERE owner = sqlc.arg(owner) AND (sqlc.narg(account_id)::int IS NULL OR id = sqlc.narg(account_id))
+    ))
+)
+    AND (sqlc.narg(min_amount)::bigint IS NULL OR amount >= sqlc.narg(min_amount))
+    AND (sqlc.narg(max_amount)::bigint IS NULL OR amount <= sqlc.narg(max_amount))
+    AND (sqlc.narg(from_time)::timestamptz IS NULL OR created_at >= sqlc.narg(from_time))
+    AND (sqlc.narg(to_time)::timestamptz IS NULL OR created_at < sqlc.narg(to_time))
+    AND (sqlc.narg(reference)::varchar IS NULL OR reference = sqlc.narg(reference))
+ORDER BY id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
@@ -6,4 +6,30 @@ returning *;
 
 -- name: GetUser :one
 SELECT * FROM users
-WHERE username = $1 LIMIT 1;
\ No newline at end of file
+WHERE username = $1 LIMIT 1;
+
+-- name: GetUserPasswordChangedAt :one
+SELECT password_changed_at FROM users
+WHERE username = $1 LIMIT 1;
+
+-- name: UpdateUserPassword :one
+UPDATE users
+SET hashed_password = $1, password_changed_at = $2
+WHERE username = $3
+returning *;
+
+-- name: ListUsers :many
+SELECT * FROM users
+WHERE sqlc.arg(search)::varchar = ''
+    OR username ILIKE '%' || sqlc.arg(search) || '%'
+    OR email ILIKE '%' || sqlc.arg(search) || '%'
+    OR full_name ILIKE '%' || sqlc.arg(search) || '%'
+ORDER BY username
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: UpdateUserFrozen :one
+UPDATE users
+SET is_frozen = $1
+WHERE username = $2
+returning *;
@@ -13,7 +13,7 @@ const addAccountBalance = `-- name: AddAccountBalance :one
 UPDATE accounts 
 SET balance = balance + $1
 WHERE id=$2
-returning id, owner, balance, currency, created_at
+returning id, owner, balance, currency, created_at, status, kind, number, held_amount, available_balance
 `
 
 type AddAccountBalanceParams struct {
@@ -30,24 +30,90 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Status,
+		&i.Kind,
+		&i.Number,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+	)
+	return i, err
+}
+
+const addAccountHeldAmount = `-- name: AddAccountHeldAmount :one
+UPDATE accounts
+SET held_amount = held_amount + $1
+WHERE id = $2
+returning id, owner, balance, currency, created_at, status, kind, number, held_amount, available_balance
+`
+
+type AddAccountHeldAmountParams struct {
+	Amount int64 `json:"amount"`
+	ID     int32 `json:"id"`
+}
+
+func (q *Queries) AddAccountHeldAmount(ctx context.Context, arg AddAccountHeldAmountParams) (Account, error) {
+	row := q.db.QueryRow(ctx, addAccountHeldAmount, arg.Amount, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.Status,
+		&i.Kind,
+		&i.Number,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+	)
+	return i, err
+}
+
+const closeAccount = `-- name: CloseAccount :one
+UPDATE accounts
+SET status = 'closed'
+WHERE id = $1 AND status = 'active' AND balance = 0
+returning id, owner, balance, currency, created_at, status, kind, number, held_amount, available_balance
+`
+
+func (q *Queries) CloseAccount(ctx context.Context, id int32) (Account, error) {
+	row := q.db.QueryRow(ctx, closeAccount, id)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.Status,
+		&i.Kind,
+		&i.Number,
+		&i.HeldAmount,
+		&i.AvailableBalance,
 	)
 	return i, err
 }
 
 const createAccount = `-- name: CreateAccount :one
-INSERT INTO accounts (owner,balance,currency) 
-VALUES ($1,$2,$3) 
-returning id, owner, balance, currency, created_at
+INSERT INTO accounts (owner,balance,currency,number)
+VALUES ($1,$2,$3,$4)
+returning id, owner, balance, currency, created_at, status, kind, number, held_amount, available_balance
 `
 
 type CreateAccountParams struct {
 	Owner    string `json:"owner"`
 	Balance  int64  `json:"balance"`
 	Currency string `json:"currency"`
+	Number   string `json:"number"`
 }
 
 func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
-	row := q.db.QueryRow(ctx, createAccount, arg.Owner, arg.Balance, arg.Currency)
+	row := q.db.QueryRow(ctx, createAccount,
+		arg.Owner,
+		arg.Balance,
+		arg.Currency,
+		arg.Number,
+	)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -55,6 +121,11 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Status,
+		&i.Kind,
+		&i.Number,
+		&i.HeldAmount,
+		&i.AvailableBalance,
 	)
 	return i, err
 }
@@ -69,16 +140,17 @@ func (q *Queries) DeleteAccount(ctx context.Context, id int32) error {
 }
 
 const deleteAllAccounts = `-- name: DeleteAllAccounts :exec
-DELETE FROM accounts
+DELETE FROM accounts WHERE kind NOT IN ('settlement', 'fee')
 `
 
+// settlement and fee accounts are part of the schema and must survive a cleanup
 func (q *Queries) DeleteAllAccounts(ctx context.Context) error {
 	_, err := q.db.Exec(ctx, deleteAllAccounts)
 	return err
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, status, kind, number, held_amount, available_balance FROM accounts
 WHERE id = $1 LIMIT 1
 `
 
@@ -91,12 +163,68 @@ func (q *Queries) GetAccount(ctx context.Context, id int32) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Status,
+		&i.Kind,
+		&i.Number,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+	)
+	return i, err
+}
+
+const getAccountByNumber = `-- name: GetAccountByNumber :one
+SELECT id, owner, balance, currency, created_at, status, kind, number, held_amount, available_balance FROM accounts
+WHERE number = $1 LIMIT 1
+`
+
+func (q *Queries) GetAccountByNumber(ctx context.Context, number string) (Account, error) {
+	row := q.db.QueryRow(ctx, getAccountByNumber, number)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.Status,
+		&i.Kind,
+		&i.Number,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+	)
+	return i, err
+}
+
+const getAccountByOwnerAndCurrency = `-- name: GetAccountByOwnerAndCurrency :one
+SELECT id, owner, balance, currency, created_at, status, kind, number, held_amount, available_balance FROM accounts
+WHERE owner = $1 AND currency = $2 LIMIT 1
+`
+
+type GetAccountByOwnerAndCurrencyParams struct {
+	Owner    string `json:"owner"`
+	Currency string `json:"currency"`
+}
+
+func (q *Queries) GetAccountByOwnerAndCurrency(ctx context.Context, arg GetAccountByOwnerAndCurrencyParams) (Account, error) {
+	row := q.db.QueryRow(ctx, getAccountByOwnerAndCurrency, arg.Owner, arg.Currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.Status,
+		&i.Kind,
+		&i.Number,
+		&i.HeldAmount,
+		&i.AvailableBalance,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, status, kind, number, held_amount, available_balance FROM accounts
 WHERE id = $1 LIMIT 1 
 FOR NO KEY UPDATE
 `
@@ -110,12 +238,63 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int32) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Status,
+		&i.Kind,
+		&i.Number,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+	)
+	return i, err
+}
+
+const getFeeAccount = `-- name: GetFeeAccount :one
+SELECT id, owner, balance, currency, created_at, status, kind, number, held_amount, available_balance FROM accounts
+WHERE kind = 'fee' AND currency = $1 LIMIT 1
+`
+
+func (q *Queries) GetFeeAccount(ctx context.Context, currency string) (Account, error) {
+	row := q.db.QueryRow(ctx, getFeeAccount, currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.Status,
+		&i.Kind,
+		&i.Number,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+	)
+	return i, err
+}
+
+const getSettlementAccount = `-- name: GetSettlementAccount :one
+SELECT id, owner, balance, currency, created_at, status, kind, number, held_amount, available_balance FROM accounts
+WHERE kind = 'settlement' AND currency = $1 LIMIT 1
+`
+
+func (q *Queries) GetSettlementAccount(ctx context.Context, currency string) (Account, error) {
+	row := q.db.QueryRow(ctx, getSettlementAccount, currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.Status,
+		&i.Kind,
+		&i.Number,
+		&i.HeldAmount,
+		&i.AvailableBalance,
 	)
 	return i, err
 }
 
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, status, kind, number, held_amount, available_balance FROM accounts
 WHERE owner = $1
 ORDER BY id
 LIMIT $2
@@ -143,6 +322,11 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.Status,
+			&i.Kind,
+			&i.Number,
+			&i.HeldAmount,
+			&i.AvailableBalance,
 		); err != nil {
 			return nil, err
 		}
@@ -158,7 +342,7 @@ const updateAccount = `-- name: UpdateAccount :one
 UPDATE accounts 
 SET balance = $1
 WHERE id=$2
-returning id, owner, balance, currency, created_at
+returning id, owner, balance, currency, created_at, status, kind, number, held_amount, available_balance
 `
 
 type UpdateAccountParams struct {
@@ -175,6 +359,42 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Status,
+		&i.Kind,
+		&i.Number,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+	)
+	return i, err
+}
+
+const updateAccountStatus = `-- name: UpdateAccountStatus :one
+UPDATE accounts
+SET status = $1
+WHERE id = $2 AND status = $3
+returning id, owner, balance, currency, created_at, status, kind, number, held_amount, available_balance
+`
+
+type UpdateAccountStatusParams struct {
+	Status        string `json:"status"`
+	ID            int32  `json:"id"`
+	CurrentStatus string `json:"current_status"`
+}
+
+func (q *Queries) UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error) {
+	row := q.db.QueryRow(ctx, updateAccountStatus, arg.Status, arg.ID, arg.CurrentStatus)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.Status,
+		&i.Kind,
+		&i.Number,
+		&i.HeldAmount,
+		&i.AvailableBalance,
 	)
 	return i, err
 }
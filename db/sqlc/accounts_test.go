@@ -10,12 +10,21 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func createRandomAccount(t *testing.T) db.Account {
+func createRandomAccount(t *testing.T, currency ...string) db.Account {
 	user := createRandomUser(t)
+	number, err := utils.NewAccountNumber()
+	require.NoError(t, err)
+
 	params := db.CreateAccountParams{
 		Owner:    user.Username,
 		Balance:  utils.RandomMoney(),
 		Currency: utils.RandomCurrency(),
+		Number:   number,
+	}
+
+	// transfers need both accounts in the same currency
+	if len(currency) > 0 {
+		params.Currency = currency[0]
 	}
 
 	account, err := testQueries.CreateAccount(context.Background(), params)
@@ -23,6 +32,11 @@ func createRandomAccount(t *testing.T) db.Account {
 	require.Equal(t, params.Owner, account.Owner)
 	require.Equal(t, params.Balance, account.Balance)
 	require.Equal(t, params.Currency, account.Currency)
+	require.Equal(t, params.Number, account.Number)
+	require.Zero(t, account.HeldAmount)
+	require.Equal(t, params.Balance, account.AvailableBalance)
+	require.Equal(t, utils.AccountStatusActive, account.Status)
+	require.Equal(t, utils.AccountKindCustomer, account.Kind)
 	require.NotZero(t, account.ID)
 	require.NotZero(t, account.CreatedAt)
 
@@ -44,6 +58,37 @@ func TestGetAccount(t *testing.T) {
 	require.Equal(t, account1.CreatedAt, account2.CreatedAt)
 }
 
+func TestGetAccountByNumber(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2, err := testQueries.GetAccountByNumber(context.Background(), account1.Number)
+	require.NoError(t, err)
+	require.Equal(t, account1, account2)
+
+	// numbers are unique
+	_, err = testQueries.CreateAccount(context.Background(), db.CreateAccountParams{
+		Owner:    createRandomUser(t).Username,
+		Currency: "USD",
+		Number:   account1.Number,
+	})
+	require.Error(t, err)
+}
+
+func TestGetAccountByOwnerAndCurrency(t *testing.T) {
+	account1 := createRandomAccount(t, "EUR")
+	account2, err := testQueries.GetAccountByOwnerAndCurrency(context.Background(), db.GetAccountByOwnerAndCurrencyParams{
+		Owner:    account1.Owner,
+		Currency: "EUR",
+	})
+	require.NoError(t, err)
+	require.Equal(t, account1, account2)
+
+	_, err = testQueries.GetAccountByOwnerAndCurrency(context.Background(), db.GetAccountByOwnerAndCurrencyParams{
+		Owner:    account1.Owner,
+		Currency: "USD",
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
 func TestUpdateAccount(t *testing.T) {
 	account1 := createRandomAccount(t)
 
@@ -94,3 +139,42 @@ func TestListAccounts(t *testing.T) {
 		require.Equal(t, lastAccount.Owner, account.Owner)
 	}
 }
+
+func TestUpdateAccountStatus(t *testing.T) {
+	account1 := createRandomAccount(t)
+
+	account2, err := testQueries.UpdateAccountStatus(context.Background(), db.UpdateAccountStatusParams{
+		ID:            account1.ID,
+		Status:        utils.AccountStatusFrozen,
+		CurrentStatus: utils.AccountStatusActive,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account1.ID, account2.ID)
+	require.Equal(t, account1.Balance, account2.Balance)
+	require.Equal(t, utils.AccountStatusFrozen, account2.Status)
+
+	// the update is skipped when the status is no longer the expected one
+	_, err = testQueries.UpdateAccountStatus(context.Background(), db.UpdateAccountStatusParams{
+		ID:            account1.ID,
+		Status:        utils.AccountStatusFrozen,
+		CurrentStatus: utils.AccountStatusActive,
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestCloseAccount(t *testing.T) {
+	account1 := createRandomAccount(t)
+
+	_, err := testQueries.CloseAccount(context.Background(), account1.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	_, err = testQueries.UpdateAccount(context.Background(), db.UpdateAccountParams{
+		ID:      account1.ID,
+		Balance: 0,
+	})
+	require.NoError(t, err)
+
+	account2, err := testQueries.CloseAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, utils.AccountStatusClosed, account2.Status)
+}
@@ -0,0 +1,32 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListCurrencies(t *testing.T) {
+	currencies, err := testQueries.ListCurrencies(context.Background())
+	require.NoError(t, err)
+
+	codes := make([]string, 0, len(currencies))
+	for _, currency := range currencies {
+		codes = append(codes, currency.Code)
+	}
+	require.Subset(t, codes, []string{"EUR", "IRR", "USD"})
+	require.IsNonDecreasing(t, codes)
+}
+
+func TestGetCurrency(t *testing.T) {
+	usd, err := testQueries.GetCurrency(context.Background(), "USD")
+	require.NoError(t, err)
+	require.Equal(t, int32(840), usd.NumericCode)
+	require.Equal(t, int32(2), usd.MinorUnits)
+	require.True(t, usd.Enabled)
+
+	_, err = testQueries.GetCurrency(context.Background(), "XXX")
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
@@ -7,27 +7,31 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createEntry = `-- name: CreateEntry :one
-INSERT INTO entries(account_id, amount)
-VALUES ($1, $2)
-returning id, account_id, amount, created_at
+INSERT INTO entries(account_id, amount, transfer_id)
+VALUES ($1, $2, $3)
+returning id, account_id, amount, created_at, transfer_id
 `
 
 type CreateEntryParams struct {
-	AccountID int32 `json:"account_id"`
-	Amount    int64 `json:"amount"`
+	AccountID  int32       `json:"account_id"`
+	Amount     int64       `json:"amount"`
+	TransferID pgtype.Int4 `json:"transfer_id"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRow(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRow(ctx, createEntry, arg.AccountID, arg.Amount, arg.TransferID)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
 	)
 	return i, err
 }
@@ -42,7 +46,7 @@ func (q *Queries) DeleteAllEntries(ctx context.Context) error {
 }
 
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id FROM entries
 WHERE id = $1 LIMIT 1
 `
 
@@ -54,6 +58,120 @@ func (q *Queries) GetEntry(ctx context.Context, id int32) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
 	)
 	return i, err
 }
+
+const listAccountStatement = `-- name: ListAccountStatement :many
+SELECT id, account_id, amount, created_at, transfer_id, description, reference, running_balance::bigint
+FROM (
+    SELECT entries.id, entries.account_id, entries.amount, entries.created_at, entries.transfer_id,
+        COALESCE(transfers.description, '')::varchar AS description,
+        COALESCE(transfers.reference, '')::varchar AS reference,
+        accounts.balance - SUM(entries.amount) OVER () + SUM(entries.amount) OVER (ORDER BY entries.id) AS running_balance
+    FROM entries
+    JOIN accounts ON accounts.id = entries.account_id
+    LEFT JOIN transfers ON transfers.id = entries.transfer_id
+    WHERE entries.account_id = $1
+) AS statement
+WHERE ($2::timestamptz IS NULL OR created_at >= $2)
+    AND ($3::timestamptz IS NULL OR created_at < $3)
+ORDER BY id DESC
+LIMIT $4
+OFFSET $5
+`
+
+type ListAccountStatementParams struct {
+	AccountID int32              `json:"account_id"`
+	FromTime  pgtype.Timestamptz `json:"from_time"`
+	ToTime    pgtype.Timestamptz `json:"to_time"`
+	Limit     int32              `json:"limit"`
+	Offset    int32              `json:"offset"`
+}
+
+type ListAccountStatementRow struct {
+	ID             int32              `json:"id"`
+	AccountID      int32              `json:"account_id"`
+	Amount         int64              `json:"amount"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	TransferID     pgtype.Int4        `json:"transfer_id"`
+	Description    string             `json:"description"`
+	Reference      string             `json:"reference"`
+	RunningBalance int64              `json:"running_balance"`
+}
+
+func (q *Queries) ListAccountStatement(ctx context.Context, arg ListAccountStatementParams) ([]ListAccountStatementRow, error) {
+	rows, err := q.db.Query(ctx, listAccountStatement,
+		arg.AccountID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAccountStatementRow{}
+	for rows.Next() {
+		var i ListAccountStatementRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.Description,
+			&i.Reference,
+			&i.RunningBalance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntries = `-- name: ListEntries :many
+SELECT id, account_id, amount, created_at, transfer_id FROM entries
+WHERE account_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3
+`
+
+type ListEntriesParams struct {
+	AccountID int32 `json:"account_id"`
+	Limit     int32 `json:"limit"`
+	Offset    int32 `json:"offset"`
+}
+
+func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error) {
+	rows, err := q.db.Query(ctx, listEntries, arg.AccountID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -2,8 +2,11 @@ package db_test
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
+	"github.com/jackc/pgx/v5/pgtype"
 	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
 	"github.com/mohammad19khodaei/simple_bank/utils"
 	"github.com/stretchr/testify/require"
@@ -23,3 +26,73 @@ func TestCreateEntry(t *testing.T) {
 	require.Equal(t, params.Amount, entry.Amount)
 	require.NotZero(t, entry.CreatedAt)
 }
+
+func TestListEntries(t *testing.T) {
+	account := createRandomAccount(t)
+	for i := 0; i < 5; i++ {
+		_, err := testQueries.CreateEntry(context.Background(), db.CreateEntryParams{
+			AccountID: account.ID,
+			Amount:    utils.RandomMoney(),
+		})
+		require.NoError(t, err)
+	}
+
+	entries, err := testQueries.ListEntries(context.Background(), db.ListEntriesParams{
+		AccountID: account.ID,
+		Limit:     3,
+		Offset:    0,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	for _, entry := range entries {
+		require.Equal(t, account.ID, entry.AccountID)
+	}
+}
+
+func TestListAccountStatement(t *testing.T) {
+	store := db.NewStore(testPool)
+	account1 := createRandomAccount(t, "USD")
+	account2 := createRandomAccount(t, "USD")
+
+	amounts := []int64{10, 20, 30}
+	for _, amount := range amounts {
+		_, err := store.TransferTx(context.Background(), db.TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+			Description:   fmt.Sprintf("payment of %d", amount),
+			Reference:     fmt.Sprintf("REF-%d", amount),
+		})
+		require.NoError(t, err)
+	}
+
+	statement, err := testQueries.ListAccountStatement(context.Background(), db.ListAccountStatementParams{
+		AccountID: account1.ID,
+		Limit:     10,
+		Offset:    0,
+	})
+	require.NoError(t, err)
+	require.Len(t, statement, len(amounts))
+
+	// newest line first, each with the balance right after the entry
+	balance := account1.Balance - 60
+	for _, line := range statement {
+		require.Equal(t, account1.ID, line.AccountID)
+		require.Equal(t, balance, line.RunningBalance)
+		require.True(t, line.TransferID.Valid)
+		require.Equal(t, fmt.Sprintf("payment of %d", -line.Amount), line.Description)
+		require.Equal(t, fmt.Sprintf("REF-%d", -line.Amount), line.Reference)
+		balance -= line.Amount
+	}
+	require.Equal(t, account1.Balance, balance)
+
+	statement, err = testQueries.ListAccountStatement(context.Background(), db.ListAccountStatementParams{
+		AccountID: account1.ID,
+		FromTime:  pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+		Limit:     10,
+		Offset:    0,
+	})
+	require.NoError(t, err)
+	require.Empty(t, statement)
+}
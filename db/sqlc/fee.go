@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Fee returns what the schedule charges on a transfer of amount: the flat fee
+// plus the percentage of amount rounded half up, kept between the minimum
+// and the maximum. The zero schedule charges nothing.
+func (f FeeSchedule) Fee(amount int64) int64 {
+	bps := int64(f.PercentageBps)
+	// amount is split so amount * bps cannot overflow
+	fee := f.Flat + amount/10000*bps + (amount%10000*bps+5000)/10000
+
+	if f.MinFee.Valid && fee < f.MinFee.Int64 {
+		fee = f.MinFee.Int64
+	}
+	if f.MaxFee.Valid && fee > f.MaxFee.Int64 {
+		fee = f.MaxFee.Int64
+	}
+	return fee
+}
+
+// feeSchedule returns the fee schedule of the currency, the zero schedule
+// when it has none.
+func feeSchedule(ctx context.Context, q *Queries, currency string) (FeeSchedule, error) {
+	schedule, err := q.GetFeeSchedule(ctx, currency)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return FeeSchedule{Currency: currency}, nil
+	}
+	return schedule, err
+}
+
+// creditFee moves the fee of the transfer into the fee account of its
+// currency. The fee account is only updated after both accounts of the
+// transfer are locked and nothing else locks it first, so it cannot take
+// part in a deadlock.
+func creditFee(ctx context.Context, q *Queries, transfer Transfer) (*Entry, error) {
+	feeAccount, err := q.GetFeeAccount(ctx, transfer.Currency)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("no fee account in %s", transfer.Currency)
+		}
+		return nil, err
+	}
+
+	entry, err := q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  feeAccount.ID,
+		Amount:     transfer.Fee,
+		TransferID: pgtype.Int4{Int32: transfer.ID, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+		ID:     feeAccount.ID,
+		Amount: transfer.Fee,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
@@ -0,0 +1,221 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// fees are only charged in IRR here, a schedule applies to every transfer of
+// its currency and the other tests move USD and EUR
+const feeCurrency = "IRR"
+
+func setFeeSchedule(t *testing.T, flat int64, percentageBps int32) db.FeeSchedule {
+	schedule, err := testQueries.UpsertFeeSchedule(context.Background(), db.UpsertFeeScheduleParams{
+		Currency:      feeCurrency,
+		Flat:          flat,
+		PercentageBps: percentageBps,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, testQueries.DeleteFeeSchedule(context.Background(), feeCurrency))
+	})
+
+	return schedule
+}
+
+func TestFeeScheduleFee(t *testing.T) {
+	testCases := []struct {
+		name     string
+		schedule db.FeeSchedule
+		amount   int64
+		fee      int64
+	}{
+		{name: "zero", schedule: db.FeeSchedule{}, amount: 1000, fee: 0},
+		{name: "flat", schedule: db.FeeSchedule{Flat: 25}, amount: 1000, fee: 25},
+		{name: "percentage", schedule: db.FeeSchedule{PercentageBps: 150}, amount: 10000, fee: 150},
+		{name: "rounded half up", schedule: db.FeeSchedule{PercentageBps: 50}, amount: 101, fee: 1},
+		{name: "rounded down", schedule: db.FeeSchedule{PercentageBps: 40}, amount: 101, fee: 0},
+		{name: "flat and percentage", schedule: db.FeeSchedule{Flat: 10, PercentageBps: 100}, amount: 5000, fee: 60},
+		{
+			name:     "minimum",
+			schedule: db.FeeSchedule{PercentageBps: 100, MinFee: pgtype.Int8{Int64: 30, Valid: true}},
+			amount:   1000,
+			fee:      30,
+		},
+		{
+			name:     "maximum",
+			schedule: db.FeeSchedule{PercentageBps: 100, MaxFee: pgtype.Int8{Int64: 500, Valid: true}},
+			amount:   1_000_000,
+			fee:      500,
+		},
+		{name: "large amount", schedule: db.FeeSchedule{PercentageBps: 10000}, amount: 1 << 60, fee: 1 << 60},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.fee, tc.schedule.Fee(tc.amount))
+		})
+	}
+}
+
+func TestUpsertFeeSchedule(t *testing.T) {
+	setFeeSchedule(t, 10, 0)
+	schedule := setFeeSchedule(t, 0, 250)
+	require.Equal(t, feeCurrency, schedule.Currency)
+	require.Zero(t, schedule.Flat)
+	require.Equal(t, int32(250), schedule.PercentageBps)
+
+	got, err := testQueries.GetFeeSchedule(context.Background(), feeCurrency)
+	require.NoError(t, err)
+	require.Equal(t, schedule, got)
+}
+
+func TestTransferTxFee(t *testing.T) {
+	store := db.NewStore(testPool)
+	fromAccount := createRandomAccount(t, feeCurrency)
+	toAccount := createRandomAccount(t, feeCurrency)
+
+	feeAccount, err := testQueries.GetFeeAccount(context.Background(), feeCurrency)
+	require.NoError(t, err)
+	require.Equal(t, utils.AccountKindFee, feeAccount.Kind)
+
+	setFeeSchedule(t, 5, 100)
+
+	result, err := store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        1000,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(15), result.Transfer.Fee)
+	require.Equal(t, int64(-1015), result.FromEntry.Amount)
+	require.Equal(t, int64(1000), result.ToEntry.Amount)
+
+	require.NotNil(t, result.FeeEntry)
+	require.Equal(t, feeAccount.ID, result.FeeEntry.AccountID)
+	require.Equal(t, int64(15), result.FeeEntry.Amount)
+	require.Equal(t, result.Transfer.ID, result.FeeEntry.TransferID.Int32)
+
+	require.Equal(t, fromAccount.Balance-1015, result.FromAccount.Balance)
+	require.Equal(t, toAccount.Balance+1000, result.ToAccount.Balance)
+
+	updatedFeeAccount, err := testQueries.GetAccount(context.Background(), feeAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, feeAccount.Balance+15, updatedFeeAccount.Balance)
+
+	// the fee must be covered by the balance too
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        result.FromAccount.Balance,
+	})
+	require.ErrorIs(t, err, db.ErrInsufficientBalance)
+}
+
+func TestTransferTxWithoutFee(t *testing.T) {
+	store := db.NewStore(testPool)
+	fromAccount := createRandomAccount(t, feeCurrency)
+	toAccount := createRandomAccount(t, feeCurrency)
+
+	result, err := store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+	require.Zero(t, result.Transfer.Fee)
+	require.Nil(t, result.FeeEntry)
+	require.Equal(t, int64(-10), result.FromEntry.Amount)
+}
+
+func TestBatchTransferTxFee(t *testing.T) {
+	store := db.NewStore(testPool)
+	fromAccount := createRandomAccount(t, feeCurrency)
+	toAccount := createRandomAccount(t, feeCurrency)
+
+	setFeeSchedule(t, 10, 0)
+
+	result, err := store.BatchTransferTx(context.Background(), db.BatchTransferTxParams{
+		Owner:         fromAccount.Owner,
+		FromAccountID: fromAccount.ID,
+		Mode:          utils.TransferBatchModeBestEffort,
+		Items: []db.BatchTransferItem{
+			{ToAccountID: toAccount.ID, Amount: 100},
+			{ToAccountID: toAccount.ID, Amount: fromAccount.Balance},
+			{ToAccountID: toAccount.ID, Amount: 50},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, utils.TransferBatchStatusPartiallyCompleted, result.Batch.Status)
+	require.Equal(t, int64(150), result.Batch.TotalAmount)
+	require.Equal(t, int64(20), result.Batch.TotalFee)
+	require.Equal(t, fromAccount.Balance-170, result.FromAccount.Balance)
+
+	require.Len(t, result.Items, 3)
+	require.Equal(t, utils.TransferBatchItemStatusFailed, result.Items[1].Status)
+
+	transfer, err := testQueries.GetTransfer(context.Background(), result.Items[0].TransferID.Int32)
+	require.NoError(t, err)
+	require.Equal(t, int64(10), transfer.Fee)
+}
+
+func TestCaptureTxFee(t *testing.T) {
+	store := db.NewStore(testPool)
+	fromAccount := createRandomAccount(t, feeCurrency)
+	toAccount := createRandomAccount(t, feeCurrency)
+
+	feeAccount, err := testQueries.GetFeeAccount(context.Background(), feeCurrency)
+	require.NoError(t, err)
+
+	schedule := setFeeSchedule(t, 5, 100)
+
+	// the fee of the whole amount is held with it
+	held, err := store.HoldTx(context.Background(), db.HoldTxParams{
+		AccountID:   fromAccount.ID,
+		ToAccountID: toAccount.ID,
+		Amount:      500,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(10), held.Hold.Fee)
+	require.Equal(t, int64(510), held.Account.HeldAmount)
+	require.Equal(t, fromAccount.Balance-510, held.Account.AvailableBalance)
+
+	// spending what is left available does not touch the hold
+	amount := (held.Account.AvailableBalance - schedule.Flat) * 100 / 101
+	spent, err := store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        amount,
+	})
+	require.NoError(t, err)
+	left := spent.FromAccount.AvailableBalance
+	require.Less(t, left, schedule.Fee(1))
+
+	result, err := store.CaptureTx(context.Background(), db.CaptureTxParams{
+		HoldID: held.Hold.ID,
+		Amount: 300,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(8), result.Transfer.Fee)
+	require.Equal(t, int64(-308), result.FromEntry.Amount)
+	require.Equal(t, int64(300), result.ToEntry.Amount)
+
+	require.NotNil(t, result.FeeEntry)
+	require.Equal(t, feeAccount.ID, result.FeeEntry.AccountID)
+	require.Equal(t, int64(8), result.FeeEntry.Amount)
+
+	// the rest of the reservation, its fee included, is released
+	require.Zero(t, result.FromAccount.HeldAmount)
+	require.Equal(t, left+510-308, result.FromAccount.AvailableBalance)
+
+	updatedFeeAccount, err := testQueries.GetAccount(context.Background(), feeAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, feeAccount.Balance+spent.Transfer.Fee+8, updatedFeeAccount.Balance)
+}
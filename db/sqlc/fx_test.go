@@ -0,0 +1,188 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func createFxQuote(t *testing.T, username string, from string, to string, rate string, expiresAt time.Time) db.FxQuote {
+	value, err := utils.ParseRate(rate)
+	require.NoError(t, err)
+	numeric, err := utils.RatToNumeric(value)
+	require.NoError(t, err)
+
+	quote, err := testQueries.CreateFxQuote(context.Background(), db.CreateFxQuoteParams{
+		ID:           pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Username:     username,
+		FromCurrency: from,
+		ToCurrency:   to,
+		Rate:         numeric,
+		ExpiresAt:    pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, username, quote.Username)
+	require.False(t, quote.UsedAt.Valid)
+
+	return quote
+}
+
+func TestUpsertFxRate(t *testing.T) {
+	base := utils.RandomString(3)
+
+	value, err := utils.ParseRate("0.92")
+	require.NoError(t, err)
+	numeric, err := utils.RatToNumeric(value)
+	require.NoError(t, err)
+
+	params := db.UpsertFxRateParams{
+		BaseCurrency:  base,
+		QuoteCurrency: "EUR",
+		Rate:          numeric,
+	}
+	_, err = testQueries.UpsertFxRate(context.Background(), params)
+	require.NoError(t, err)
+
+	value, err = utils.ParseRate("0.95")
+	require.NoError(t, err)
+	params.Rate, err = utils.RatToNumeric(value)
+	require.NoError(t, err)
+	_, err = testQueries.UpsertFxRate(context.Background(), params)
+	require.NoError(t, err)
+
+	rate, err := testQueries.GetFxRate(context.Background(), db.GetFxRateParams{
+		BaseCurrency:  base,
+		QuoteCurrency: "EUR",
+	})
+	require.NoError(t, err)
+
+	stored, err := utils.NumericToRat(rate.Rate)
+	require.NoError(t, err)
+	require.Equal(t, "0.95", utils.FormatRate(stored))
+}
+
+func TestTransferTxWithQuote(t *testing.T) {
+	store := db.NewStore(testPool)
+	account1 := createRandomAccount(t, "USD")
+	account2 := createRandomAccount(t, "EUR")
+
+	quote := createFxQuote(t, account1.Owner, "USD", "EUR", "0.92", time.Now().Add(time.Minute))
+
+	result, err := store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+		QuoteID:       quote.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(100), result.Transfer.Amount)
+	require.Equal(t, "USD", result.Transfer.Currency)
+	require.Equal(t, int64(-100), result.FromEntry.Amount)
+	require.Equal(t, int64(92), result.ToEntry.Amount)
+	require.Equal(t, account1.Balance-100, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+92, result.ToAccount.Balance)
+
+	rate, err := utils.NumericToRat(result.Transfer.ExchangeRate)
+	require.NoError(t, err)
+	require.Equal(t, "0.92", utils.FormatRate(rate))
+
+	// a quote pays for a single transfer
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+		QuoteID:       quote.ID,
+	})
+	require.ErrorIs(t, err, db.ErrInvalidQuote)
+}
+
+// createJPY registers a currency without minor units, the ones of the schema
+// all have two.
+func createJPY(t *testing.T) {
+	_, err := testPool.Exec(context.Background(), `
+		INSERT INTO currencies (code, numeric_code, minor_units, symbol, name)
+		VALUES ('JPY', 392, 0, '¥', 'Yen')
+		ON CONFLICT (code) DO NOTHING`)
+	require.NoError(t, err)
+}
+
+func TestTransferTxWithQuoteMinorUnits(t *testing.T) {
+	createJPY(t)
+
+	store := db.NewStore(testPool)
+	account1 := createRandomAccount(t, "USD")
+	account2 := createRandomAccount(t, "JPY")
+
+	quote := createFxQuote(t, account1.Owner, "USD", "JPY", "150", time.Now().Add(time.Minute))
+
+	// 1.00 USD is 150 JPY, not 15000
+	result, err := store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+		QuoteID:       quote.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(-100), result.FromEntry.Amount)
+	require.Equal(t, int64(150), result.ToEntry.Amount)
+
+	reversal, err := store.ReverseTx(context.Background(), db.ReverseTxParams{
+		TransferID: result.Transfer.ID,
+		Amount:     50,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "JPY", reversal.Transfer.Currency)
+	require.Equal(t, int64(75), reversal.Transfer.Amount)
+	require.Equal(t, int64(50), reversal.ToEntry.Amount)
+}
+
+func TestTransferTxInvalidQuote(t *testing.T) {
+	store := db.NewStore(testPool)
+	account1 := createRandomAccount(t, "USD")
+	account2 := createRandomAccount(t, "EUR")
+
+	testCases := []struct {
+		name  string
+		quote db.FxQuote
+	}{
+		{
+			name:  "expired",
+			quote: createFxQuote(t, account1.Owner, "USD", "EUR", "0.92", time.Now().Add(-time.Second)),
+		},
+		{
+			name:  "another user",
+			quote: createFxQuote(t, account2.Owner, "USD", "EUR", "0.92", time.Now().Add(time.Minute)),
+		},
+		{
+			name:  "other currencies",
+			quote: createFxQuote(t, account1.Owner, "EUR", "USD", "1.08", time.Now().Add(time.Minute)),
+		},
+		{
+			name:  "unknown",
+			quote: db.FxQuote{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := store.TransferTx(context.Background(), db.TransferTxParams{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        100,
+				QuoteID:       tc.quote.ID,
+			})
+			require.ErrorIs(t, err, db.ErrInvalidQuote)
+		})
+	}
+
+	// no money moved
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+}
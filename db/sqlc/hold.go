@@ -0,0 +1,245 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+)
+
+// Errors returned by CaptureTx and VoidTx when the hold cannot be settled.
+var (
+	ErrHoldNotPending     = errors.New("hold is not pending")
+	ErrHoldExpired        = errors.New("hold expired")
+	ErrCaptureExceedsHold = errors.New("capture exceeds the held amount")
+)
+
+type HoldTxParams struct {
+	AccountID   int32
+	ToAccountID int32
+	Amount      int64
+	Description string
+	Reference   string
+	ExpiresAt   time.Time
+}
+
+type HoldTxResult struct {
+	Hold    Hold
+	Account Account
+}
+
+// HoldTx reserves money on an account for a later capture by the to account.
+// The money does not move, only the available balance of the account goes
+// down by the held amount and the fee of its transfer, so the capture is
+// always covered.
+func (s *SQLStore) HoldTx(ctx context.Context, params HoldTxParams) (HoldTxResult, error) {
+	var result HoldTxResult
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		account, toAccount, err := lockTransferAccounts(ctx, q, params.AccountID, params.ToAccountID)
+		if err != nil {
+			return err
+		}
+
+		schedule, err := feeSchedule(ctx, q, account.Currency)
+		if err != nil {
+			return err
+		}
+		fee := schedule.Fee(params.Amount)
+
+		if err := validateTransfer(account, toAccount, params.Amount+fee); err != nil {
+			return err
+		}
+		// holds are captured without a quote
+		if account.Currency != toAccount.Currency {
+			return fmt.Errorf("%w: account currency %s, to account currency %s", ErrCurrencyMismatch, account.Currency, toAccount.Currency)
+		}
+		// the limits are checked when the money is held, the capture only
+		// moves money that already counts as spent
+		if err := checkTransferLimits(ctx, q, account, params.Amount+fee); err != nil {
+			return err
+		}
+
+		result.Account, err = q.AddAccountHeldAmount(ctx, AddAccountHeldAmountParams{
+			ID:     account.ID,
+			Amount: params.Amount + fee,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Hold, err = q.CreateHold(ctx, CreateHoldParams{
+			AccountID:   account.ID,
+			ToAccountID: toAccount.ID,
+			Amount:      params.Amount,
+			Fee:         fee,
+			Currency:    account.Currency,
+			Description: params.Description,
+			Reference:   params.Reference,
+			ExpiresAt:   pgtype.Timestamptz{Time: params.ExpiresAt, Valid: true},
+		})
+		return err
+	})
+
+	return result, err
+}
+
+type CaptureTxParams struct {
+	HoldID int32
+	// Amount is at most the held amount, zero captures all of it.
+	Amount int64
+}
+
+type CaptureTxResult struct {
+	TransferTxResult
+	Hold Hold
+}
+
+// CaptureTx settles a pending hold with a transfer to its to account. A hold
+// is captured once, whatever is not captured goes back to the available
+// balance. The fee is charged out of what the hold reserved for it, a
+// schedule raised since the hold does not charge more.
+func (s *SQLStore) CaptureTx(ctx context.Context, params CaptureTxParams) (CaptureTxResult, error) {
+	var result CaptureTxResult
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		hold, err := lockPendingHold(ctx, q, params.HoldID)
+		if err != nil {
+			return err
+		}
+
+		if !hold.ExpiresAt.Time.After(time.Now()) {
+			return fmt.Errorf("%w: hold %d expired at %s", ErrHoldExpired, hold.ID, hold.ExpiresAt.Time.Format(time.RFC3339))
+		}
+
+		amount := params.Amount
+		if amount == 0 {
+			amount = hold.Amount
+		}
+		if amount > hold.Amount {
+			return fmt.Errorf("%w: only %d is held by hold %d", ErrCaptureExceedsHold, hold.Amount, hold.ID)
+		}
+
+		_, toAccount, err := lockTransferAccounts(ctx, q, hold.AccountID, hold.ToAccountID)
+		if err != nil {
+			return err
+		}
+
+		fromAccount, err := q.AddAccountHeldAmount(ctx, AddAccountHeldAmountParams{
+			ID:     hold.AccountID,
+			Amount: -(hold.Amount + hold.Fee),
+		})
+		if err != nil {
+			return err
+		}
+
+		schedule, err := feeSchedule(ctx, q, hold.Currency)
+		if err != nil {
+			return err
+		}
+		fee := min(schedule.Fee(amount), hold.Fee)
+
+		if err := validateTransfer(fromAccount, toAccount, amount+fee); err != nil {
+			return err
+		}
+
+		result.TransferTxResult, err = postTransfer(ctx, q, CreateTransferParams{
+			FromAccountID: fromAccount.ID,
+			ToAccountID:   toAccount.ID,
+			Amount:        amount,
+			Fee:           fee,
+			Currency:      hold.Currency,
+			Description:   hold.Description,
+			Reference:     hold.Reference,
+		}, amount)
+		if err != nil {
+			return err
+		}
+
+		result.Hold, err = q.CaptureHold(ctx, CaptureHoldParams{
+			ID:             hold.ID,
+			CapturedAmount: amount,
+			TransferID:     pgtype.Int4{Int32: result.Transfer.ID, Valid: true},
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// VoidTx cancels a pending hold and gives the held amount back to the
+// available balance. A hold past its expiry that was not released yet can
+// still be voided.
+func (s *SQLStore) VoidTx(ctx context.Context, holdID int32) (HoldTxResult, error) {
+	var result HoldTxResult
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		hold, err := lockPendingHold(ctx, q, holdID)
+		if err != nil {
+			return err
+		}
+
+		result, err = releaseHold(ctx, q, hold, utils.HoldStatusVoided)
+		return err
+	})
+
+	return result, err
+}
+
+// ExpireHoldsTx releases up to limit pending holds past their expiry and
+// reports how many it released.
+func (s *SQLStore) ExpireHoldsTx(ctx context.Context, limit int32) (int, error) {
+	var released int
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		holds, err := q.ListExpiredHolds(ctx, limit)
+		if err != nil {
+			return err
+		}
+
+		for _, hold := range holds {
+			if _, err := releaseHold(ctx, q, hold, utils.HoldStatusExpired); err != nil {
+				return err
+			}
+		}
+
+		released = len(holds)
+		return nil
+	})
+
+	return released, err
+}
+
+func lockPendingHold(ctx context.Context, q *Queries, holdID int32) (Hold, error) {
+	hold, err := q.GetHoldForUpdate(ctx, holdID)
+	if err != nil {
+		return Hold{}, err
+	}
+
+	if hold.Status != utils.HoldStatusPending {
+		return Hold{}, fmt.Errorf("%w: hold %d is %s", ErrHoldNotPending, hold.ID, hold.Status)
+	}
+
+	return hold, nil
+}
+
+// releaseHold gives the amount and the fee reserved by a locked pending hold
+// back to the available balance of its account and moves the hold to status.
+func releaseHold(ctx context.Context, q *Queries, hold Hold, status string) (result HoldTxResult, err error) {
+	result.Account, err = q.AddAccountHeldAmount(ctx, AddAccountHeldAmountParams{
+		ID:     hold.AccountID,
+		Amount: -(hold.Amount + hold.Fee),
+	})
+	if err != nil {
+		return
+	}
+
+	result.Hold, err = q.UpdateHoldStatus(ctx, UpdateHoldStatusParams{
+		ID:     hold.ID,
+		Status: status,
+	})
+	return
+}
@@ -0,0 +1,147 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func createHold(t *testing.T, account db.Account, toAccount db.Account, amount int64, expiresAt time.Time) db.Hold {
+	store := db.NewStore(testPool)
+
+	result, err := store.HoldTx(context.Background(), db.HoldTxParams{
+		AccountID:   account.ID,
+		ToAccountID: toAccount.ID,
+		Amount:      amount,
+		Reference:   utils.RandomString(10),
+		ExpiresAt:   expiresAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, utils.HoldStatusPending, result.Hold.Status)
+	require.Equal(t, amount, result.Hold.Amount)
+	require.Equal(t, account.Currency, result.Hold.Currency)
+	require.Equal(t, account.Balance, result.Account.Balance)
+	require.Equal(t, account.HeldAmount+amount, result.Account.HeldAmount)
+	require.Equal(t, account.AvailableBalance-amount, result.Account.AvailableBalance)
+
+	return result.Hold
+}
+
+func TestHoldTx(t *testing.T) {
+	store := db.NewStore(testPool)
+	account1 := createRandomAccount(t, "USD")
+	account2 := createRandomAccount(t, "USD")
+
+	createHold(t, account1, account2, account1.Balance-10, time.Now().Add(time.Hour))
+
+	// held money cannot be spent by a transfer or another hold
+	_, err := store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        11,
+	})
+	require.ErrorIs(t, err, db.ErrInsufficientBalance)
+
+	_, err = store.HoldTx(context.Background(), db.HoldTxParams{
+		AccountID:   account1.ID,
+		ToAccountID: account2.ID,
+		Amount:      11,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, db.ErrInsufficientBalance)
+
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+}
+
+func TestCaptureTx(t *testing.T) {
+	store := db.NewStore(testPool)
+	account1 := createRandomAccount(t, "USD")
+	account2 := createRandomAccount(t, "USD")
+
+	hold := createHold(t, account1, account2, 100, time.Now().Add(time.Hour))
+
+	_, err := store.CaptureTx(context.Background(), db.CaptureTxParams{
+		HoldID: hold.ID,
+		Amount: 101,
+	})
+	require.ErrorIs(t, err, db.ErrCaptureExceedsHold)
+
+	result, err := store.CaptureTx(context.Background(), db.CaptureTxParams{
+		HoldID: hold.ID,
+		Amount: 60,
+	})
+	require.NoError(t, err)
+	require.Equal(t, utils.HoldStatusCaptured, result.Hold.Status)
+	require.Equal(t, int64(60), result.Hold.CapturedAmount)
+	require.Equal(t, result.Transfer.ID, result.Hold.TransferID.Int32)
+	require.Equal(t, int64(60), result.Transfer.Amount)
+	require.Equal(t, hold.Reference, result.Transfer.Reference)
+
+	// the 40 left are released
+	require.Equal(t, account1.Balance-60, result.FromAccount.Balance)
+	require.Zero(t, result.FromAccount.HeldAmount)
+	require.Equal(t, account1.Balance-60, result.FromAccount.AvailableBalance)
+	require.Equal(t, account2.Balance+60, result.ToAccount.Balance)
+
+	_, err = store.CaptureTx(context.Background(), db.CaptureTxParams{HoldID: hold.ID})
+	require.ErrorIs(t, err, db.ErrHoldNotPending)
+
+	_, err = store.VoidTx(context.Background(), hold.ID)
+	require.ErrorIs(t, err, db.ErrHoldNotPending)
+}
+
+func TestVoidTx(t *testing.T) {
+	store := db.NewStore(testPool)
+	account1 := createRandomAccount(t, "USD")
+	account2 := createRandomAccount(t, "USD")
+
+	hold := createHold(t, account1, account2, 100, time.Now().Add(time.Hour))
+
+	result, err := store.VoidTx(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, utils.HoldStatusVoided, result.Hold.Status)
+	require.Zero(t, result.Hold.CapturedAmount)
+	require.Equal(t, account1.Balance, result.Account.Balance)
+	require.Equal(t, account1.Balance, result.Account.AvailableBalance)
+
+	_, err = store.CaptureTx(context.Background(), db.CaptureTxParams{HoldID: hold.ID})
+	require.ErrorIs(t, err, db.ErrHoldNotPending)
+}
+
+func TestExpireHoldsTx(t *testing.T) {
+	store := db.NewStore(testPool)
+	account1 := createRandomAccount(t, "USD")
+	account2 := createRandomAccount(t, "USD")
+
+	hold := createHold(t, account1, account2, 100, time.Now().Add(-time.Second))
+
+	_, err := store.CaptureTx(context.Background(), db.CaptureTxParams{HoldID: hold.ID})
+	require.ErrorIs(t, err, db.ErrHoldExpired)
+
+	// other tests may leave expired holds behind
+	for {
+		released, err := store.ExpireHoldsTx(context.Background(), 100)
+		require.NoError(t, err)
+		if released < 100 {
+			break
+		}
+	}
+
+	expired, err := testQueries.GetHold(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, utils.HoldStatusExpired, expired.Status)
+
+	account, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Zero(t, account.HeldAmount)
+	require.Equal(t, account1.Balance, account.AvailableBalance)
+}
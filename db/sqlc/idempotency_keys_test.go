@@ -0,0 +1,134 @@
+package db_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateIdempotencyKey(t *testing.T) {
+	user := createRandomUser(t)
+
+	params := db.CreateIdempotencyKeyParams{
+		Username:    user.Username,
+		Key:         utils.RandomString(16),
+		RequestHash: utils.RandomString(64),
+		Response:    []byte(`{"transfer":{"id":1}}`),
+		ExpiresAt:   pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+	key, err := testQueries.CreateIdempotencyKey(context.Background(), params)
+	require.NoError(t, err)
+	require.Equal(t, params.Username, key.Username)
+	require.Equal(t, params.Key, key.Key)
+	require.Equal(t, params.RequestHash, key.RequestHash)
+	require.JSONEq(t, string(params.Response), string(key.Response))
+	require.NotZero(t, key.CreatedAt)
+	require.WithinDuration(t, params.ExpiresAt.Time, key.ExpiresAt.Time, time.Millisecond)
+
+	stored, err := testQueries.GetIdempotencyKey(context.Background(), db.GetIdempotencyKeyParams{
+		Username: user.Username,
+		Key:      params.Key,
+	})
+	require.NoError(t, err)
+	require.Equal(t, key, stored)
+
+	// keys are scoped to the user that sent them
+	_, err = testQueries.GetIdempotencyKey(context.Background(), db.GetIdempotencyKeyParams{
+		Username: createRandomUser(t).Username,
+		Key:      params.Key,
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	// an unexpired key is not taken over
+	_, err = testQueries.CreateIdempotencyKey(context.Background(), params)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestExpiredIdempotencyKey(t *testing.T) {
+	user := createRandomUser(t)
+
+	params := db.CreateIdempotencyKeyParams{
+		Username:    user.Username,
+		Key:         utils.RandomString(16),
+		RequestHash: utils.RandomString(64),
+		Response:    []byte(`{"transfer":{"id":1}}`),
+		ExpiresAt:   pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true},
+	}
+	_, err := testQueries.CreateIdempotencyKey(context.Background(), params)
+	require.NoError(t, err)
+
+	// expired keys are not replayed
+	_, err = testQueries.GetIdempotencyKey(context.Background(), db.GetIdempotencyKeyParams{
+		Username: user.Username,
+		Key:      params.Key,
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	// and can be used again
+	params.RequestHash = utils.RandomString(64)
+	params.ExpiresAt = pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true}
+	key, err := testQueries.CreateIdempotencyKey(context.Background(), params)
+	require.NoError(t, err)
+	require.Equal(t, params.RequestHash, key.RequestHash)
+
+	expired := params
+	expired.Key = utils.RandomString(16)
+	expired.ExpiresAt = pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true}
+	_, err = testQueries.CreateIdempotencyKey(context.Background(), expired)
+	require.NoError(t, err)
+
+	err = testQueries.DeleteExpiredIdempotencyKeys(context.Background())
+	require.NoError(t, err)
+
+	var count int
+	err = testPool.QueryRow(context.Background(), "SELECT count(*) FROM idempotency_keys WHERE username = $1", user.Username).Scan(&count)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+}
+
+func TestTransferTxIdempotency(t *testing.T) {
+	store := db.NewStore(testPool)
+	account1 := createRandomAccount(t, "USD")
+	account2 := createRandomAccount(t, "USD")
+
+	params := db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Idempotency: &db.TransferIdempotency{
+			Username:    account1.Owner,
+			Key:         utils.RandomString(16),
+			RequestHash: utils.RandomString(64),
+			Duration:    time.Hour,
+		},
+	}
+
+	result, err := store.TransferTx(context.Background(), params)
+	require.NoError(t, err)
+
+	stored, err := testQueries.GetIdempotencyKey(context.Background(), db.GetIdempotencyKeyParams{
+		Username: params.Idempotency.Username,
+		Key:      params.Idempotency.Key,
+	})
+	require.NoError(t, err)
+
+	var storedResult db.TransferTxResult
+	err = json.Unmarshal(stored.Response, &storedResult)
+	require.NoError(t, err)
+	require.Equal(t, result.Transfer.ID, storedResult.Transfer.ID)
+
+	// the retried transfer is rolled back and the balance only moves once
+	_, err = store.TransferTx(context.Background(), params)
+	require.ErrorIs(t, err, db.ErrIdempotencyKeyExists)
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-10, updatedAccount1.Balance)
+}
@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ErrTransferLimitExceeded is returned when a debit would go over one of the
+// outgoing limits of the account.
+var ErrTransferLimitExceeded = errors.New("transfer limit exceeded")
+
+// AccountLimits are the outgoing limits in effect on an account, in its
+// currency, with what was already spent against them. An invalid limit is no
+// limit.
+type AccountLimits struct {
+	PerTransaction pgtype.Int8
+	Daily          pgtype.Int8
+	Monthly        pgtype.Int8
+	// DailySpent and MonthlySpent sum the debits of the current UTC day and
+	// month. Money held by pending holds counts as spent in both until the
+	// hold is settled.
+	DailySpent   int64
+	MonthlySpent int64
+}
+
+// DailyRemaining returns what can still leave the account today. It reports
+// false when there is no daily limit.
+func (l AccountLimits) DailyRemaining() (int64, bool) {
+	return remainingAllowance(l.Daily, l.DailySpent)
+}
+
+// MonthlyRemaining returns what can still leave the account this month. It
+// reports false when there is no monthly limit.
+func (l AccountLimits) MonthlyRemaining() (int64, bool) {
+	return remainingAllowance(l.Monthly, l.MonthlySpent)
+}
+
+func remainingAllowance(limit pgtype.Int8, spent int64) (int64, bool) {
+	if !limit.Valid {
+		return 0, false
+	}
+	return max(limit.Int64-spent, 0), true
+}
+
+func (l AccountLimits) check(amount int64) error {
+	if l.PerTransaction.Valid && amount > l.PerTransaction.Int64 {
+		return fmt.Errorf("%w: at most %d per transaction", ErrTransferLimitExceeded, l.PerTransaction.Int64)
+	}
+	if remaining, ok := l.DailyRemaining(); ok && amount > remaining {
+		return fmt.Errorf("%w: %d left of the daily limit", ErrTransferLimitExceeded, remaining)
+	}
+	if remaining, ok := l.MonthlyRemaining(); ok && amount > remaining {
+		return fmt.Errorf("%w: %d left of the monthly limit", ErrTransferLimitExceeded, remaining)
+	}
+	return nil
+}
+
+func (l *AccountLimits) spend(amount int64) {
+	l.DailySpent += amount
+	l.MonthlySpent += amount
+}
+
+// GetAccountLimits returns the limits in effect on the account now.
+func (s *SQLStore) GetAccountLimits(ctx context.Context, account Account) (AccountLimits, error) {
+	return accountLimits(ctx, s.Queries, account, time.Now())
+}
+
+// accountLimits resolves the limits of the account. Each limit of the account
+// wins over the one of its owner, which wins over the one of its currency.
+// Called in a transaction the account must be locked, so concurrent debits
+// cannot both fit in the same allowance. An owner has a single account per
+// currency, so the limit of the owner is spent by that account alone.
+func accountLimits(ctx context.Context, q *Queries, account Account, now time.Time) (AccountLimits, error) {
+	var limits AccountLimits
+
+	rows, err := q.ListAccountTransferLimits(ctx, ListAccountTransferLimitsParams{
+		Currency:  account.Currency,
+		Owner:     pgtype.Text{String: account.Owner, Valid: true},
+		AccountID: pgtype.Int4{Int32: account.ID, Valid: true},
+	})
+	if err != nil {
+		return limits, err
+	}
+
+	for _, row := range rows {
+		if row.PerTransaction.Valid {
+			limits.PerTransaction = row.PerTransaction
+		}
+		if row.Daily.Valid {
+			limits.Daily = row.Daily
+		}
+		if row.Monthly.Valid {
+			limits.Monthly = row.Monthly
+		}
+	}
+
+	if !limits.Daily.Valid && !limits.Monthly.Valid {
+		return limits, nil
+	}
+
+	now = now.UTC()
+	totals, err := q.GetAccountDebitTotals(ctx, GetAccountDebitTotalsParams{
+		AccountID:  account.ID,
+		DayStart:   pgtype.Timestamptz{Time: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC), Valid: true},
+		MonthStart: pgtype.Timestamptz{Time: time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC), Valid: true},
+	})
+	if err != nil {
+		return limits, err
+	}
+
+	limits.DailySpent = totals.Daily + account.HeldAmount
+	limits.MonthlySpent = totals.Monthly + account.HeldAmount
+	return limits, nil
+}
+
+// checkTransferLimits fails with ErrTransferLimitExceeded when debiting
+// amount from the locked account would go over one of its limits.
+func checkTransferLimits(ctx context.Context, q *Queries, account Account, amount int64) error {
+	limits, err := accountLimits(ctx, q, account, time.Now())
+	if err != nil {
+		return err
+	}
+	return limits.check(amount)
+}
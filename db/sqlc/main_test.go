@@ -32,8 +32,15 @@ func TestMain(t *testing.M) {
 	defer testPool.Close()
 	existCode := t.Run()
 
-	testQueries.DeleteAllTransfers(ctx)
+	// entries, holds, scheduled runs and batch items reference their transfer
+	testQueries.DeleteAllTransferBatchItems(ctx)
+	testQueries.DeleteAllTransferBatches(ctx)
+	testQueries.DeleteAllScheduledTransferRuns(ctx)
+	testQueries.DeleteAllScheduledTransfers(ctx)
+	testQueries.DeleteAllHolds(ctx)
 	testQueries.DeleteAllEntries(ctx)
+	testQueries.DeleteAllTransfers(ctx)
+	testQueries.DeleteAllTransferLimits(ctx)
 	testQueries.DeleteAllAccounts(ctx)
 
 	os.Exit(existCode)
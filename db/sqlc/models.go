@@ -14,6 +14,24 @@ type Account struct {
 	Balance   int64              `json:"balance"`
 	Currency  string             `json:"currency"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	Status    string             `json:"status"`
+	Kind      string             `json:"kind"`
+	// public account number, clients address accounts by it instead of id
+	Number string `json:"number"`
+	// sum of the pending holds on the account
+	HeldAmount int64 `json:"held_amount"`
+	// balance minus the pending holds, what transfers can spend
+	AvailableBalance int64 `json:"available_balance"`
+}
+
+type Currency struct {
+	Code        string `json:"code"`
+	NumericCode int32  `json:"numeric_code"`
+	// ISO 4217 exponent, amounts are stored in 10^-minor_units of the currency
+	MinorUnits int32  `json:"minor_units"`
+	Symbol     string `json:"symbol"`
+	Name       string `json:"name"`
+	Enabled    bool   `json:"enabled"`
 }
 
 type Entry struct {
@@ -22,6 +40,118 @@ type Entry struct {
 	// can be negative or positive
 	Amount    int64              `json:"amount"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	// the transfer that posted the entry, null on entries older than the column
+	TransferID pgtype.Int4 `json:"transfer_id"`
+}
+
+type FeeSchedule struct {
+	Currency string `json:"currency"`
+	// charged on every transfer, in the minor unit of the currency
+	Flat int64 `json:"flat"`
+	// charged on the amount in basis points, 150 is 1.5%
+	PercentageBps int32 `json:"percentage_bps"`
+	// floor of flat plus percentage, null for none
+	MinFee pgtype.Int8 `json:"min_fee"`
+	// cap of flat plus percentage, null for none
+	MaxFee    pgtype.Int8        `json:"max_fee"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type FxQuote struct {
+	ID           pgtype.UUID        `json:"id"`
+	Username     string             `json:"username"`
+	FromCurrency string             `json:"from_currency"`
+	ToCurrency   string             `json:"to_currency"`
+	Rate         pgtype.Numeric     `json:"rate"`
+	ExpiresAt    pgtype.Timestamptz `json:"expires_at"`
+	UsedAt       pgtype.Timestamptz `json:"used_at"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
+type FxRate struct {
+	BaseCurrency  string             `json:"base_currency"`
+	QuoteCurrency string             `json:"quote_currency"`
+	Rate          pgtype.Numeric     `json:"rate"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+}
+
+type Hold struct {
+	ID int32 `json:"id"`
+	// the account the money is reserved on
+	AccountID int32 `json:"account_id"`
+	// the account credited when the hold is captured
+	ToAccountID    int32  `json:"to_account_id"`
+	Amount         int64  `json:"amount"`
+	Currency       string `json:"currency"`
+	Status         string `json:"status"`
+	CapturedAmount int64  `json:"captured_amount"`
+	// the transfer that captured the hold
+	TransferID  pgtype.Int4        `json:"transfer_id"`
+	Description string             `json:"description"`
+	Reference   string             `json:"reference"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	// reserved on top of amount for the fee of the capture, held_amount of the account counts both
+	Fee int64 `json:"fee"`
+}
+
+type IdempotencyKey struct {
+	Username    string             `json:"username"`
+	Key         string             `json:"key"`
+	RequestHash string             `json:"request_hash"`
+	Response    []byte             `json:"response"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	// a request retried with the key is replayed until then, the key can be reused and is purged afterwards
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+}
+
+type RevokedToken struct {
+	ID        pgtype.UUID        `json:"id"`
+	Username  string             `json:"username"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	RevokedAt pgtype.Timestamptz `json:"revoked_at"`
+}
+
+type ScheduledTransfer struct {
+	ID            int32  `json:"id"`
+	Owner         string `json:"owner"`
+	FromAccountID int32  `json:"from_account_id"`
+	ToAccountID   int32  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	Description   string `json:"description"`
+	Reference     string `json:"reference"`
+	// cron expression of a recurring transfer, empty for a one-off transfer
+	Schedule string `json:"schedule"`
+	// null once a one-off transfer ran
+	NextRunAt pgtype.Timestamptz `json:"next_run_at"`
+	Status    string             `json:"status"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type ScheduledTransferRun struct {
+	ID                  int32 `json:"id"`
+	ScheduledTransferID int32 `json:"scheduled_transfer_id"`
+	// the time the run was due
+	ScheduledAt pgtype.Timestamptz `json:"scheduled_at"`
+	Status      string             `json:"status"`
+	// the transfer made by a succeeded run
+	TransferID pgtype.Int4        `json:"transfer_id"`
+	Error      string             `json:"error"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	// the last time a scheduler took the run, a run still pending long after is taken again
+	ClaimedAt pgtype.Timestamptz `json:"claimed_at"`
+}
+
+type Session struct {
+	ID           pgtype.UUID        `json:"id"`
+	Username     string             `json:"username"`
+	RefreshToken string             `json:"refresh_token"`
+	UserAgent    string             `json:"user_agent"`
+	ClientIp     string             `json:"client_ip"`
+	IsBlocked    bool               `json:"is_blocked"`
+	ExpiresAt    pgtype.Timestamptz `json:"expires_at"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
 }
 
 type Transfer struct {
@@ -31,6 +161,66 @@ type Transfer struct {
 	// must be positive
 	Amount    int64              `json:"amount"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	// set on cross-currency transfers, the to account is credited amount * exchange_rate
+	ExchangeRate pgtype.Numeric `json:"exchange_rate"`
+	// currency of amount, the one of the from account
+	Currency    string `json:"currency"`
+	Description string `json:"description"`
+	// supplied by the client to match the transfer with its own records
+	Reference string `json:"reference"`
+	// set on reversals, the transfer they give the money back for
+	ReversalOf pgtype.Int4 `json:"reversal_of"`
+	// sum of the reversals of the transfer, in its currency
+	ReversedAmount int64 `json:"reversed_amount"`
+	// charged to the from account on top of amount, in its currency
+	Fee int64 `json:"fee"`
+}
+
+type TransferBatch struct {
+	ID             int32  `json:"id"`
+	Owner          string `json:"owner"`
+	FromAccountID  int32  `json:"from_account_id"`
+	Currency       string `json:"currency"`
+	Mode           string `json:"mode"`
+	Status         string `json:"status"`
+	ItemCount      int32  `json:"item_count"`
+	SucceededCount int32  `json:"succeeded_count"`
+	// sum of the amounts of the succeeded items
+	TotalAmount int64              `json:"total_amount"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	// sum of the fees of the succeeded items
+	TotalFee int64 `json:"total_fee"`
+}
+
+type TransferBatchItem struct {
+	ID      int32 `json:"id"`
+	BatchID int32 `json:"batch_id"`
+	// index of the item in the request, from 0
+	Position int32 `json:"position"`
+	// as requested, a failed item may name an account that does not exist
+	ToAccountID int32  `json:"to_account_id"`
+	Amount      int64  `json:"amount"`
+	Description string `json:"description"`
+	Reference   string `json:"reference"`
+	Status      string `json:"status"`
+	// the transfer made by a succeeded item
+	TransferID pgtype.Int4 `json:"transfer_id"`
+	Error      string      `json:"error"`
+}
+
+// outgoing limits of the currency when owner and account_id are null, of a user or of an account otherwise
+type TransferLimit struct {
+	ID        int32       `json:"id"`
+	Currency  string      `json:"currency"`
+	Owner     pgtype.Text `json:"owner"`
+	AccountID pgtype.Int4 `json:"account_id"`
+	// null for no limit, as daily and monthly
+	PerTransaction pgtype.Int8 `json:"per_transaction"`
+	// per UTC calendar day
+	Daily pgtype.Int8 `json:"daily"`
+	// per UTC calendar month
+	Monthly   pgtype.Int8        `json:"monthly"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
 }
 
 type User struct {
@@ -40,4 +230,11 @@ type User struct {
 	Email             string             `json:"email"`
 	PasswordChangedAt pgtype.Timestamptz `json:"password_changed_at"`
 	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	Role              string             `json:"role"`
+	IsFrozen          bool               `json:"is_frozen"`
+}
+
+type UserTokenRevocation struct {
+	Username      string             `json:"username"`
+	RevokedBefore pgtype.Timestamptz `json:"revoked_before"`
 }
@@ -6,25 +6,96 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	AddAccountHeldAmount(ctx context.Context, arg AddAccountHeldAmountParams) (Account, error)
+	AddTransferReversedAmount(ctx context.Context, arg AddTransferReversedAmountParams) (Transfer, error)
+	AdvanceScheduledTransfer(ctx context.Context, arg AdvanceScheduledTransferParams) (ScheduledTransfer, error)
+	BlockSession(ctx context.Context, id pgtype.UUID) error
+	BlockUserSessions(ctx context.Context, username string) error
+	CaptureHold(ctx context.Context, arg CaptureHoldParams) (Hold, error)
+	ClaimStaleScheduledTransferRuns(ctx context.Context, arg ClaimStaleScheduledTransferRunsParams) ([]ScheduledTransferRun, error)
+	CloseAccount(ctx context.Context, id int32) (Account, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateFxQuote(ctx context.Context, arg CreateFxQuoteParams) (FxQuote, error)
+	CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	CreateRevokedToken(ctx context.Context, arg CreateRevokedTokenParams) error
+	CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
+	CreateScheduledTransferRun(ctx context.Context, arg CreateScheduledTransferRunParams) (ScheduledTransferRun, error)
+	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateTransferBatch(ctx context.Context, arg CreateTransferBatchParams) (TransferBatch, error)
+	CreateTransferBatchItem(ctx context.Context, arg CreateTransferBatchItemParams) (TransferBatchItem, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	DeleteAccount(ctx context.Context, id int32) error
 	DeleteAllAccounts(ctx context.Context) error
 	DeleteAllEntries(ctx context.Context) error
+	DeleteAllHolds(ctx context.Context) error
+	DeleteAllScheduledTransferRuns(ctx context.Context) error
+	DeleteAllScheduledTransfers(ctx context.Context) error
+	DeleteAllTransferBatchItems(ctx context.Context) error
+	DeleteAllTransferBatches(ctx context.Context) error
+	DeleteAllTransferLimits(ctx context.Context) error
 	DeleteAllTransfers(ctx context.Context) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context) error
+	DeleteExpiredRevokedTokens(ctx context.Context) error
+	DeleteFeeSchedule(ctx context.Context, currency string) error
+	FinishScheduledTransferRun(ctx context.Context, arg FinishScheduledTransferRunParams) (ScheduledTransferRun, error)
 	GetAccount(ctx context.Context, id int32) (Account, error)
+	GetAccountByNumber(ctx context.Context, number string) (Account, error)
+	GetAccountByOwnerAndCurrency(ctx context.Context, arg GetAccountByOwnerAndCurrencyParams) (Account, error)
+	GetAccountDebitTotals(ctx context.Context, arg GetAccountDebitTotalsParams) (GetAccountDebitTotalsRow, error)
 	GetAccountForUpdate(ctx context.Context, id int32) (Account, error)
+	GetCurrency(ctx context.Context, code string) (Currency, error)
 	GetEntry(ctx context.Context, id int32) (Entry, error)
+	GetFeeAccount(ctx context.Context, currency string) (Account, error)
+	GetFeeSchedule(ctx context.Context, currency string) (FeeSchedule, error)
+	GetFxQuoteForUpdate(ctx context.Context, id pgtype.UUID) (FxQuote, error)
+	GetFxRate(ctx context.Context, arg GetFxRateParams) (FxRate, error)
+	GetHold(ctx context.Context, id int32) (Hold, error)
+	GetHoldForUpdate(ctx context.Context, id int32) (Hold, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetScheduledTransfer(ctx context.Context, id int32) (ScheduledTransfer, error)
+	GetSession(ctx context.Context, id pgtype.UUID) (Session, error)
+	GetSettlementAccount(ctx context.Context, currency string) (Account, error)
 	GetTransfer(ctx context.Context, id int32) (Transfer, error)
+	GetTransferBatch(ctx context.Context, id int32) (TransferBatch, error)
+	GetTransferForUpdate(ctx context.Context, id int32) (Transfer, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetUserPasswordChangedAt(ctx context.Context, username string) (pgtype.Timestamptz, error)
+	IsTokenRevoked(ctx context.Context, arg IsTokenRevokedParams) (bool, error)
+	ListAccountStatement(ctx context.Context, arg ListAccountStatementParams) ([]ListAccountStatementRow, error)
+	ListAccountTransferLimits(ctx context.Context, arg ListAccountTransferLimitsParams) ([]TransferLimit, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListCurrencies(ctx context.Context) ([]Currency, error)
+	ListDueScheduledTransfers(ctx context.Context, limit int32) ([]ScheduledTransfer, error)
+	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListExpiredHolds(ctx context.Context, limit int32) ([]Hold, error)
+	ListFeeSchedules(ctx context.Context) ([]FeeSchedule, error)
+	ListOwnerTransfers(ctx context.Context, arg ListOwnerTransfersParams) ([]Transfer, error)
+	ListScheduledTransferRuns(ctx context.Context, arg ListScheduledTransferRunsParams) ([]ScheduledTransferRun, error)
+	ListScheduledTransfers(ctx context.Context, arg ListScheduledTransfersParams) ([]ScheduledTransfer, error)
+	ListTransferBatchItems(ctx context.Context, batchID int32) ([]TransferBatchItem, error)
+	ListTransferReversals(ctx context.Context, reversalOf pgtype.Int4) ([]Transfer, error)
+	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
+	MarkFxQuoteUsed(ctx context.Context, id pgtype.UUID) error
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error)
+	UpdateHoldStatus(ctx context.Context, arg UpdateHoldStatusParams) (Hold, error)
+	UpdateScheduledTransferStatus(ctx context.Context, arg UpdateScheduledTransferStatusParams) (ScheduledTransfer, error)
+	UpdateUserFrozen(ctx context.Context, arg UpdateUserFrozenParams) (User, error)
+	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error)
+	UpsertFeeSchedule(ctx context.Context, arg UpsertFeeScheduleParams) (FeeSchedule, error)
+	UpsertFxRate(ctx context.Context, arg UpsertFxRateParams) (FxRate, error)
+	UpsertTransferLimit(ctx context.Context, arg UpsertTransferLimitParams) (TransferLimit, error)
+	UpsertUserTokenRevocation(ctx context.Context, arg UpsertUserTokenRevocationParams) error
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+)
+
+// applyQuote checks that the quote can pay for this transfer, marks it used
+// and returns the amount to credit in the currency of the to account along
+// with the rate to record on the transfer.
+func applyQuote(
+	ctx context.Context,
+	q *Queries,
+	quoteID pgtype.UUID,
+	fromAccount Account,
+	toAccount Account,
+	amount int64,
+) (int64, pgtype.Numeric, error) {
+	quote, err := q.GetFxQuoteForUpdate(ctx, quoteID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, pgtype.Numeric{}, fmt.Errorf("%w: quote not found", ErrInvalidQuote)
+		}
+		return 0, pgtype.Numeric{}, err
+	}
+
+	switch {
+	case quote.Username != fromAccount.Owner:
+		return 0, pgtype.Numeric{}, fmt.Errorf("%w: quote belongs to another user", ErrInvalidQuote)
+	case quote.UsedAt.Valid:
+		return 0, pgtype.Numeric{}, fmt.Errorf("%w: quote was already used", ErrInvalidQuote)
+	case !quote.ExpiresAt.Time.After(time.Now()):
+		return 0, pgtype.Numeric{}, fmt.Errorf("%w: quote expired", ErrInvalidQuote)
+	case quote.FromCurrency != fromAccount.Currency || quote.ToCurrency != toAccount.Currency:
+		return 0, pgtype.Numeric{}, fmt.Errorf("%w: quote is for %s to %s", ErrInvalidQuote, quote.FromCurrency, quote.ToCurrency)
+	}
+
+	rate, err := utils.NumericToRat(quote.Rate)
+	if err != nil {
+		return 0, pgtype.Numeric{}, err
+	}
+
+	rate, err = minorUnitRate(ctx, q, rate, fromAccount.Currency, toAccount.Currency)
+	if err != nil {
+		return 0, pgtype.Numeric{}, err
+	}
+
+	creditAmount, err := utils.ConvertAmount(amount, rate)
+	if err != nil {
+		return 0, pgtype.Numeric{}, err
+	}
+	if creditAmount <= 0 {
+		return 0, pgtype.Numeric{}, fmt.Errorf("%w: amount is too small to convert", ErrInvalidQuote)
+	}
+
+	if err := q.MarkFxQuoteUsed(ctx, quote.ID); err != nil {
+		return 0, pgtype.Numeric{}, err
+	}
+
+	return creditAmount, quote.Rate, nil
+}
+
+// minorUnitRate scales rate, which is between the major units of the two
+// currencies, to apply to amounts in their minor units. The minor units come
+// from the currencies table, which the server checks the registry against at
+// startup.
+func minorUnitRate(ctx context.Context, q *Queries, rate *big.Rat, fromCurrency string, toCurrency string) (*big.Rat, error) {
+	from, err := q.GetCurrency(ctx, fromCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := q.GetCurrency(ctx, toCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.MinorUnitRate(rate, from.MinorUnits, to.MinorUnits), nil
+}
@@ -0,0 +1,144 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+)
+
+// Errors returned by ReverseTx when the transfer cannot be given back.
+var (
+	ErrTransferNotReversible   = errors.New("transfer cannot be reversed")
+	ErrReversalExceedsTransfer = errors.New("reversal exceeds the transfer amount")
+)
+
+type ReverseTxParams struct {
+	TransferID int32
+	// Amount is in the currency of the original transfer. Zero reverses
+	// whatever was not reversed yet.
+	Amount      int64
+	Description string
+}
+
+type ReverseTxResult struct {
+	TransferTxResult
+	// OriginalTransfer is the reversed transfer with its reversed amount
+	// updated.
+	OriginalTransfer Transfer
+}
+
+// ReverseTx gives back all or part of a transfer with a new transfer in the
+// opposite direction linked to the original one. The reversals of a transfer
+// never add up to more than its amount, so it cannot be reversed twice.
+func (s *SQLStore) ReverseTx(ctx context.Context, params ReverseTxParams) (ReverseTxResult, error) {
+	var result ReverseTxResult
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		// the lock on the original transfer serializes concurrent reversals of it
+		original, err := q.GetTransferForUpdate(ctx, params.TransferID)
+		if err != nil {
+			return err
+		}
+
+		if original.ReversalOf.Valid {
+			return fmt.Errorf("%w: transfer %d is itself a reversal", ErrTransferNotReversible, original.ID)
+		}
+
+		remaining := original.Amount - original.ReversedAmount
+		amount := params.Amount
+		if amount == 0 {
+			amount = remaining
+		}
+		if remaining == 0 {
+			return fmt.Errorf("%w: transfer %d was already fully reversed", ErrReversalExceedsTransfer, original.ID)
+		}
+		if amount > remaining {
+			return fmt.Errorf("%w: only %d of transfer %d is left to reverse", ErrReversalExceedsTransfer, remaining, original.ID)
+		}
+
+		// the money goes back from the account that received it
+		fromAccount, toAccount, err := lockTransferAccounts(ctx, q, original.ToAccountID, original.FromAccountID)
+		if err != nil {
+			return err
+		}
+
+		reversal := CreateTransferParams{
+			FromAccountID: fromAccount.ID,
+			ToAccountID:   toAccount.ID,
+			Amount:        amount,
+			Currency:      fromAccount.Currency,
+			Description:   params.Description,
+			Reference:     original.Reference,
+			ReversalOf:    pgtype.Int4{Int32: original.ID, Valid: true},
+		}
+		if reversal.Description == "" {
+			reversal.Description = fmt.Sprintf("reversal of transfer %d", original.ID)
+		}
+
+		if original.ExchangeRate.Valid {
+			reversal.Amount, reversal.ExchangeRate, err = reverseRate(ctx, q, original.ExchangeRate, toAccount.Currency, fromAccount.Currency, amount)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := validateTransfer(fromAccount, toAccount, reversal.Amount); err != nil {
+			return err
+		}
+
+		result.TransferTxResult, err = postTransfer(ctx, q, reversal, amount)
+		if err != nil {
+			return err
+		}
+
+		result.OriginalTransfer, err = q.AddTransferReversedAmount(ctx, AddTransferReversedAmountParams{
+			ID:     original.ID,
+			Amount: amount,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// reverseRate returns what to debit from the to account of a cross-currency
+// transfer to give amount back at the rate of the transfer, along with the
+// inverse rate to record on the reversal. fromCurrency and toCurrency are the
+// ones of the original transfer.
+func reverseRate(
+	ctx context.Context,
+	q *Queries,
+	exchangeRate pgtype.Numeric,
+	fromCurrency string,
+	toCurrency string,
+	amount int64,
+) (int64, pgtype.Numeric, error) {
+	rate, err := utils.NumericToRat(exchangeRate)
+	if err != nil {
+		return 0, pgtype.Numeric{}, err
+	}
+
+	minorRate, err := minorUnitRate(ctx, q, rate, fromCurrency, toCurrency)
+	if err != nil {
+		return 0, pgtype.Numeric{}, err
+	}
+
+	debitAmount, err := utils.ConvertAmount(amount, minorRate)
+	if err != nil {
+		return 0, pgtype.Numeric{}, err
+	}
+	if debitAmount <= 0 {
+		return 0, pgtype.Numeric{}, fmt.Errorf("%w: amount is too small to convert", ErrTransferNotReversible)
+	}
+
+	inverse, err := utils.RatToNumeric(new(big.Rat).Inv(rate))
+	if err != nil {
+		return 0, pgtype.Numeric{}, err
+	}
+
+	return debitAmount, inverse, nil
+}
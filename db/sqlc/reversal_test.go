@@ -0,0 +1,147 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReverseTx(t *testing.T) {
+	store := db.NewStore(testPool)
+	account1 := createRandomAccount(t, "USD")
+	account2 := createRandomAccount(t, "USD")
+
+	transfer, err := store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Reference:     utils.RandomString(10),
+	})
+	require.NoError(t, err)
+
+	// partial refund
+	result, err := store.ReverseTx(context.Background(), db.ReverseTxParams{
+		TransferID: transfer.Transfer.ID,
+		Amount:     4,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account2.ID, result.Transfer.FromAccountID)
+	require.Equal(t, account1.ID, result.Transfer.ToAccountID)
+	require.Equal(t, int64(4), result.Transfer.Amount)
+	require.Equal(t, pgtype.Int4{Int32: transfer.Transfer.ID, Valid: true}, result.Transfer.ReversalOf)
+	require.Equal(t, transfer.Transfer.Reference, result.Transfer.Reference)
+	require.NotEmpty(t, result.Transfer.Description)
+	require.Equal(t, int64(-4), result.FromEntry.Amount)
+	require.Equal(t, int64(4), result.ToEntry.Amount)
+	require.Equal(t, account1.Balance-6, result.ToAccount.Balance)
+	require.Equal(t, account2.Balance+6, result.FromAccount.Balance)
+	require.Equal(t, int64(4), result.OriginalTransfer.ReversedAmount)
+
+	// more than what is left
+	_, err = store.ReverseTx(context.Background(), db.ReverseTxParams{
+		TransferID: transfer.Transfer.ID,
+		Amount:     7,
+	})
+	require.ErrorIs(t, err, db.ErrReversalExceedsTransfer)
+
+	// the rest
+	result, err = store.ReverseTx(context.Background(), db.ReverseTxParams{
+		TransferID: transfer.Transfer.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(6), result.Transfer.Amount)
+	require.Equal(t, account1.Balance, result.ToAccount.Balance)
+	require.Equal(t, account2.Balance, result.FromAccount.Balance)
+	require.Equal(t, int64(10), result.OriginalTransfer.ReversedAmount)
+
+	// nothing left
+	_, err = store.ReverseTx(context.Background(), db.ReverseTxParams{
+		TransferID: transfer.Transfer.ID,
+	})
+	require.ErrorIs(t, err, db.ErrReversalExceedsTransfer)
+
+	// a reversal cannot be reversed
+	_, err = store.ReverseTx(context.Background(), db.ReverseTxParams{
+		TransferID: result.Transfer.ID,
+	})
+	require.ErrorIs(t, err, db.ErrTransferNotReversible)
+
+	reversals, err := testQueries.ListTransferReversals(context.Background(), pgtype.Int4{Int32: transfer.Transfer.ID, Valid: true})
+	require.NoError(t, err)
+	require.Len(t, reversals, 2)
+}
+
+func TestReverseTxWithQuote(t *testing.T) {
+	store := db.NewStore(testPool)
+	account1 := createRandomAccount(t, "USD")
+	account2 := createRandomAccount(t, "EUR")
+
+	quote := createFxQuote(t, account1.Owner, "USD", "EUR", "0.92", time.Now().Add(time.Minute))
+
+	transfer, err := store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+		QuoteID:       quote.ID,
+	})
+	require.NoError(t, err)
+
+	// the sender gets back the dollars, the recipient pays them at the rate
+	// of the transfer
+	result, err := store.ReverseTx(context.Background(), db.ReverseTxParams{
+		TransferID: transfer.Transfer.ID,
+		Amount:     50,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "EUR", result.Transfer.Currency)
+	require.Equal(t, int64(46), result.Transfer.Amount)
+	require.Equal(t, int64(-46), result.FromEntry.Amount)
+	require.Equal(t, int64(50), result.ToEntry.Amount)
+	require.Equal(t, account1.Balance-50, result.ToAccount.Balance)
+	require.Equal(t, account2.Balance+46, result.FromAccount.Balance)
+	require.Equal(t, int64(50), result.OriginalTransfer.ReversedAmount)
+}
+
+func TestReverseTxConcurrent(t *testing.T) {
+	store := db.NewStore(testPool)
+	account1 := createRandomAccount(t, "USD")
+	account2 := createRandomAccount(t, "USD")
+
+	transfer, err := store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	num := 3
+	errs := make(chan error, num)
+	for i := 0; i < num; i++ {
+		go func() {
+			_, err := store.ReverseTx(context.Background(), db.ReverseTxParams{
+				TransferID: transfer.Transfer.ID,
+			})
+			errs <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < num; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, db.ErrReversalExceedsTransfer)
+	}
+	require.Equal(t, 1, succeeded)
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+}
@@ -0,0 +1,63 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsTokenRevoked(t *testing.T) {
+	user := createRandomUser(t)
+	tokenID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	issuedAt := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+
+	params := db.IsTokenRevokedParams{
+		ID:       tokenID,
+		Username: user.Username,
+		IssuedAt: issuedAt,
+	}
+
+	revoked, err := testQueries.IsTokenRevoked(context.Background(), params)
+	require.NoError(t, err)
+	require.False(t, revoked)
+
+	err = testQueries.CreateRevokedToken(context.Background(), db.CreateRevokedTokenParams{
+		ID:        tokenID,
+		Username:  user.Username,
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(time.Minute), Valid: true},
+	})
+	require.NoError(t, err)
+
+	revoked, err = testQueries.IsTokenRevoked(context.Background(), params)
+	require.NoError(t, err)
+	require.True(t, revoked)
+}
+
+func TestUpsertUserTokenRevocation(t *testing.T) {
+	user := createRandomUser(t)
+	params := db.IsTokenRevokedParams{
+		ID:       pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Username: user.Username,
+		IssuedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}
+
+	err := testQueries.UpsertUserTokenRevocation(context.Background(), db.UpsertUserTokenRevocationParams{
+		Username:      user.Username,
+		RevokedBefore: pgtype.Timestamptz{Time: time.Now().Add(time.Second), Valid: true},
+	})
+	require.NoError(t, err)
+
+	revoked, err := testQueries.IsTokenRevoked(context.Background(), params)
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	params.IssuedAt = pgtype.Timestamptz{Time: time.Now().Add(time.Minute), Valid: true}
+	revoked, err = testQueries.IsTokenRevoked(context.Background(), params)
+	require.NoError(t, err)
+	require.False(t, revoked)
+}
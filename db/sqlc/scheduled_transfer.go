@@ -0,0 +1,140 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+)
+
+type ScheduledTransferClaim struct {
+	ScheduledTransfer ScheduledTransfer
+	Run               ScheduledTransferRun
+}
+
+// ClaimScheduledTransfersTx takes up to limit due scheduled transfers, moves
+// each to its next run, or completes it when it does not recur, and records
+// a pending run for the caller to execute. Once committed the claimed runs
+// are no longer due, so replicas claiming at the same time never get the
+// same run.
+func (s *SQLStore) ClaimScheduledTransfersTx(ctx context.Context, limit int32) ([]ScheduledTransferClaim, error) {
+	var claims []ScheduledTransferClaim
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		due, err := q.ListDueScheduledTransfers(ctx, limit)
+		if err != nil {
+			return err
+		}
+
+		// schedules are in UTC, Next evaluates them in the location of now
+		now := time.Now().UTC()
+		for _, scheduled := range due {
+			advance := AdvanceScheduledTransferParams{
+				ID:     scheduled.ID,
+				Status: utils.ScheduledTransferStatusCompleted,
+			}
+
+			// runs missed while no scheduler was up are made once, the
+			// next run is counted from now
+			if scheduled.Schedule != "" {
+				schedule, err := utils.ParseCron(scheduled.Schedule)
+				if err != nil {
+					// a bad schedule must not hold up the other due
+					// transfers by failing the claim
+					if err := pauseScheduledTransfer(ctx, q, scheduled, err); err != nil {
+						return err
+					}
+					continue
+				}
+				if next := schedule.Next(now); !next.IsZero() {
+					advance.Status = utils.ScheduledTransferStatusActive
+					advance.NextRunAt = pgtype.Timestamptz{Time: next, Valid: true}
+				}
+			}
+
+			if _, err := q.AdvanceScheduledTransfer(ctx, advance); err != nil {
+				return err
+			}
+
+			run, err := q.CreateScheduledTransferRun(ctx, CreateScheduledTransferRunParams{
+				ScheduledTransferID: scheduled.ID,
+				ScheduledAt:         scheduled.NextRunAt,
+			})
+			if err != nil {
+				return err
+			}
+
+			claims = append(claims, ScheduledTransferClaim{
+				ScheduledTransfer: scheduled,
+				Run:               run,
+			})
+		}
+
+		return nil
+	})
+
+	return claims, err
+}
+
+// ClaimStaleScheduledTransferRunsTx takes again up to limit runs claimed
+// before claimedBefore and still pending, left behind by a scheduler that
+// stopped before recording their outcome. The caller must execute them so
+// that a run already made is not made twice.
+func (s *SQLStore) ClaimStaleScheduledTransferRunsTx(ctx context.Context, claimedBefore time.Time, limit int32) ([]ScheduledTransferClaim, error) {
+	var claims []ScheduledTransferClaim
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		runs, err := q.ClaimStaleScheduledTransferRuns(ctx, ClaimStaleScheduledTransferRunsParams{
+			ClaimedBefore: pgtype.Timestamptz{Time: claimedBefore, Valid: true},
+			Limit:         limit,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, run := range runs {
+			scheduled, err := q.GetScheduledTransfer(ctx, run.ScheduledTransferID)
+			if err != nil {
+				return err
+			}
+
+			claims = append(claims, ScheduledTransferClaim{
+				ScheduledTransfer: scheduled,
+				Run:               run,
+			})
+		}
+
+		return nil
+	})
+
+	return claims, err
+}
+
+// pauseScheduledTransfer takes a scheduled transfer that cannot run out of
+// the due ones, with a failed run telling its owner why.
+func pauseScheduledTransfer(ctx context.Context, q *Queries, scheduled ScheduledTransfer, reason error) error {
+	_, err := q.AdvanceScheduledTransfer(ctx, AdvanceScheduledTransferParams{
+		ID:        scheduled.ID,
+		Status:    utils.ScheduledTransferStatusPaused,
+		NextRunAt: scheduled.NextRunAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	run, err := q.CreateScheduledTransferRun(ctx, CreateScheduledTransferRunParams{
+		ScheduledTransferID: scheduled.ID,
+		ScheduledAt:         scheduled.NextRunAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = q.FinishScheduledTransferRun(ctx, FinishScheduledTransferRunParams{
+		ID:     run.ID,
+		Status: utils.ScheduledRunStatusFailed,
+		Error:  reason.Error(),
+	})
+	return err
+}
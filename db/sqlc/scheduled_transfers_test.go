@@ -0,0 +1,273 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func createScheduledTransfer(t *testing.T, from db.Account, to db.Account, schedule string, nextRunAt time.Time) db.ScheduledTransfer {
+	params := db.CreateScheduledTransferParams{
+		Owner:         from.Owner,
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        10,
+		Currency:      from.Currency,
+		Reference:     utils.RandomString(10),
+		Schedule:      schedule,
+		NextRunAt:     pgtype.Timestamptz{Time: nextRunAt, Valid: true},
+	}
+
+	scheduled, err := testQueries.CreateScheduledTransfer(context.Background(), params)
+	require.NoError(t, err)
+	require.Equal(t, params.Owner, scheduled.Owner)
+	require.Equal(t, params.Amount, scheduled.Amount)
+	require.Equal(t, params.Schedule, scheduled.Schedule)
+	require.Equal(t, utils.ScheduledTransferStatusActive, scheduled.Status)
+	require.WithinDuration(t, nextRunAt, scheduled.NextRunAt.Time, time.Second)
+
+	return scheduled
+}
+
+// claimScheduledTransfer claims due transfers until the one with id comes
+// up, other tests may leave due transfers behind.
+func claimScheduledTransfer(t *testing.T, store db.Store, id int32) (db.ScheduledTransferClaim, bool) {
+	for {
+		claims, err := store.ClaimScheduledTransfersTx(context.Background(), 50)
+		require.NoError(t, err)
+
+		for _, claim := range claims {
+			if claim.ScheduledTransfer.ID == id {
+				return claim, true
+			}
+		}
+		if len(claims) == 0 {
+			return db.ScheduledTransferClaim{}, false
+		}
+	}
+}
+
+func TestClaimScheduledTransfersTxOneOff(t *testing.T) {
+	store := db.NewStore(testPool)
+	account1 := createRandomAccount(t, "USD")
+	account2 := createRandomAccount(t, "USD")
+
+	dueAt := time.Now().Add(-time.Minute)
+	scheduled := createScheduledTransfer(t, account1, account2, "", dueAt)
+
+	claim, ok := claimScheduledTransfer(t, store, scheduled.ID)
+	require.True(t, ok)
+	require.Equal(t, utils.ScheduledRunStatusPending, claim.Run.Status)
+	require.WithinDuration(t, dueAt, claim.Run.ScheduledAt.Time, time.Second)
+
+	completed, err := testQueries.GetScheduledTransfer(context.Background(), scheduled.ID)
+	require.NoError(t, err)
+	require.Equal(t, utils.ScheduledTransferStatusCompleted, completed.Status)
+	require.False(t, completed.NextRunAt.Valid)
+
+	// a claimed run is not due anymore
+	_, ok = claimScheduledTransfer(t, store, scheduled.ID)
+	require.False(t, ok)
+}
+
+// claimStaleRun claims the runs claimed before claimedBefore until the one
+// with id comes up, other tests may leave pending runs behind.
+func claimStaleRun(t *testing.T, store db.Store, claimedBefore time.Time, id int32) (db.ScheduledTransferClaim, bool) {
+	for {
+		claims, err := store.ClaimStaleScheduledTransferRunsTx(context.Background(), claimedBefore, 50)
+		require.NoError(t, err)
+
+		for _, claim := range claims {
+			if claim.Run.ID == id {
+				return claim, true
+			}
+		}
+		if len(claims) == 0 {
+			return db.ScheduledTransferClaim{}, false
+		}
+	}
+}
+
+func TestClaimStaleScheduledTransferRunsTx(t *testing.T) {
+	store := db.NewStore(testPool)
+	account1 := createRandomAccount(t, "USD")
+	account2 := createRandomAccount(t, "USD")
+
+	scheduled := createScheduledTransfer(t, account1, account2, "", time.Now().Add(-time.Minute))
+	claim, ok := claimScheduledTransfer(t, store, scheduled.ID)
+	require.True(t, ok)
+
+	// the run was just claimed, it is not stale yet
+	_, ok = claimStaleRun(t, store, time.Now().Add(-time.Minute), claim.Run.ID)
+	require.False(t, ok)
+
+	stale, ok := claimStaleRun(t, store, time.Now(), claim.Run.ID)
+	require.True(t, ok)
+	require.Equal(t, scheduled.ID, stale.ScheduledTransfer.ID)
+	require.Equal(t, utils.ScheduledRunStatusPending, stale.Run.Status)
+	require.True(t, stale.Run.ClaimedAt.Time.After(claim.Run.ClaimedAt.Time))
+
+	// a finished run is never stale
+	_, err := testQueries.FinishScheduledTransferRun(context.Background(), db.FinishScheduledTransferRunParams{
+		ID:     claim.Run.ID,
+		Status: utils.ScheduledRunStatusFailed,
+		Error:  "failed",
+	})
+	require.NoError(t, err)
+
+	_, ok = claimStaleRun(t, store, time.Now(), claim.Run.ID)
+	require.False(t, ok)
+}
+
+func TestClaimScheduledTransfersTxRecurring(t *testing.T) {
+	store := db.NewStore(testPool)
+	account1 := createRandomAccount(t, "USD")
+	account2 := createRandomAccount(t, "USD")
+
+	scheduled := createScheduledTransfer(t, account1, account2, "0 9 1 * *", time.Now().Add(-time.Minute))
+
+	_, ok := claimScheduledTransfer(t, store, scheduled.ID)
+	require.True(t, ok)
+
+	advanced, err := testQueries.GetScheduledTransfer(context.Background(), scheduled.ID)
+	require.NoError(t, err)
+	require.Equal(t, utils.ScheduledTransferStatusActive, advanced.Status)
+	require.True(t, advanced.NextRunAt.Time.After(time.Now()))
+	require.Equal(t, 1, advanced.NextRunAt.Time.UTC().Day())
+	require.Equal(t, 9, advanced.NextRunAt.Time.UTC().Hour())
+
+	runs := mustListRuns(t, scheduled.ID)
+	require.Len(t, runs, 1)
+
+	run, err := testQueries.FinishScheduledTransferRun(context.Background(), db.FinishScheduledTransferRunParams{
+		ID:     runs[0].ID,
+		Status: utils.ScheduledRunStatusFailed,
+		Error:  db.ErrInsufficientBalance.Error(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, utils.ScheduledRunStatusFailed, run.Status)
+	require.False(t, run.TransferID.Valid)
+}
+
+func TestClaimScheduledTransfersTxInvalidSchedule(t *testing.T) {
+	store := db.NewStore(testPool)
+	account1 := createRandomAccount(t, "USD")
+	account2 := createRandomAccount(t, "USD")
+
+	invalid := createScheduledTransfer(t, account1, account2, "not a schedule", time.Now().Add(-2*time.Minute))
+	valid := createScheduledTransfer(t, account1, account2, "", time.Now().Add(-time.Minute))
+
+	// the invalid schedule does not fail the claim of the other
+	_, ok := claimScheduledTransfer(t, store, valid.ID)
+	require.True(t, ok)
+
+	paused, err := testQueries.GetScheduledTransfer(context.Background(), invalid.ID)
+	require.NoError(t, err)
+	require.Equal(t, utils.ScheduledTransferStatusPaused, paused.Status)
+
+	runs := mustListRuns(t, invalid.ID)
+	require.Len(t, runs, 1)
+	require.Equal(t, utils.ScheduledRunStatusFailed, runs[0].Status)
+	require.NotEmpty(t, runs[0].Error)
+}
+
+func TestClaimScheduledTransfersTxConcurrent(t *testing.T) {
+	store := db.NewStore(testPool)
+	account1 := createRandomAccount(t, "USD")
+	account2 := createRandomAccount(t, "USD")
+
+	scheduled := createScheduledTransfer(t, account1, account2, "", time.Now().Add(-time.Minute))
+
+	num := 5
+	claimed := make(chan int, num)
+	for i := 0; i < num; i++ {
+		go func() {
+			claims, err := store.ClaimScheduledTransfersTx(context.Background(), 50)
+			require.NoError(t, err)
+
+			count := 0
+			for _, claim := range claims {
+				if claim.ScheduledTransfer.ID == scheduled.ID {
+					count++
+				}
+			}
+			claimed <- count
+		}()
+	}
+
+	total := 0
+	for i := 0; i < num; i++ {
+		total += <-claimed
+	}
+	require.Equal(t, 1, total)
+	require.Len(t, mustListRuns(t, scheduled.ID), 1)
+}
+
+func TestUpdateScheduledTransferStatus(t *testing.T) {
+	account1 := createRandomAccount(t, "USD")
+	account2 := createRandomAccount(t, "USD")
+
+	scheduled := createScheduledTransfer(t, account1, account2, "@daily", time.Now().Add(time.Hour))
+
+	paused, err := testQueries.UpdateScheduledTransferStatus(context.Background(), db.UpdateScheduledTransferStatusParams{
+		ID:            scheduled.ID,
+		Status:        utils.ScheduledTransferStatusPaused,
+		CurrentStatus: utils.ScheduledTransferStatusActive,
+	})
+	require.NoError(t, err)
+	require.Equal(t, utils.ScheduledTransferStatusPaused, paused.Status)
+	require.Equal(t, scheduled.NextRunAt, paused.NextRunAt)
+
+	// the status changed since it was read
+	_, err = testQueries.UpdateScheduledTransferStatus(context.Background(), db.UpdateScheduledTransferStatusParams{
+		ID:            scheduled.ID,
+		Status:        utils.ScheduledTransferStatusCancelled,
+		CurrentStatus: utils.ScheduledTransferStatusActive,
+	})
+	require.Error(t, err)
+
+	nextRunAt := time.Now().Add(2 * time.Hour)
+	resumed, err := testQueries.UpdateScheduledTransferStatus(context.Background(), db.UpdateScheduledTransferStatusParams{
+		ID:            scheduled.ID,
+		Status:        utils.ScheduledTransferStatusActive,
+		CurrentStatus: utils.ScheduledTransferStatusPaused,
+		NextRunAt:     pgtype.Timestamptz{Time: nextRunAt, Valid: true},
+	})
+	require.NoError(t, err)
+	require.WithinDuration(t, nextRunAt, resumed.NextRunAt.Time, time.Second)
+}
+
+func TestListScheduledTransfers(t *testing.T) {
+	account1 := createRandomAccount(t, "USD")
+	account2 := createRandomAccount(t, "USD")
+
+	for i := 0; i < 3; i++ {
+		createScheduledTransfer(t, account1, account2, "@monthly", time.Now().Add(time.Hour))
+	}
+
+	scheduled, err := testQueries.ListScheduledTransfers(context.Background(), db.ListScheduledTransfersParams{
+		Owner:  account1.Owner,
+		Limit:  5,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+	require.Len(t, scheduled, 3)
+	for _, item := range scheduled {
+		require.Equal(t, account1.Owner, item.Owner)
+	}
+}
+
+func mustListRuns(t *testing.T, scheduledTransferID int32) []db.ScheduledTransferRun {
+	runs, err := testQueries.ListScheduledTransferRuns(context.Background(), db.ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduledTransferID,
+		Limit:               10,
+		Offset:              0,
+	})
+	require.NoError(t, err)
+	return runs
+}
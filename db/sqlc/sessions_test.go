@@ -0,0 +1,54 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomSession(t *testing.T) db.Session {
+	user := createRandomUser(t)
+	params := db.CreateSessionParams{
+		ID:           pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Username:     user.Username,
+		RefreshToken: utils.RandomString(32),
+		UserAgent:    "go-test",
+		ClientIp:     "127.0.0.1",
+		IsBlocked:    false,
+		ExpiresAt:    pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+
+	session, err := testQueries.CreateSession(context.Background(), params)
+	require.NoError(t, err)
+	require.Equal(t, params.ID, session.ID)
+	require.Equal(t, params.Username, session.Username)
+	require.Equal(t, params.RefreshToken, session.RefreshToken)
+	require.Equal(t, params.UserAgent, session.UserAgent)
+	require.Equal(t, params.ClientIp, session.ClientIp)
+	require.False(t, session.IsBlocked)
+	require.WithinDuration(t, params.ExpiresAt.Time, session.ExpiresAt.Time, time.Second)
+	require.NotZero(t, session.CreatedAt)
+
+	return session
+}
+
+func TestCreateSession(t *testing.T) {
+	createRandomSession(t)
+}
+
+func TestGetSession(t *testing.T) {
+	session1 := createRandomSession(t)
+	session2, err := testQueries.GetSession(context.Background(), session1.ID)
+	require.NoError(t, err)
+	require.Equal(t, session1.ID, session2.ID)
+	require.Equal(t, session1.Username, session2.Username)
+	require.Equal(t, session1.RefreshToken, session2.RefreshToken)
+	require.Equal(t, session1.IsBlocked, session2.IsBlocked)
+	require.Equal(t, session1.ExpiresAt, session2.ExpiresAt)
+}
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+)
+
+type DepositTxParams struct {
+	AccountID int32
+	Amount    int64
+}
+
+// DepositTx funds a customer account from the settlement account of its
+// currency. The settlement account goes negative by the same amount, so the
+// entries of the ledger always sum to zero.
+func (s *SQLStore) DepositTx(ctx context.Context, params DepositTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		settlementAccount, err := settlementAccountFor(ctx, q, params.AccountID)
+		if err != nil {
+			return err
+		}
+
+		settlementAccount, account, err := lockTransferAccounts(ctx, q, settlementAccount.ID, params.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if err := validateCustomerAccount(account); err != nil {
+			return err
+		}
+
+		result, err = postTransfer(ctx, q, CreateTransferParams{
+			FromAccountID: settlementAccount.ID,
+			ToAccountID:   account.ID,
+			Amount:        params.Amount,
+			Currency:      account.Currency,
+		}, params.Amount)
+		return err
+	})
+
+	return result, err
+}
+
+type WithdrawTxParams struct {
+	AccountID int32
+	Amount    int64
+}
+
+// WithdrawTx pays money out of a customer account into the settlement
+// account of its currency.
+func (s *SQLStore) WithdrawTx(ctx context.Context, params WithdrawTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		settlementAccount, err := settlementAccountFor(ctx, q, params.AccountID)
+		if err != nil {
+			return err
+		}
+
+		account, settlementAccount, err := lockTransferAccounts(ctx, q, params.AccountID, settlementAccount.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := validateCustomerAccount(account); err != nil {
+			return err
+		}
+
+		if account.AvailableBalance < params.Amount {
+			return ErrInsufficientBalance
+		}
+
+		result, err = postTransfer(ctx, q, CreateTransferParams{
+			FromAccountID: account.ID,
+			ToAccountID:   settlementAccount.ID,
+			Amount:        params.Amount,
+			Currency:      account.Currency,
+		}, params.Amount)
+		return err
+	})
+
+	return result, err
+}
+
+// settlementAccountFor finds the settlement account in the currency of the
+// given account. Neither account is locked yet.
+func settlementAccountFor(ctx context.Context, q *Queries, accountID int32) (Account, error) {
+	account, err := q.GetAccount(ctx, accountID)
+	if err != nil {
+		return Account{}, err
+	}
+
+	return q.GetSettlementAccount(ctx, account.Currency)
+}
@@ -0,0 +1,66 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDepositTx(t *testing.T) {
+	store := db.NewStore(testPool)
+	account := createRandomAccount(t, "USD")
+
+	settlementAccount, err := testQueries.GetSettlementAccount(context.Background(), "USD")
+	require.NoError(t, err)
+	require.Equal(t, utils.AccountKindSettlement, settlementAccount.Kind)
+
+	result, err := store.DepositTx(context.Background(), db.DepositTxParams{
+		AccountID: account.ID,
+		Amount:    100,
+	})
+	require.NoError(t, err)
+	require.Equal(t, settlementAccount.ID, result.Transfer.FromAccountID)
+	require.Equal(t, account.ID, result.Transfer.ToAccountID)
+	require.Equal(t, int64(-100), result.FromEntry.Amount)
+	require.Equal(t, int64(100), result.ToEntry.Amount)
+	require.Equal(t, account.Balance+100, result.ToAccount.Balance)
+	require.Equal(t, settlementAccount.Balance-100, result.FromAccount.Balance)
+}
+
+func TestWithdrawTx(t *testing.T) {
+	store := db.NewStore(testPool)
+	account := createRandomAccount(t, "USD")
+
+	result, err := store.WithdrawTx(context.Background(), db.WithdrawTxParams{
+		AccountID: account.ID,
+		Amount:    account.Balance,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.ID, result.Transfer.FromAccountID)
+	require.Equal(t, utils.AccountKindSettlement, result.ToAccount.Kind)
+	require.Zero(t, result.FromAccount.Balance)
+
+	_, err = store.WithdrawTx(context.Background(), db.WithdrawTxParams{
+		AccountID: account.ID,
+		Amount:    1,
+	})
+	require.ErrorIs(t, err, db.ErrInsufficientBalance)
+}
+
+func TestTransferTxToSettlementAccount(t *testing.T) {
+	store := db.NewStore(testPool)
+	account := createRandomAccount(t, "USD")
+
+	settlementAccount, err := testQueries.GetSettlementAccount(context.Background(), "USD")
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account.ID,
+		ToAccountID:   settlementAccount.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, db.ErrNotCustomerAccount)
+}
@@ -2,16 +2,47 @@ package db
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mohammad19khodaei/simple_bank/utils"
 )
 
+// Errors returned by TransferTx when the transfer breaks a rule checked on
+// the locked accounts. Callers can match them with errors.Is.
+var (
+	ErrAccountNotActive    = errors.New("account is not active")
+	ErrCurrencyMismatch    = errors.New("currency mismatch")
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	ErrNotCustomerAccount  = errors.New("not a customer account")
+	ErrInvalidQuote        = errors.New("invalid exchange rate quote")
+	ErrSameAccount         = errors.New("cannot transfer to the from account")
+)
+
+// ErrIdempotencyKeyExists is returned by TransferTx when another request
+// already committed a transfer under the same idempotency key and the key has
+// not expired yet.
+var ErrIdempotencyKeyExists = errors.New("idempotency key already used")
+
 type Store interface {
 	Querier
 	TransferTx(ctx context.Context, params TransferTxParams) (TransferTxResult, error)
+	DepositTx(ctx context.Context, params DepositTxParams) (TransferTxResult, error)
+	WithdrawTx(ctx context.Context, params WithdrawTxParams) (TransferTxResult, error)
+	ReverseTx(ctx context.Context, params ReverseTxParams) (ReverseTxResult, error)
+	HoldTx(ctx context.Context, params HoldTxParams) (HoldTxResult, error)
+	CaptureTx(ctx context.Context, params CaptureTxParams) (CaptureTxResult, error)
+	VoidTx(ctx context.Context, holdID int32) (HoldTxResult, error)
+	ExpireHoldsTx(ctx context.Context, limit int32) (int, error)
+	ClaimScheduledTransfersTx(ctx context.Context, limit int32) ([]ScheduledTransferClaim, error)
+	ClaimStaleScheduledTransferRunsTx(ctx context.Context, claimedBefore time.Time, limit int32) ([]ScheduledTransferClaim, error)
+	BatchTransferTx(ctx context.Context, params BatchTransferTxParams) (BatchTransferTxResult, error)
+	GetAccountLimits(ctx context.Context, account Account) (AccountLimits, error)
 }
 
 type SQLStore struct {
@@ -55,6 +86,23 @@ type TransferTxParams struct {
 	FromAccountID int32
 	ToAccountID   int32
 	Amount        int64
+	Description   string
+	Reference     string
+	// QuoteID, when set, converts the amount credited to the to account with
+	// the locked rate of the quote. It is required across currencies.
+	QuoteID pgtype.UUID
+	// Idempotency, when set, is stored with the serialized result in the same
+	// transaction so a retried request can never move the money twice.
+	Idempotency *TransferIdempotency
+}
+
+type TransferIdempotency struct {
+	Username    string
+	Key         string
+	RequestHash string
+	// Duration is how long the key replays the transfer, it can be reused
+	// afterwards.
+	Duration time.Duration
 }
 
 type TransferTxResult struct {
@@ -63,54 +111,194 @@ type TransferTxResult struct {
 	ToAccount   Account
 	FromEntry   Entry
 	ToEntry     Entry
+	// FeeEntry credits the fee account, it is only set when a fee was
+	// charged.
+	FeeEntry *Entry
 }
 
 func (s *SQLStore) TransferTx(ctx context.Context, params TransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult
 
 	err := s.execTx(ctx, func(q *Queries) error {
-		var err error
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: params.FromAccountID,
-			ToAccountID:   params.ToAccountID,
-			Amount:        params.Amount,
-		})
+		fromAccount, toAccount, err := lockTransferAccounts(ctx, q, params.FromAccountID, params.ToAccountID)
 		if err != nil {
 			return err
 		}
 
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: params.FromAccountID,
-			Amount:    -params.Amount,
-		})
+		schedule, err := feeSchedule(ctx, q, fromAccount.Currency)
 		if err != nil {
 			return err
 		}
+		fee := schedule.Fee(params.Amount)
 
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: params.ToAccountID,
-			Amount:    params.Amount,
-		})
-		if err != nil {
+		// the fee is paid by the from account on top of the amount
+		if err := validateTransfer(fromAccount, toAccount, params.Amount+fee); err != nil {
 			return err
 		}
 
-		if params.FromAccountID < params.ToAccountID {
-			result.FromAccount, result.ToAccount, err = transferMoney(ctx, q, params.FromAccountID, -params.Amount, params.ToAccountID, params.Amount)
+		if err := checkTransferLimits(ctx, q, fromAccount, params.Amount+fee); err != nil {
+			return err
+		}
 
-		} else {
-			result.ToAccount, result.FromAccount, err = transferMoney(ctx, q, params.ToAccountID, +params.Amount, params.FromAccountID, -params.Amount)
+		transfer := CreateTransferParams{
+			FromAccountID: params.FromAccountID,
+			ToAccountID:   params.ToAccountID,
+			Amount:        params.Amount,
+			Currency:      fromAccount.Currency,
+			Description:   params.Description,
+			Reference:     params.Reference,
+			Fee:           fee,
+		}
+		creditAmount := params.Amount
+
+		if params.QuoteID.Valid {
+			creditAmount, transfer.ExchangeRate, err = applyQuote(ctx, q, params.QuoteID, fromAccount, toAccount, params.Amount)
+			if err != nil {
+				return err
+			}
+		} else if fromAccount.Currency != toAccount.Currency {
+			return fmt.Errorf("%w: from account currency %s, to account currency %s", ErrCurrencyMismatch, fromAccount.Currency, toAccount.Currency)
 		}
+
+		result, err = postTransfer(ctx, q, transfer, creditAmount)
 		if err != nil {
 			return err
 		}
 
+		if params.Idempotency != nil {
+			return storeIdempotencyKey(ctx, q, params.Idempotency, result)
+		}
+
 		return nil
 	})
 
 	return result, err
 }
 
+// postTransfer records the transfer and its two entries and moves the money.
+// The to account is credited creditAmount, which only differs from the
+// debited amount on cross-currency transfers. A fee is debited from the from
+// account on top of the amount and credited to the fee account in a third
+// entry. The caller must hold the locks on both accounts.
+func postTransfer(ctx context.Context, q *Queries, transfer CreateTransferParams, creditAmount int64) (result TransferTxResult, err error) {
+	fromAccountID, toAccountID, debit := transfer.FromAccountID, transfer.ToAccountID, transfer.Amount+transfer.Fee
+
+	result.Transfer, err = q.CreateTransfer(ctx, transfer)
+	if err != nil {
+		return
+	}
+
+	transferID := pgtype.Int4{Int32: result.Transfer.ID, Valid: true}
+
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  fromAccountID,
+		Amount:     -debit,
+		TransferID: transferID,
+	})
+	if err != nil {
+		return
+	}
+
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  toAccountID,
+		Amount:     creditAmount,
+		TransferID: transferID,
+	})
+	if err != nil {
+		return
+	}
+
+	if fromAccountID < toAccountID {
+		result.FromAccount, result.ToAccount, err = transferMoney(ctx, q, fromAccountID, -debit, toAccountID, creditAmount)
+
+	} else {
+		result.ToAccount, result.FromAccount, err = transferMoney(ctx, q, toAccountID, +creditAmount, fromAccountID, -debit)
+	}
+	if err != nil {
+		return
+	}
+
+	if transfer.Fee > 0 {
+		result.FeeEntry, err = creditFee(ctx, q, result.Transfer)
+	}
+
+	return
+}
+
+// lockTransferAccounts locks both accounts in a consistent order, so two
+// opposite transfers cannot deadlock and the checks made on the returned
+// accounts still hold when their balances are updated.
+func lockTransferAccounts(ctx context.Context, q *Queries, fromAccountID int32, toAccountID int32) (fromAccount Account, toAccount Account, err error) {
+	if fromAccountID < toAccountID {
+		if fromAccount, err = q.GetAccountForUpdate(ctx, fromAccountID); err != nil {
+			return
+		}
+		toAccount, err = q.GetAccountForUpdate(ctx, toAccountID)
+		return
+	}
+
+	if toAccount, err = q.GetAccountForUpdate(ctx, toAccountID); err != nil {
+		return
+	}
+	fromAccount, err = q.GetAccountForUpdate(ctx, fromAccountID)
+	return
+}
+
+func validateTransfer(fromAccount Account, toAccount Account, amount int64) error {
+	// a transfer to itself would only charge a fee
+	if fromAccount.ID == toAccount.ID {
+		return fmt.Errorf("%w: account %d", ErrSameAccount, fromAccount.ID)
+	}
+
+	// settlement accounts only move money through DepositTx and WithdrawTx
+	for _, account := range []Account{fromAccount, toAccount} {
+		if err := validateCustomerAccount(account); err != nil {
+			return err
+		}
+	}
+
+	// money held for a later capture cannot be spent
+	if fromAccount.AvailableBalance < amount {
+		return ErrInsufficientBalance
+	}
+
+	return nil
+}
+
+func validateCustomerAccount(account Account) error {
+	if account.Kind != utils.AccountKindCustomer {
+		return fmt.Errorf("%w: account %d is a %s account", ErrNotCustomerAccount, account.ID, account.Kind)
+	}
+	if account.Status != utils.AccountStatusActive {
+		return fmt.Errorf("%w: account %d is %s", ErrAccountNotActive, account.ID, account.Status)
+	}
+	return nil
+}
+
+func storeIdempotencyKey(ctx context.Context, q *Queries, idempotency *TransferIdempotency, result TransferTxResult) error {
+	response, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreateIdempotencyKey(ctx, CreateIdempotencyKeyParams{
+		Username:    idempotency.Username,
+		Key:         idempotency.Key,
+		RequestHash: idempotency.RequestHash,
+		Response:    response,
+		ExpiresAt:   pgtype.Timestamptz{Time: time.Now().Add(idempotency.Duration), Valid: true},
+	})
+	if err != nil {
+		// the key is taken and has not expired
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrIdempotencyKeyExists
+		}
+		return err
+	}
+
+	return nil
+}
+
 func transferMoney(
 	ctx context.Context,
 	q *Queries,
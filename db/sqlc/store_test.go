@@ -5,13 +5,14 @@ import (
 	"testing"
 
 	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/utils"
 	"github.com/stretchr/testify/require"
 )
 
 func TestCreateTransferTx(t *testing.T) {
 	store := db.NewStore(testPool)
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
+	account1 := createRandomAccount(t, "USD")
+	account2 := createRandomAccount(t, "USD")
 
 	num := 5
 	amount := int64(10)
@@ -52,6 +53,7 @@ func TestCreateTransferTx(t *testing.T) {
 		require.Equal(t, -amount, fromEntry.Amount)
 		require.NotZero(t, fromEntry.ID)
 		require.NotZero(t, fromEntry.CreatedAt)
+		require.Equal(t, transfer.ID, fromEntry.TransferID.Int32)
 
 		_, err = testQueries.GetEntry(context.Background(), fromEntry.ID)
 		require.NoError(t, err)
@@ -105,8 +107,8 @@ type result struct {
 
 func TestCreateTransferTxDeadLock(t *testing.T) {
 	store := db.NewStore(testPool)
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
+	account1 := createRandomAccount(t, "USD")
+	account2 := createRandomAccount(t, "USD")
 
 	num := 10
 	amount := int64(10)
@@ -143,3 +145,92 @@ func TestCreateTransferTxDeadLock(t *testing.T) {
 	require.Equal(t, account1.Balance, updatedAccount1.Balance)
 	require.Equal(t, account2.Balance, updatedAccount2.Balance)
 }
+
+func TestCreateTransferTxInactiveAccount(t *testing.T) {
+	store := db.NewStore(testPool)
+	account1 := createRandomAccount(t, "USD")
+	account2 := createRandomAccount(t, "USD")
+
+	_, err := testQueries.UpdateAccountStatus(context.Background(), db.UpdateAccountStatusParams{
+		ID:            account2.ID,
+		Status:        utils.AccountStatusFrozen,
+		CurrentStatus: utils.AccountStatusActive,
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, db.ErrAccountNotActive)
+
+	// nothing is written when the transfer is refused
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+}
+
+func TestCreateTransferTxCurrencyMismatch(t *testing.T) {
+	store := db.NewStore(testPool)
+	account1 := createRandomAccount(t, "USD")
+	account2 := createRandomAccount(t, "EUR")
+
+	_, err := store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, db.ErrCurrencyMismatch)
+}
+
+func TestCreateTransferTxSameAccount(t *testing.T) {
+	store := db.NewStore(testPool)
+	account := createRandomAccount(t, "USD")
+
+	_, err := store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account.ID,
+		ToAccountID:   account.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, db.ErrSameAccount)
+}
+
+func TestCreateTransferTxConcurrentOverdraw(t *testing.T) {
+	store := db.NewStore(testPool)
+	account1 := createRandomAccount(t, "USD")
+	account2 := createRandomAccount(t, "USD")
+
+	// each transfer fits the balance on its own but only one of them can
+	// succeed once the other one committed
+	amount := account1.Balance/2 + 1
+
+	num := 2
+	errs := make(chan error, num)
+	for i := 0; i < num; i++ {
+		go func() {
+			_, err := store.TransferTx(context.Background(), db.TransferTxParams{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+			})
+			errs <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < num; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, db.ErrInsufficientBalance)
+	}
+	require.Equal(t, 1, succeeded)
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-amount, updatedAccount1.Balance)
+	require.GreaterOrEqual(t, updatedAccount1.Balance, int64(0))
+}
@@ -0,0 +1,202 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+)
+
+type BatchTransferItem struct {
+	ToAccountID int32
+	Amount      int64
+	Description string
+	Reference   string
+}
+
+type BatchTransferTxParams struct {
+	Owner         string
+	FromAccountID int32
+	// Mode is utils.TransferBatchModeAtomic or
+	// utils.TransferBatchModeBestEffort.
+	Mode  string
+	Items []BatchTransferItem
+}
+
+type BatchTransferTxResult struct {
+	Batch       TransferBatch
+	Items       []TransferBatchItem
+	FromAccount Account
+}
+
+// BatchTransferTx moves money from one account to many in a single
+// transaction. In atomic mode the first item that breaks a rule fails the
+// whole batch, the error names the item and nothing is recorded. In best
+// effort mode such items are recorded as failed with the reason and the
+// others still move. Items are posted in order, so when the balance runs out
+// it is the last items that fail.
+func (s *SQLStore) BatchTransferTx(ctx context.Context, params BatchTransferTxParams) (BatchTransferTxResult, error) {
+	var result BatchTransferTxResult
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		accounts, err := lockBatchAccounts(ctx, q, params)
+		if err != nil {
+			return err
+		}
+
+		fromAccount, ok := accounts[params.FromAccountID]
+		if !ok {
+			return pgx.ErrNoRows
+		}
+		if err := validateCustomerAccount(fromAccount); err != nil {
+			return err
+		}
+
+		limits, err := accountLimits(ctx, q, fromAccount, time.Now())
+		if err != nil {
+			return err
+		}
+
+		schedule, err := feeSchedule(ctx, q, fromAccount.Currency)
+		if err != nil {
+			return err
+		}
+
+		batch := CreateTransferBatchParams{
+			Owner:         params.Owner,
+			FromAccountID: fromAccount.ID,
+			Currency:      fromAccount.Currency,
+			Mode:          params.Mode,
+			ItemCount:     int32(len(params.Items)),
+		}
+		items := make([]CreateTransferBatchItemParams, 0, len(params.Items))
+
+		for i, item := range params.Items {
+			batchItem := CreateTransferBatchItemParams{
+				Position:    int32(i),
+				ToAccountID: item.ToAccountID,
+				Amount:      item.Amount,
+				Description: item.Description,
+				Reference:   item.Reference,
+				Status:      utils.TransferBatchItemStatusSucceeded,
+			}
+
+			// only rule violations are recorded on the item, any other
+			// error has aborted the database transaction
+			fee := schedule.Fee(item.Amount)
+			if err := validateBatchItem(fromAccount, accounts, limits, item, fee); err != nil {
+				if params.Mode == utils.TransferBatchModeAtomic {
+					return fmt.Errorf("item %d: %w", i, err)
+				}
+				batchItem.Status = utils.TransferBatchItemStatusFailed
+				batchItem.Error = err.Error()
+				items = append(items, batchItem)
+				continue
+			}
+
+			transferred, err := postTransfer(ctx, q, CreateTransferParams{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   item.ToAccountID,
+				Amount:        item.Amount,
+				Currency:      fromAccount.Currency,
+				Description:   item.Description,
+				Reference:     item.Reference,
+				Fee:           fee,
+			}, item.Amount)
+			if err != nil {
+				return err
+			}
+
+			fromAccount = transferred.FromAccount
+			limits.spend(item.Amount + fee)
+			batchItem.TransferID = pgtype.Int4{Int32: transferred.Transfer.ID, Valid: true}
+			batch.SucceededCount++
+			batch.TotalAmount += item.Amount
+			batch.TotalFee += fee
+			items = append(items, batchItem)
+		}
+
+		switch batch.SucceededCount {
+		case batch.ItemCount:
+			batch.Status = utils.TransferBatchStatusCompleted
+		case 0:
+			batch.Status = utils.TransferBatchStatusFailed
+		default:
+			batch.Status = utils.TransferBatchStatusPartiallyCompleted
+		}
+
+		result.Batch, err = q.CreateTransferBatch(ctx, batch)
+		if err != nil {
+			return err
+		}
+
+		result.Items = make([]TransferBatchItem, 0, len(items))
+		for _, item := range items {
+			item.BatchID = result.Batch.ID
+			created, err := q.CreateTransferBatchItem(ctx, item)
+			if err != nil {
+				return err
+			}
+			result.Items = append(result.Items, created)
+		}
+
+		result.FromAccount = fromAccount
+		return nil
+	})
+
+	return result, err
+}
+
+// lockBatchAccounts locks the from account and every to account of the batch
+// in id order, the order lockTransferAccounts uses, so a batch cannot
+// deadlock with a concurrent transfer. Accounts that do not exist are left
+// out of the returned map.
+func lockBatchAccounts(ctx context.Context, q *Queries, params BatchTransferTxParams) (map[int32]Account, error) {
+	ids := []int32{params.FromAccountID}
+	seen := map[int32]bool{params.FromAccountID: true}
+	for _, item := range params.Items {
+		if !seen[item.ToAccountID] {
+			seen[item.ToAccountID] = true
+			ids = append(ids, item.ToAccountID)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	accounts := make(map[int32]Account, len(ids))
+	for _, id := range ids {
+		account, err := q.GetAccountForUpdate(ctx, id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue
+			}
+			return nil, err
+		}
+		accounts[id] = account
+	}
+
+	return accounts, nil
+}
+
+// validateBatchItem checks an item as TransferTx checks a transfer, fee
+// included, against the from account as the previous items left it.
+func validateBatchItem(fromAccount Account, accounts map[int32]Account, limits AccountLimits, item BatchTransferItem, fee int64) error {
+	toAccount, ok := accounts[item.ToAccountID]
+	if !ok {
+		return fmt.Errorf("account %d not found: %w", item.ToAccountID, pgx.ErrNoRows)
+	}
+
+	if err := validateTransfer(fromAccount, toAccount, item.Amount+fee); err != nil {
+		return err
+	}
+	// batches carry no quotes
+	if fromAccount.Currency != toAccount.Currency {
+		return fmt.Errorf("%w: from account currency %s, to account currency %s", ErrCurrencyMismatch, fromAccount.Currency, toAccount.Currency)
+	}
+
+	return limits.check(item.Amount + fee)
+}
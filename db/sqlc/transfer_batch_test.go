@@ -0,0 +1,146 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchTransferTxAtomic(t *testing.T) {
+	store := db.NewStore(testPool)
+	fromAccount := createRandomAccount(t, "USD")
+	toAccount1 := createRandomAccount(t, "USD")
+	toAccount2 := createRandomAccount(t, "USD")
+
+	result, err := store.BatchTransferTx(context.Background(), db.BatchTransferTxParams{
+		Owner:         fromAccount.Owner,
+		FromAccountID: fromAccount.ID,
+		Mode:          utils.TransferBatchModeAtomic,
+		Items: []db.BatchTransferItem{
+			{ToAccountID: toAccount1.ID, Amount: 100, Reference: "salary-1"},
+			{ToAccountID: toAccount2.ID, Amount: 200, Reference: "salary-2"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, utils.TransferBatchStatusCompleted, result.Batch.Status)
+	require.Equal(t, int32(2), result.Batch.ItemCount)
+	require.Equal(t, int32(2), result.Batch.SucceededCount)
+	require.Equal(t, int64(300), result.Batch.TotalAmount)
+	require.Equal(t, "USD", result.Batch.Currency)
+	require.Equal(t, fromAccount.Balance-300, result.FromAccount.Balance)
+
+	require.Len(t, result.Items, 2)
+	for i, item := range result.Items {
+		require.Equal(t, result.Batch.ID, item.BatchID)
+		require.Equal(t, int32(i), item.Position)
+		require.Equal(t, utils.TransferBatchItemStatusSucceeded, item.Status)
+		require.True(t, item.TransferID.Valid)
+
+		transfer, err := store.GetTransfer(context.Background(), item.TransferID.Int32)
+		require.NoError(t, err)
+		require.Equal(t, item.ToAccountID, transfer.ToAccountID)
+		require.Equal(t, item.Amount, transfer.Amount)
+		require.Equal(t, item.Reference, transfer.Reference)
+	}
+
+	items, err := store.ListTransferBatchItems(context.Background(), result.Batch.ID)
+	require.NoError(t, err)
+	require.Equal(t, result.Items, items)
+
+	// the second item cannot be paid, so neither is
+	_, err = store.BatchTransferTx(context.Background(), db.BatchTransferTxParams{
+		Owner:         fromAccount.Owner,
+		FromAccountID: fromAccount.ID,
+		Mode:          utils.TransferBatchModeAtomic,
+		Items: []db.BatchTransferItem{
+			{ToAccountID: toAccount1.ID, Amount: 10},
+			{ToAccountID: toAccount2.ID, Amount: result.FromAccount.Balance},
+		},
+	})
+	require.ErrorIs(t, err, db.ErrInsufficientBalance)
+	require.ErrorContains(t, err, "item 1")
+
+	account, err := store.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, result.FromAccount.Balance, account.Balance)
+}
+
+func TestBatchTransferTxBestEffort(t *testing.T) {
+	store := db.NewStore(testPool)
+	fromAccount := createRandomAccount(t, "USD")
+	toAccount := createRandomAccount(t, "USD")
+	eurAccount := createRandomAccount(t, "EUR")
+
+	result, err := store.BatchTransferTx(context.Background(), db.BatchTransferTxParams{
+		Owner:         fromAccount.Owner,
+		FromAccountID: fromAccount.ID,
+		Mode:          utils.TransferBatchModeBestEffort,
+		Items: []db.BatchTransferItem{
+			{ToAccountID: toAccount.ID, Amount: 100},
+			{ToAccountID: eurAccount.ID, Amount: 100},
+			{ToAccountID: toAccount.ID, Amount: fromAccount.Balance},
+			{ToAccountID: -1, Amount: 100},
+			{ToAccountID: toAccount.ID, Amount: 50},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, utils.TransferBatchStatusPartiallyCompleted, result.Batch.Status)
+	require.Equal(t, int32(5), result.Batch.ItemCount)
+	require.Equal(t, int32(2), result.Batch.SucceededCount)
+	require.Equal(t, int64(150), result.Batch.TotalAmount)
+	require.Equal(t, fromAccount.Balance-150, result.FromAccount.Balance)
+
+	statuses := []string{}
+	for _, item := range result.Items {
+		statuses = append(statuses, item.Status)
+		require.Equal(t, item.Status == utils.TransferBatchItemStatusSucceeded, item.TransferID.Valid)
+		require.Equal(t, item.Status == utils.TransferBatchItemStatusFailed, item.Error != "")
+	}
+	require.Equal(t, []string{
+		utils.TransferBatchItemStatusSucceeded,
+		utils.TransferBatchItemStatusFailed,
+		utils.TransferBatchItemStatusFailed,
+		utils.TransferBatchItemStatusFailed,
+		utils.TransferBatchItemStatusSucceeded,
+	}, statuses)
+	require.Contains(t, result.Items[1].Error, db.ErrCurrencyMismatch.Error())
+	require.Contains(t, result.Items[2].Error, db.ErrInsufficientBalance.Error())
+	require.Contains(t, result.Items[3].Error, "not found")
+
+	batch, err := store.GetTransferBatch(context.Background(), result.Batch.ID)
+	require.NoError(t, err)
+	require.Equal(t, result.Batch, batch)
+}
+
+func TestBatchTransferTxInactiveFromAccount(t *testing.T) {
+	store := db.NewStore(testPool)
+	fromAccount := createRandomAccount(t, "USD")
+	toAccount := createRandomAccount(t, "USD")
+
+	_, err := store.UpdateAccountStatus(context.Background(), db.UpdateAccountStatusParams{
+		ID:            fromAccount.ID,
+		Status:        utils.AccountStatusFrozen,
+		CurrentStatus: utils.AccountStatusActive,
+	})
+	require.NoError(t, err)
+
+	_, err = store.BatchTransferTx(context.Background(), db.BatchTransferTxParams{
+		Owner:         fromAccount.Owner,
+		FromAccountID: fromAccount.ID,
+		Mode:          utils.TransferBatchModeBestEffort,
+		Items:         []db.BatchTransferItem{{ToAccountID: toAccount.ID, Amount: 10}},
+	})
+	require.ErrorIs(t, err, db.ErrAccountNotActive)
+
+	_, err = store.BatchTransferTx(context.Background(), db.BatchTransferTxParams{
+		Owner:         fromAccount.Owner,
+		FromAccountID: -1,
+		Mode:          utils.TransferBatchModeBestEffort,
+		Items:         []db.BatchTransferItem{{ToAccountID: toAccount.ID, Amount: 10}},
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
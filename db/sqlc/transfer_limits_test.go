@@ -0,0 +1,142 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// limits are only set on random accounts and users here, a limit of a whole
+// currency would apply to the transfers of the other tests
+func setAccountLimit(t *testing.T, account db.Account, perTransaction int64, daily int64, monthly int64) db.TransferLimit {
+	limit, err := testQueries.UpsertTransferLimit(context.Background(), db.UpsertTransferLimitParams{
+		Currency:       account.Currency,
+		AccountID:      pgtype.Int4{Int32: account.ID, Valid: true},
+		PerTransaction: pgtype.Int8{Int64: perTransaction, Valid: perTransaction != 0},
+		Daily:          pgtype.Int8{Int64: daily, Valid: daily != 0},
+		Monthly:        pgtype.Int8{Int64: monthly, Valid: monthly != 0},
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.ID, limit.AccountID.Int32)
+	require.False(t, limit.Owner.Valid)
+
+	return limit
+}
+
+func TestUpsertTransferLimit(t *testing.T) {
+	account := createRandomAccount(t, "USD")
+
+	limit1 := setAccountLimit(t, account, 100, 0, 0)
+	limit2 := setAccountLimit(t, account, 0, 500, 0)
+	require.Equal(t, limit1.ID, limit2.ID)
+	require.False(t, limit2.PerTransaction.Valid)
+	require.Equal(t, int64(500), limit2.Daily.Int64)
+}
+
+func TestTransferTxLimits(t *testing.T) {
+	store := db.NewStore(testPool)
+	fromAccount := createRandomAccount(t, "USD")
+	toAccount := createRandomAccount(t, "USD")
+
+	setAccountLimit(t, fromAccount, 300, 500, 0)
+
+	transfer := func(amount int64) error {
+		_, err := store.TransferTx(context.Background(), db.TransferTxParams{
+			FromAccountID: fromAccount.ID,
+			ToAccountID:   toAccount.ID,
+			Amount:        amount,
+		})
+		return err
+	}
+
+	require.ErrorIs(t, transfer(301), db.ErrTransferLimitExceeded)
+	require.NoError(t, transfer(300))
+	require.NoError(t, transfer(150))
+	require.ErrorIs(t, transfer(51), db.ErrTransferLimitExceeded)
+
+	limits, err := store.GetAccountLimits(context.Background(), fromAccount)
+	require.NoError(t, err)
+	require.Equal(t, int64(450), limits.DailySpent)
+
+	remaining, ok := limits.DailyRemaining()
+	require.True(t, ok)
+	require.Equal(t, int64(50), remaining)
+
+	_, ok = limits.MonthlyRemaining()
+	require.False(t, ok)
+
+	require.NoError(t, transfer(50))
+}
+
+func TestTransferTxOwnerLimit(t *testing.T) {
+	store := db.NewStore(testPool)
+	fromAccount := createRandomAccount(t, "USD")
+	toAccount := createRandomAccount(t, "USD")
+
+	_, err := testQueries.UpsertTransferLimit(context.Background(), db.UpsertTransferLimitParams{
+		Currency: fromAccount.Currency,
+		Owner:    pgtype.Text{String: fromAccount.Owner, Valid: true},
+		Monthly:  pgtype.Int8{Int64: 100, Valid: true},
+	})
+	require.NoError(t, err)
+
+	// the limit of the account wins over the one of its owner
+	setAccountLimit(t, fromAccount, 0, 0, 200)
+
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        150,
+	})
+	require.NoError(t, err)
+
+	// pending holds count as spent
+	_, err = store.HoldTx(context.Background(), db.HoldTxParams{
+		AccountID:   fromAccount.ID,
+		ToAccountID: toAccount.ID,
+		Amount:      51,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, db.ErrTransferLimitExceeded)
+
+	fromAccount, err = store.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	createHold(t, fromAccount, toAccount, 50, time.Now().Add(time.Hour))
+
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, db.ErrTransferLimitExceeded)
+}
+
+func TestBatchTransferTxLimits(t *testing.T) {
+	store := db.NewStore(testPool)
+	fromAccount := createRandomAccount(t, "USD")
+	toAccount := createRandomAccount(t, "USD")
+
+	setAccountLimit(t, fromAccount, 0, 250, 0)
+
+	result, err := store.BatchTransferTx(context.Background(), db.BatchTransferTxParams{
+		Owner:         fromAccount.Owner,
+		FromAccountID: fromAccount.ID,
+		Mode:          utils.TransferBatchModeBestEffort,
+		Items: []db.BatchTransferItem{
+			{ToAccountID: toAccount.ID, Amount: 100},
+			{ToAccountID: toAccount.ID, Amount: 100},
+			{ToAccountID: toAccount.ID, Amount: 100},
+			{ToAccountID: toAccount.ID, Amount: 50},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(250), result.Batch.TotalAmount)
+	require.Equal(t, utils.TransferBatchItemStatusFailed, result.Items[2].Status)
+	require.Contains(t, result.Items[2].Error, db.ErrTransferLimitExceeded.Error())
+	require.Equal(t, utils.TransferBatchItemStatusSucceeded, result.Items[3].Status)
+}
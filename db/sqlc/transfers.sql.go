@@ -7,22 +7,72 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const addTransferReversedAmount = `-- name: AddTransferReversedAmount :one
+UPDATE transfers
+SET reversed_amount = reversed_amount + $1
+WHERE id = $2
+RETURNING id, from_account_id, to_account_id, amount, created_at, exchange_rate, currency, description, reference, reversal_of, reversed_amount, fee
+`
+
+type AddTransferReversedAmountParams struct {
+	Amount int64 `json:"amount"`
+	ID     int32 `json:"id"`
+}
+
+func (q *Queries) AddTransferReversedAmount(ctx context.Context, arg AddTransferReversedAmountParams) (Transfer, error) {
+	row := q.db.QueryRow(ctx, addTransferReversedAmount, arg.Amount, arg.ID)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ExchangeRate,
+		&i.Currency,
+		&i.Description,
+		&i.Reference,
+		&i.ReversalOf,
+		&i.ReversedAmount,
+		&i.Fee,
+	)
+	return i, err
+}
+
 const createTransfer = `-- name: CreateTransfer :one
-INSERT INTO transfers(from_account_id,to_account_id,amount)
-VALUES ($1,$2,$3)
-returning id, from_account_id, to_account_id, amount, created_at
+INSERT INTO transfers(from_account_id,to_account_id,amount,exchange_rate,currency,description,reference,reversal_of,fee)
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+returning id, from_account_id, to_account_id, amount, created_at, exchange_rate, currency, description, reference, reversal_of, reversed_amount, fee
 `
 
 type CreateTransferParams struct {
-	FromAccountID int32 `json:"from_account_id"`
-	ToAccountID   int32 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int32          `json:"from_account_id"`
+	ToAccountID   int32          `json:"to_account_id"`
+	Amount        int64          `json:"amount"`
+	ExchangeRate  pgtype.Numeric `json:"exchange_rate"`
+	Currency      string         `json:"currency"`
+	Description   string         `json:"description"`
+	Reference     string         `json:"reference"`
+	ReversalOf    pgtype.Int4    `json:"reversal_of"`
+	Fee           int64          `json:"fee"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRow(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRow(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ExchangeRate,
+		arg.Currency,
+		arg.Description,
+		arg.Reference,
+		arg.ReversalOf,
+		arg.Fee,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -30,6 +80,13 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ExchangeRate,
+		&i.Currency,
+		&i.Description,
+		&i.Reference,
+		&i.ReversalOf,
+		&i.ReversedAmount,
+		&i.Fee,
 	)
 	return i, err
 }
@@ -44,7 +101,7 @@ func (q *Queries) DeleteAllTransfers(ctx context.Context) error {
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, exchange_rate, currency, description, reference, reversal_of, reversed_amount, fee FROM transfers
 WHERE id = $1 LIMIT 1
 `
 
@@ -57,6 +114,204 @@ func (q *Queries) GetTransfer(ctx context.Context, id int32) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ExchangeRate,
+		&i.Currency,
+		&i.Description,
+		&i.Reference,
+		&i.ReversalOf,
+		&i.ReversedAmount,
+		&i.Fee,
 	)
 	return i, err
 }
+
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, exchange_rate, currency, description, reference, reversal_of, reversed_amount, fee FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int32) (Transfer, error) {
+	row := q.db.QueryRow(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ExchangeRate,
+		&i.Currency,
+		&i.Description,
+		&i.Reference,
+		&i.ReversalOf,
+		&i.ReversedAmount,
+		&i.Fee,
+	)
+	return i, err
+}
+
+const listOwnerTransfers = `-- name: ListOwnerTransfers :many
+SELECT id, from_account_id, to_account_id, amount, created_at, exchange_rate, currency, description, reference, reversal_of, reversed_amount, fee FROM transfers
+WHERE (
+    ($1::varchar <> 'in' AND from_account_id IN (
+        SELECT id FROM accounts
+        WHERE owner = $2 AND ($3::int IS NULL OR id = $3)
+    ))
+    OR ($1::varchar <> 'out' AND to_account_id IN (
+        SELECT id FROM accounts
+        WHERE owner = $2 AND ($3::int IS NULL OR id = $3)
+    ))
+)
+    AND ($4::bigint IS NULL OR amount >= $4)
+    AND ($5::bigint IS NULL OR amount <= $5)
+    AND ($6::timestamptz IS NULL OR created_at >= $6)
+    AND ($7::timestamptz IS NULL OR created_at < $7)
+    AND ($8::varchar IS NULL OR reference = $8)
+ORDER BY id DESC
+LIMIT $9
+OFFSET $10
+`
+
+type ListOwnerTransfersParams struct {
+	Direction string             `json:"direction"`
+	Owner     string             `json:"owner"`
+	AccountID pgtype.Int4        `json:"account_id"`
+	MinAmount pgtype.Int8        `json:"min_amount"`
+	MaxAmount pgtype.Int8        `json:"max_amount"`
+	FromTime  pgtype.Timestamptz `json:"from_time"`
+	ToTime    pgtype.Timestamptz `json:"to_time"`
+	Reference pgtype.Text        `json:"reference"`
+	Limit     int32              `json:"limit"`
+	Offset    int32              `json:"offset"`
+}
+
+func (q *Queries) ListOwnerTransfers(ctx context.Context, arg ListOwnerTransfersParams) ([]Transfer, error) {
+	rows, err := q.db.Query(ctx, listOwnerTransfers,
+		arg.Direction,
+		arg.Owner,
+		arg.AccountID,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.FromTime,
+		arg.ToTime,
+		arg.Reference,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ExchangeRate,
+			&i.Currency,
+			&i.Description,
+			&i.Reference,
+			&i.ReversalOf,
+			&i.ReversedAmount,
+			&i.Fee,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransferReversals = `-- name: ListTransferReversals :many
+SELECT id, from_account_id, to_account_id, amount, created_at, exchange_rate, currency, description, reference, reversal_of, reversed_amount, fee FROM transfers
+WHERE reversal_of = $1
+ORDER BY id
+`
+
+func (q *Queries) ListTransferReversals(ctx context.Context, reversalOf pgtype.Int4) ([]Transfer, error) {
+	rows, err := q.db.Query(ctx, listTransferReversals, reversalOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ExchangeRate,
+			&i.Currency,
+			&i.Description,
+			&i.Reference,
+			&i.ReversalOf,
+			&i.ReversedAmount,
+			&i.Fee,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransfers = `-- name: ListTransfers :many
+SELECT id, from_account_id, to_account_id, amount, created_at, exchange_rate, currency, description, reference, reversal_of, reversed_amount, fee FROM transfers
+WHERE from_account_id = $1 OR to_account_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3
+`
+
+type ListTransfersParams struct {
+	AccountID int32 `json:"account_id"`
+	Limit     int32 `json:"limit"`
+	Offset    int32 `json:"offset"`
+}
+
+func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error) {
+	rows, err := q.db.Query(ctx, listTransfers, arg.AccountID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ExchangeRate,
+			&i.Currency,
+			&i.Description,
+			&i.Reference,
+			&i.ReversalOf,
+			&i.ReversedAmount,
+			&i.Fee,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
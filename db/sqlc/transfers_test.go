@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/jackc/pgx/v5/pgtype"
 	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
 	"github.com/mohammad19khodaei/simple_bank/utils"
 	"github.com/stretchr/testify/require"
@@ -17,6 +18,7 @@ func TestCreateTransfer(t *testing.T) {
 		FromAccountID: account1.ID,
 		ToAccountID:   account2.ID,
 		Amount:        utils.RandomMoney(),
+		Currency:      account1.Currency,
 	}
 	transfer, err := testQueries.CreateTransfer(context.Background(), params)
 	require.NoError(t, err)
@@ -25,5 +27,105 @@ func TestCreateTransfer(t *testing.T) {
 	require.Equal(t, params.FromAccountID, transfer.FromAccountID)
 	require.Equal(t, params.ToAccountID, transfer.ToAccountID)
 	require.Equal(t, params.Amount, transfer.Amount)
+	require.Equal(t, params.Currency, transfer.Currency)
 	require.NotZero(t, transfer.CreatedAt)
 }
+
+func TestListTransfers(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	for i := 0; i < 3; i++ {
+		_, err := testQueries.CreateTransfer(context.Background(), db.CreateTransferParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        utils.RandomMoney(),
+			Currency:      account1.Currency,
+		})
+		require.NoError(t, err)
+
+		_, err = testQueries.CreateTransfer(context.Background(), db.CreateTransferParams{
+			FromAccountID: account2.ID,
+			ToAccountID:   account1.ID,
+			Amount:        utils.RandomMoney(),
+			Currency:      account2.Currency,
+		})
+		require.NoError(t, err)
+	}
+
+	transfers, err := testQueries.ListTransfers(context.Background(), db.ListTransfersParams{
+		AccountID: account1.ID,
+		Limit:     10,
+		Offset:    0,
+	})
+	require.NoError(t, err)
+	require.Len(t, transfers, 6)
+
+	for _, transfer := range transfers {
+		require.True(t, transfer.FromAccountID == account1.ID || transfer.ToAccountID == account1.ID)
+	}
+}
+
+func TestListOwnerTransfers(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	outgoing, err := testQueries.CreateTransfer(context.Background(), db.CreateTransferParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Currency:      account1.Currency,
+	})
+	require.NoError(t, err)
+
+	incoming, err := testQueries.CreateTransfer(context.Background(), db.CreateTransferParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        100,
+		Currency:      account2.Currency,
+		Reference:     utils.RandomString(12),
+	})
+	require.NoError(t, err)
+
+	params := db.ListOwnerTransfersParams{
+		Owner:  account1.Owner,
+		Limit:  10,
+		Offset: 0,
+	}
+
+	transfers, err := testQueries.ListOwnerTransfers(context.Background(), params)
+	require.NoError(t, err)
+	require.Equal(t, []db.Transfer{incoming, outgoing}, transfers)
+
+	params.Direction = "out"
+	transfers, err = testQueries.ListOwnerTransfers(context.Background(), params)
+	require.NoError(t, err)
+	require.Equal(t, []db.Transfer{outgoing}, transfers)
+
+	params.Direction = "in"
+	transfers, err = testQueries.ListOwnerTransfers(context.Background(), params)
+	require.NoError(t, err)
+	require.Equal(t, []db.Transfer{incoming}, transfers)
+
+	params.Direction = ""
+	params.MinAmount = pgtype.Int8{Int64: 50, Valid: true}
+	transfers, err = testQueries.ListOwnerTransfers(context.Background(), params)
+	require.NoError(t, err)
+	require.Equal(t, []db.Transfer{incoming}, transfers)
+
+	params.MinAmount = pgtype.Int8{}
+	params.Reference = pgtype.Text{String: incoming.Reference, Valid: true}
+	transfers, err = testQueries.ListOwnerTransfers(context.Background(), params)
+	require.NoError(t, err)
+	require.Equal(t, []db.Transfer{incoming}, transfers)
+
+	// transfers are only visible to the owners of their accounts
+	params = db.ListOwnerTransfersParams{
+		Owner:  utils.RandomOwner(),
+		Limit:  10,
+		Offset: 0,
+	}
+	transfers, err = testQueries.ListOwnerTransfers(context.Background(), params)
+	require.NoError(t, err)
+	require.Empty(t, transfers)
+}
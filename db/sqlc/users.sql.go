@@ -7,12 +7,14 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (username,hashed_password,full_name, email) 
 VALUES ($1,$2,$3,$4) 
-returning username, hashed_password, full_name, email, password_changed_at, created_at
+returning username, hashed_password, full_name, email, password_changed_at, created_at, role, is_frozen
 `
 
 type CreateUserParams struct {
@@ -37,12 +39,14 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.Role,
+		&i.IsFrozen,
 	)
 	return i, err
 }
 
 const getUser = `-- name: GetUser :one
-SELECT username, hashed_password, full_name, email, password_changed_at, created_at FROM users
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, role, is_frozen FROM users
 WHERE username = $1 LIMIT 1
 `
 
@@ -56,6 +60,123 @@ func (q *Queries) GetUser(ctx context.Context, username string) (User, error) {
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.Role,
+		&i.IsFrozen,
+	)
+	return i, err
+}
+
+const getUserPasswordChangedAt = `-- name: GetUserPasswordChangedAt :one
+SELECT password_changed_at FROM users
+WHERE username = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserPasswordChangedAt(ctx context.Context, username string) (pgtype.Timestamptz, error) {
+	row := q.db.QueryRow(ctx, getUserPasswordChangedAt, username)
+	var password_changed_at pgtype.Timestamptz
+	err := row.Scan(&password_changed_at)
+	return password_changed_at, err
+}
+
+const listUsers = `-- name: ListUsers :many
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, role, is_frozen FROM users
+WHERE $1::varchar = ''
+    OR username ILIKE '%' || $1 || '%'
+    OR email ILIKE '%' || $1 || '%'
+    OR full_name ILIKE '%' || $1 || '%'
+ORDER BY username
+LIMIT $2
+OFFSET $3
+`
+
+type ListUsersParams struct {
+	Search string `json:"search"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+}
+
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error) {
+	rows, err := q.db.Query(ctx, listUsers, arg.Search, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.Username,
+			&i.HashedPassword,
+			&i.FullName,
+			&i.Email,
+			&i.PasswordChangedAt,
+			&i.CreatedAt,
+			&i.Role,
+			&i.IsFrozen,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateUserFrozen = `-- name: UpdateUserFrozen :one
+UPDATE users
+SET is_frozen = $1
+WHERE username = $2
+returning username, hashed_password, full_name, email, password_changed_at, created_at, role, is_frozen
+`
+
+type UpdateUserFrozenParams struct {
+	IsFrozen bool   `json:"is_frozen"`
+	Username string `json:"username"`
+}
+
+func (q *Queries) UpdateUserFrozen(ctx context.Context, arg UpdateUserFrozenParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserFrozen, arg.IsFrozen, arg.Username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.IsFrozen,
+	)
+	return i, err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :one
+UPDATE users
+SET hashed_password = $1, password_changed_at = $2
+WHERE username = $3
+returning username, hashed_password, full_name, email, password_changed_at, created_at, role, is_frozen
+`
+
+type UpdateUserPasswordParams struct {
+	HashedPassword    string             `json:"hashed_password"`
+	PasswordChangedAt pgtype.Timestamptz `json:"password_changed_at"`
+	Username          string             `json:"username"`
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserPassword, arg.HashedPassword, arg.PasswordChangedAt, arg.Username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.IsFrozen,
 	)
 	return i, err
 }
@@ -3,7 +3,9 @@ package db_test
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/jackc/pgx/v5/pgtype"
 	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
 	"github.com/mohammad19khodaei/simple_bank/utils"
 	"github.com/stretchr/testify/require"
@@ -25,6 +27,8 @@ func createRandomUser(t *testing.T) db.User {
 	require.Equal(t, params.HashedPassword, user.HashedPassword)
 	require.Equal(t, params.FullName, user.FullName)
 	require.Equal(t, params.Email, user.Email)
+	require.Equal(t, utils.RoleCustomer, user.Role)
+	require.False(t, user.IsFrozen)
 	require.NotZero(t, user.PasswordChangedAt)
 	require.NotZero(t, user.CreatedAt)
 
@@ -46,3 +50,52 @@ func TestGetUser(t *testing.T) {
 	require.Equal(t, user1.PasswordChangedAt, user2.PasswordChangedAt)
 	require.Equal(t, user1.CreatedAt, user2.CreatedAt)
 }
+
+func TestUpdateUserPassword(t *testing.T) {
+	user1 := createRandomUser(t)
+
+	hashedPassword, err := utils.HashPassword(utils.RandomString(6))
+	require.NoError(t, err)
+
+	user2, err := testQueries.UpdateUserPassword(context.Background(), db.UpdateUserPasswordParams{
+		HashedPassword:    hashedPassword,
+		PasswordChangedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		Username:          user1.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, user1.Username, user2.Username)
+	require.Equal(t, hashedPassword, user2.HashedPassword)
+	require.True(t, user2.PasswordChangedAt.Time.After(user1.PasswordChangedAt.Time))
+
+	passwordChangedAt, err := testQueries.GetUserPasswordChangedAt(context.Background(), user1.Username)
+	require.NoError(t, err)
+	require.Equal(t, user2.PasswordChangedAt, passwordChangedAt)
+}
+
+func TestUpdateUserFrozen(t *testing.T) {
+	user1 := createRandomUser(t)
+
+	user2, err := testQueries.UpdateUserFrozen(context.Background(), db.UpdateUserFrozenParams{
+		IsFrozen: true,
+		Username: user1.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, user1.Username, user2.Username)
+	require.True(t, user2.IsFrozen)
+}
+
+func TestListUsers(t *testing.T) {
+	user := createRandomUser(t)
+
+	users, err := testQueries.ListUsers(context.Background(), db.ListUsersParams{
+		Search: user.Username,
+		Limit:  10,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, users)
+
+	for _, u := range users {
+		require.Contains(t, u.Username, user.Username)
+	}
+}
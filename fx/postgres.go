@@ -0,0 +1,55 @@
+package fx
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+)
+
+// PostgresProvider serves the rates kept in the fx_rates table, so they can
+// be updated without restarting the server.
+type PostgresProvider struct {
+	querier db.Querier
+}
+
+func NewPostgresProvider(querier db.Querier) RateProvider {
+	return &PostgresProvider{
+		querier: querier,
+	}
+}
+
+func (p *PostgresProvider) Rate(ctx context.Context, from string, to string) (Rate, error) {
+	rate, err := p.rate(ctx, from, to)
+	if !errors.Is(err, ErrRateNotFound) {
+		return rate, err
+	}
+
+	rate, err = p.rate(ctx, to, from)
+	if err != nil {
+		return Rate{}, err
+	}
+	return rate.inverse(), nil
+}
+
+func (p *PostgresProvider) rate(ctx context.Context, from string, to string) (Rate, error) {
+	fxRate, err := p.querier.GetFxRate(ctx, db.GetFxRateParams{
+		BaseCurrency:  from,
+		QuoteCurrency: to,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Rate{}, ErrRateNotFound
+		}
+		return Rate{}, err
+	}
+
+	value, err := utils.NumericToRat(fxRate.Rate)
+	if err != nil {
+		return Rate{}, err
+	}
+
+	return Rate{From: from, To: to, Value: value}, nil
+}
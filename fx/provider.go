@@ -0,0 +1,31 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"math/big"
+)
+
+var ErrRateNotFound = errors.New("exchange rate not found")
+
+// Rate converts an amount in From into To: to = from * Value.
+type Rate struct {
+	From  string
+	To    string
+	Value *big.Rat
+}
+
+// RateProvider tells the current exchange rate between two currencies.
+type RateProvider interface {
+	Rate(ctx context.Context, from string, to string) (Rate, error)
+}
+
+// inverse returns the rate of the opposite direction, it is used when a
+// provider only knows one side of a currency pair.
+func (r Rate) inverse() Rate {
+	return Rate{
+		From:  r.To,
+		To:    r.From,
+		Value: new(big.Rat).Inv(r.Value),
+	}
+}
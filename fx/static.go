@@ -0,0 +1,62 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/mohammad19khodaei/simple_bank/utils"
+)
+
+// StaticProvider serves rates that are fixed at startup, typically loaded from
+// a JSON file shaped as {"USD": {"EUR": "0.92"}}.
+type StaticProvider struct {
+	rates map[string]map[string]*big.Rat
+}
+
+func NewStaticProvider(rates map[string]map[string]string) (RateProvider, error) {
+	provider := &StaticProvider{
+		rates: make(map[string]map[string]*big.Rat),
+	}
+
+	for from, quotes := range rates {
+		provider.rates[from] = make(map[string]*big.Rat)
+		for to, value := range quotes {
+			rate, err := utils.ParseRate(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s/%s: %w", from, to, err)
+			}
+			provider.rates[from][to] = rate
+		}
+	}
+
+	return provider, nil
+}
+
+func NewStaticProviderFromFile(path string) (RateProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rates map[string]map[string]string
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+
+	return NewStaticProvider(rates)
+}
+
+func (p *StaticProvider) Rate(_ context.Context, from string, to string) (Rate, error) {
+	if value, ok := p.rates[from][to]; ok {
+		return Rate{From: from, To: to, Value: value}, nil
+	}
+
+	if value, ok := p.rates[to][from]; ok {
+		return Rate{From: to, To: from, Value: value}.inverse(), nil
+	}
+
+	return Rate{}, ErrRateNotFound
+}
@@ -0,0 +1,58 @@
+package fx_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mohammad19khodaei/simple_bank/fx"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticProvider(t *testing.T) {
+	provider, err := fx.NewStaticProvider(map[string]map[string]string{
+		"USD": {"EUR": "0.8"},
+	})
+	require.NoError(t, err)
+
+	rate, err := provider.Rate(context.Background(), "USD", "EUR")
+	require.NoError(t, err)
+	require.Equal(t, "USD", rate.From)
+	require.Equal(t, "EUR", rate.To)
+	require.Equal(t, "0.8", utils.FormatRate(rate.Value))
+
+	// the opposite direction is derived from the known one
+	rate, err = provider.Rate(context.Background(), "EUR", "USD")
+	require.NoError(t, err)
+	require.Equal(t, "EUR", rate.From)
+	require.Equal(t, "USD", rate.To)
+	require.Equal(t, "1.25", utils.FormatRate(rate.Value))
+
+	_, err = provider.Rate(context.Background(), "USD", "IRR")
+	require.ErrorIs(t, err, fx.ErrRateNotFound)
+}
+
+func TestStaticProviderInvalidRate(t *testing.T) {
+	_, err := fx.NewStaticProvider(map[string]map[string]string{
+		"USD": {"EUR": "-1"},
+	})
+	require.Error(t, err)
+}
+
+func TestStaticProviderFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	err := os.WriteFile(path, []byte(`{"USD": {"IRR": "42000"}}`), 0o600)
+	require.NoError(t, err)
+
+	provider, err := fx.NewStaticProviderFromFile(path)
+	require.NoError(t, err)
+
+	rate, err := provider.Rate(context.Background(), "USD", "IRR")
+	require.NoError(t, err)
+	require.Equal(t, "42000", utils.FormatRate(rate.Value))
+
+	_, err = fx.NewStaticProviderFromFile(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+}
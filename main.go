@@ -3,13 +3,18 @@ package main
 import (
 	"context"
 	"log"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/mohammad19khodaei/simple_bank/api"
 	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/scheduler"
 	"github.com/mohammad19khodaei/simple_bank/utils"
 )
 
+// expiredHoldsBatch bounds the holds released by a single transaction.
+const expiredHoldsBatch = 100
+
 func main() {
 	config, err := utils.LoadConfig(".", "app")
 	if err != nil {
@@ -21,12 +26,96 @@ func main() {
 		log.Fatal(err)
 	}
 
-	server, err := api.NewServer(config, db.NewStore(connPool))
+	store := db.NewStore(connPool)
+
+	server, err := api.NewServer(config, store)
 	if err != nil {
 		log.Fatal("could not create start", err)
 	}
 
+	if config.RevocationStore == "postgres" && config.RevocationCleanupInterval > 0 {
+		go deleteExpiredRevokedTokens(context.Background(), store, config.RevocationCleanupInterval)
+	}
+
+	if config.IdempotencyCleanupInterval > 0 {
+		go deleteExpiredIdempotencyKeys(context.Background(), store, config.IdempotencyCleanupInterval)
+	}
+
+	if config.HoldExpiryInterval > 0 {
+		go expireHolds(context.Background(), store, config.HoldExpiryInterval)
+	}
+
+	if config.SchedulerInterval > 0 {
+		go scheduler.New(store, config.SchedulerInterval).Run(context.Background())
+	}
+
 	if err := server.Start(config.ServerAddress); err != nil {
 		log.Fatal("could not start server", err)
 	}
 }
+
+// expireHolds releases the holds past their expiry every interval until ctx
+// is done.
+func expireHolds(ctx context.Context, store db.Store, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// keep going while full batches come back
+		for {
+			released, err := store.ExpireHoldsTx(ctx, expiredHoldsBatch)
+			if err != nil {
+				log.Println("could not expire holds", err)
+				break
+			}
+			if released < expiredHoldsBatch {
+				break
+			}
+		}
+	}
+}
+
+// deleteExpiredRevokedTokens drops the revoked tokens past their expiry every
+// interval until ctx is done. Expired tokens are rejected anyway, keeping
+// them would only grow the table.
+func deleteExpiredRevokedTokens(ctx context.Context, store db.Store, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := store.DeleteExpiredRevokedTokens(ctx); err != nil {
+			log.Println("could not delete expired revoked tokens", err)
+		}
+	}
+}
+
+// deleteExpiredIdempotencyKeys drops the idempotency keys past their expiry
+// every interval until ctx is done. Expired keys are no longer replayed.
+func deleteExpiredIdempotencyKeys(ctx context.Context, store db.Store, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := store.DeleteExpiredIdempotencyKeys(ctx); err != nil {
+			log.Println("could not delete expired idempotency keys", err)
+		}
+	}
+}
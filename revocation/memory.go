@@ -0,0 +1,62 @@
+package revocation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mohammad19khodaei/simple_bank/token"
+)
+
+type MemoryStore struct {
+	mu            sync.RWMutex
+	revokedTokens map[string]time.Time
+	revokedUsers  map[string]time.Time
+}
+
+func NewMemoryStore() Store {
+	return &MemoryStore{
+		revokedTokens: make(map[string]time.Time),
+		revokedUsers:  make(map[string]time.Time),
+	}
+}
+
+func (s *MemoryStore) Revoke(_ context.Context, payload *token.Payload) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for id, expiresAt := range s.revokedTokens {
+		if now.After(expiresAt) {
+			delete(s.revokedTokens, id)
+		}
+	}
+
+	s.revokedTokens[payload.ID] = payload.ExpiresAt.Time
+	return nil
+}
+
+func (s *MemoryStore) RevokeUser(_ context.Context, username string, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cutoff, ok := s.revokedUsers[username]; !ok || before.After(cutoff) {
+		s.revokedUsers[username] = before
+	}
+	return nil
+}
+
+func (s *MemoryStore) IsRevoked(_ context.Context, payload *token.Payload) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.revokedTokens[payload.ID]; ok {
+		return true, nil
+	}
+
+	if cutoff, ok := s.revokedUsers[payload.Username]; ok && issuedBefore(payload, cutoff) {
+		return true, nil
+	}
+
+	return false, nil
+}
@@ -0,0 +1,60 @@
+package revocation_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mohammad19khodaei/simple_bank/revocation"
+	"github.com/mohammad19khodaei/simple_bank/token"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStoreRevoke(t *testing.T) {
+	store := revocation.NewMemoryStore()
+
+	payload1, err := token.NewPayload(utils.RandomOwner(), utils.RoleCustomer, time.Minute, token.TokenTypeAccess)
+	require.NoError(t, err)
+	payload2, err := token.NewPayload(payload1.Username, utils.RoleCustomer, time.Minute, token.TokenTypeAccess)
+	require.NoError(t, err)
+
+	err = store.Revoke(context.Background(), payload1)
+	require.NoError(t, err)
+
+	revoked, err := store.IsRevoked(context.Background(), payload1)
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	revoked, err = store.IsRevoked(context.Background(), payload2)
+	require.NoError(t, err)
+	require.False(t, revoked)
+}
+
+func TestMemoryStoreRevokeUser(t *testing.T) {
+	store := revocation.NewMemoryStore()
+
+	payload, err := token.NewPayload(utils.RandomOwner(), utils.RoleCustomer, time.Minute, token.TokenTypeAccess)
+	require.NoError(t, err)
+	otherPayload, err := token.NewPayload(utils.RandomOwner(), utils.RoleCustomer, time.Minute, token.TokenTypeAccess)
+	require.NoError(t, err)
+
+	err = store.RevokeUser(context.Background(), payload.Username, time.Now().Add(time.Second))
+	require.NoError(t, err)
+
+	revoked, err := store.IsRevoked(context.Background(), payload)
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	revoked, err = store.IsRevoked(context.Background(), otherPayload)
+	require.NoError(t, err)
+	require.False(t, revoked)
+
+	laterPayload, err := token.NewPayload(payload.Username, utils.RoleCustomer, time.Minute, token.TokenTypeAccess)
+	require.NoError(t, err)
+	laterPayload.IssuedAt.Time = time.Now().Add(2 * time.Second)
+
+	revoked, err = store.IsRevoked(context.Background(), laterPayload)
+	require.NoError(t, err)
+	require.False(t, revoked)
+}
@@ -0,0 +1,54 @@
+package revocation
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/token"
+)
+
+type PostgresStore struct {
+	querier db.Querier
+}
+
+func NewPostgresStore(querier db.Querier) Store {
+	return &PostgresStore{
+		querier: querier,
+	}
+}
+
+func (s *PostgresStore) Revoke(ctx context.Context, payload *token.Payload) error {
+	id, err := uuid.Parse(payload.ID)
+	if err != nil {
+		return token.ErrInvalidToken
+	}
+
+	return s.querier.CreateRevokedToken(ctx, db.CreateRevokedTokenParams{
+		ID:        pgtype.UUID{Bytes: id, Valid: true},
+		Username:  payload.Username,
+		ExpiresAt: pgtype.Timestamptz{Time: payload.ExpiresAt.Time, Valid: true},
+	})
+}
+
+func (s *PostgresStore) RevokeUser(ctx context.Context, username string, before time.Time) error {
+	return s.querier.UpsertUserTokenRevocation(ctx, db.UpsertUserTokenRevocationParams{
+		Username:      username,
+		RevokedBefore: pgtype.Timestamptz{Time: before, Valid: true},
+	})
+}
+
+func (s *PostgresStore) IsRevoked(ctx context.Context, payload *token.Payload) (bool, error) {
+	id, err := uuid.Parse(payload.ID)
+	if err != nil {
+		return false, token.ErrInvalidToken
+	}
+
+	return s.querier.IsTokenRevoked(ctx, db.IsTokenRevokedParams{
+		ID:       pgtype.UUID{Bytes: id, Valid: true},
+		Username: payload.Username,
+		IssuedAt: pgtype.Timestamptz{Time: payload.IssuedAt.Time, Valid: true},
+	})
+}
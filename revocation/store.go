@@ -0,0 +1,24 @@
+package revocation
+
+import (
+	"context"
+	"time"
+
+	"github.com/mohammad19khodaei/simple_bank/token"
+)
+
+// Store keeps track of tokens that must be rejected before they expire.
+type Store interface {
+	// Revoke rejects the single token identified by payload.ID.
+	Revoke(ctx context.Context, payload *token.Payload) error
+	// RevokeUser rejects every token of username issued before the given time.
+	RevokeUser(ctx context.Context, username string, before time.Time) error
+	IsRevoked(ctx context.Context, payload *token.Payload) (bool, error)
+}
+
+// issuedBefore reports whether the token was issued before cutoff. IssuedAt
+// only carries second precision, so the cutoff is truncated the same way and
+// tokens minted within the cutoff second stay valid.
+func issuedBefore(payload *token.Payload, cutoff time.Time) bool {
+	return payload.IssuedAt.Time.Before(cutoff.Truncate(time.Second))
+}
@@ -0,0 +1,223 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+)
+
+// batchSize bounds the scheduled transfers claimed by a single transaction.
+const batchSize = 50
+
+// staleRunAge is how long a claimed run may stay pending before another
+// scheduler takes it again. It is well above the time a batch takes.
+const staleRunAge = 10 * time.Minute
+
+// runKeyDuration is how long the idempotency key of a run is kept. A run is
+// taken again within staleRunAge while a scheduler is up, the margin covers
+// schedulers that stay down for long.
+const runKeyDuration = 365 * 24 * time.Hour
+
+var (
+	errOwnerFrozen = errors.New("owner is frozen")
+	errRunKeyTaken = errors.New("idempotency key of the run was used by another request")
+)
+
+// Scheduler polls the store for due scheduled transfers and makes them with
+// TransferTx. Claims go through ClaimScheduledTransfersTx, so any number of
+// replicas can run a Scheduler against the same database.
+type Scheduler struct {
+	store    db.Store
+	interval time.Duration
+}
+
+func New(store db.Store, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		store:    store,
+		interval: interval,
+	}
+}
+
+// Run makes the due transfers every interval until ctx is done. The runs
+// left pending by a scheduler that stopped are made first.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// keep going while full batches come back
+		for {
+			claimed, err := s.RunStale(ctx)
+			if err != nil {
+				log.Println("could not run stale scheduled transfer runs", err)
+				break
+			}
+			if claimed < batchSize {
+				break
+			}
+		}
+
+		for {
+			claimed, err := s.RunDue(ctx)
+			if err != nil {
+				log.Println("could not run scheduled transfers", err)
+				break
+			}
+			if claimed < batchSize {
+				break
+			}
+		}
+	}
+}
+
+// RunDue claims a batch of due scheduled transfers, makes them and records
+// the outcome of each run. It reports how many runs it claimed.
+func (s *Scheduler) RunDue(ctx context.Context) (int, error) {
+	claims, err := s.store.ClaimScheduledTransfersTx(ctx, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, claim := range claims {
+		s.execute(ctx, claim)
+	}
+
+	return len(claims), nil
+}
+
+// RunStale takes again a batch of runs that stayed pending, as the scheduler
+// that claimed them stopped before recording their outcome, and finishes
+// them. A run whose transfer was made is recorded without making it again.
+// It reports how many runs it claimed.
+func (s *Scheduler) RunStale(ctx context.Context) (int, error) {
+	claims, err := s.store.ClaimStaleScheduledTransferRunsTx(ctx, time.Now().Add(-staleRunAge), batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, claim := range claims {
+		result, err := s.madeTransfer(ctx, runIdempotency(claim))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				s.execute(ctx, claim)
+				continue
+			}
+			if errors.Is(err, errRunKeyTaken) {
+				s.finish(ctx, claim, result, err)
+				continue
+			}
+			log.Printf("could not recover run %d of scheduled transfer %d: %v", claim.Run.ID, claim.ScheduledTransfer.ID, err)
+			continue
+		}
+
+		s.finish(ctx, claim, result, nil)
+	}
+
+	return len(claims), nil
+}
+
+func (s *Scheduler) execute(ctx context.Context, claim db.ScheduledTransferClaim) {
+	idempotency := runIdempotency(claim)
+
+	result, err := s.transfer(ctx, claim.ScheduledTransfer, idempotency)
+	if errors.Is(err, db.ErrIdempotencyKeyExists) {
+		// another scheduler took the run again and made it meanwhile
+		result, err = s.madeTransfer(ctx, idempotency)
+	}
+	// a run cut short by shutdown stays pending and is taken again once
+	// stale, it must not be recorded as failed
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+
+	s.finish(ctx, claim, result, err)
+}
+
+// finish records the outcome of a run. It is recorded even when ctx was
+// cancelled meanwhile, the transfer was made by then.
+func (s *Scheduler) finish(ctx context.Context, claim db.ScheduledTransferClaim, result db.TransferTxResult, err error) {
+	finish := db.FinishScheduledTransferRunParams{
+		ID:     claim.Run.ID,
+		Status: utils.ScheduledRunStatusSucceeded,
+	}
+
+	if err != nil {
+		finish.Status = utils.ScheduledRunStatusFailed
+		finish.Error = err.Error()
+	} else {
+		finish.TransferID = pgtype.Int4{Int32: result.Transfer.ID, Valid: true}
+	}
+
+	if _, err := s.store.FinishScheduledTransferRun(context.WithoutCancel(ctx), finish); err != nil {
+		log.Printf("could not record run %d of scheduled transfer %d: %v", claim.Run.ID, claim.ScheduledTransfer.ID, err)
+	}
+}
+
+// transfer makes a scheduled transfer on behalf of its owner. Balance,
+// currency and status are checked by TransferTx as for any other transfer.
+func (s *Scheduler) transfer(ctx context.Context, scheduled db.ScheduledTransfer, idempotency *db.TransferIdempotency) (db.TransferTxResult, error) {
+	owner, err := s.store.GetUser(ctx, scheduled.Owner)
+	if err != nil {
+		return db.TransferTxResult{}, err
+	}
+	if owner.IsFrozen {
+		return db.TransferTxResult{}, errOwnerFrozen
+	}
+
+	return s.store.TransferTx(ctx, db.TransferTxParams{
+		FromAccountID: scheduled.FromAccountID,
+		ToAccountID:   scheduled.ToAccountID,
+		Amount:        scheduled.Amount,
+		Description:   scheduled.Description,
+		Reference:     scheduled.Reference,
+		Idempotency:   idempotency,
+	})
+}
+
+// runIdempotency ties the transfer of a run to the run, so a run executed
+// again cannot move the money twice. Clients send a hash of their request
+// along with their keys, the key itself stands for the hash here so a client
+// can never pass its transfer for the one of a run.
+func runIdempotency(claim db.ScheduledTransferClaim) *db.TransferIdempotency {
+	key := fmt.Sprintf("scheduled-transfer-run-%d", claim.Run.ID)
+	return &db.TransferIdempotency{
+		Username:    claim.ScheduledTransfer.Owner,
+		Key:         key,
+		RequestHash: key,
+		Duration:    runKeyDuration,
+	}
+}
+
+// madeTransfer returns the transfer already made with idempotency, it fails
+// with pgx.ErrNoRows when there is none.
+func (s *Scheduler) madeTransfer(ctx context.Context, idempotency *db.TransferIdempotency) (db.TransferTxResult, error) {
+	var result db.TransferTxResult
+
+	stored, err := s.store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{
+		Username: idempotency.Username,
+		Key:      idempotency.Key,
+	})
+	if err != nil {
+		return result, err
+	}
+	if stored.RequestHash != idempotency.RequestHash {
+		return result, errRunKeyTaken
+	}
+
+	err = json.Unmarshal(stored.Response, &result)
+	return result, err
+}
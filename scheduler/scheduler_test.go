@@ -0,0 +1,320 @@
+package scheduler_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	mockdb "github.com/mohammad19khodaei/simple_bank/db/mock"
+	db "github.com/mohammad19khodaei/simple_bank/db/sqlc"
+	"github.com/mohammad19khodaei/simple_bank/scheduler"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRunDue(t *testing.T) {
+	owner := db.User{Username: utils.RandomOwner()}
+	frozenOwner := db.User{Username: utils.RandomOwner(), IsFrozen: true}
+
+	scheduled := db.ScheduledTransfer{
+		ID:            1,
+		Owner:         owner.Username,
+		FromAccountID: 10,
+		ToAccountID:   20,
+		Amount:        100,
+		Currency:      "USD",
+		Description:   "rent",
+		Reference:     "flat-3b",
+		Schedule:      "0 9 1 * *",
+	}
+
+	testCases := []struct {
+		name       string
+		claims     []db.ScheduledTransferClaim
+		buildStubs func(store *mockdb.MockStore)
+	}{
+		{
+			name: "succeeded",
+			claims: []db.ScheduledTransferClaim{
+				{ScheduledTransfer: scheduled, Run: db.ScheduledTransferRun{ID: 7}},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(owner.Username)).Times(1).Return(owner, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(db.TransferTxParams{
+						FromAccountID: scheduled.FromAccountID,
+						ToAccountID:   scheduled.ToAccountID,
+						Amount:        scheduled.Amount,
+						Description:   scheduled.Description,
+						Reference:     scheduled.Reference,
+						Idempotency: &db.TransferIdempotency{
+							Username:    owner.Username,
+							Key:         "scheduled-transfer-run-7",
+							RequestHash: "scheduled-transfer-run-7",
+							Duration:    365 * 24 * time.Hour,
+						},
+					})).
+					Times(1).
+					Return(db.TransferTxResult{Transfer: db.Transfer{ID: 42}}, nil)
+				store.EXPECT().
+					FinishScheduledTransferRun(gomock.Any(), gomock.Eq(db.FinishScheduledTransferRunParams{
+						ID:         7,
+						Status:     utils.ScheduledRunStatusSucceeded,
+						TransferID: pgtype.Int4{Int32: 42, Valid: true},
+					})).
+					Times(1)
+			},
+		},
+		{
+			name: "transfer failed",
+			claims: []db.ScheduledTransferClaim{
+				{ScheduledTransfer: scheduled, Run: db.ScheduledTransferRun{ID: 8}},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(owner.Username)).Times(1).Return(owner, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrInsufficientBalance)
+				store.EXPECT().
+					FinishScheduledTransferRun(gomock.Any(), gomock.Eq(db.FinishScheduledTransferRunParams{
+						ID:     8,
+						Status: utils.ScheduledRunStatusFailed,
+						Error:  db.ErrInsufficientBalance.Error(),
+					})).
+					Times(1)
+			},
+		},
+		{
+			name: "made by another scheduler meanwhile",
+			claims: []db.ScheduledTransferClaim{
+				{ScheduledTransfer: scheduled, Run: db.ScheduledTransferRun{ID: 10}},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(owner.Username)).Times(1).Return(owner, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrIdempotencyKeyExists)
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Eq(db.GetIdempotencyKeyParams{
+						Username: owner.Username,
+						Key:      "scheduled-transfer-run-10",
+					})).
+					Times(1).
+					Return(storedRun(t, owner.Username, 10, 43), nil)
+				store.EXPECT().
+					FinishScheduledTransferRun(gomock.Any(), gomock.Eq(db.FinishScheduledTransferRunParams{
+						ID:         10,
+						Status:     utils.ScheduledRunStatusSucceeded,
+						TransferID: pgtype.Int4{Int32: 43, Valid: true},
+					})).
+					Times(1)
+			},
+		},
+		{
+			name: "frozen owner",
+			claims: []db.ScheduledTransferClaim{
+				{
+					ScheduledTransfer: db.ScheduledTransfer{ID: 2, Owner: frozenOwner.Username},
+					Run:               db.ScheduledTransferRun{ID: 9},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(frozenOwner.Username)).Times(1).Return(frozenOwner, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					FinishScheduledTransferRun(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, params db.FinishScheduledTransferRunParams) (db.ScheduledTransferRun, error) {
+						require.Equal(t, utils.ScheduledRunStatusFailed, params.Status)
+						require.NotEmpty(t, params.Error)
+						return db.ScheduledTransferRun{}, nil
+					})
+			},
+		},
+		{
+			name:       "nothing due",
+			buildStubs: func(store *mockdb.MockStore) {},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().ClaimScheduledTransfersTx(gomock.Any(), gomock.Any()).Times(1).Return(tc.claims, nil)
+			tc.buildStubs(store)
+
+			claimed, err := scheduler.New(store, 0).RunDue(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, len(tc.claims), claimed)
+		})
+	}
+}
+
+func TestRunDueShutdown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	owner := db.User{Username: utils.RandomOwner()}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ClaimScheduledTransfersTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]db.ScheduledTransferClaim{
+			{ScheduledTransfer: db.ScheduledTransfer{ID: 1, Owner: owner.Username}, Run: db.ScheduledTransferRun{ID: 7}},
+		}, nil)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(owner.Username)).Times(1).Return(owner, nil)
+	store.EXPECT().
+		TransferTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, _ db.TransferTxParams) (db.TransferTxResult, error) {
+			cancel()
+			return db.TransferTxResult{}, context.Canceled
+		})
+	// the run stays pending to be taken again once stale
+	store.EXPECT().FinishScheduledTransferRun(gomock.Any(), gomock.Any()).Times(0)
+
+	claimed, err := scheduler.New(store, 0).RunDue(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, claimed)
+}
+
+func TestRunStale(t *testing.T) {
+	owner := db.User{Username: utils.RandomOwner()}
+
+	scheduled := db.ScheduledTransfer{
+		ID:            1,
+		Owner:         owner.Username,
+		FromAccountID: 10,
+		ToAccountID:   20,
+		Amount:        100,
+		Currency:      "USD",
+		Schedule:      "0 9 1 * *",
+	}
+
+	testCases := []struct {
+		name       string
+		claims     []db.ScheduledTransferClaim
+		buildStubs func(store *mockdb.MockStore)
+	}{
+		{
+			name: "transfer was made",
+			claims: []db.ScheduledTransferClaim{
+				{ScheduledTransfer: scheduled, Run: db.ScheduledTransferRun{ID: 7}},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Eq(db.GetIdempotencyKeyParams{
+						Username: owner.Username,
+						Key:      "scheduled-transfer-run-7",
+					})).
+					Times(1).
+					Return(storedRun(t, owner.Username, 7, 42), nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					FinishScheduledTransferRun(gomock.Any(), gomock.Eq(db.FinishScheduledTransferRunParams{
+						ID:         7,
+						Status:     utils.ScheduledRunStatusSucceeded,
+						TransferID: pgtype.Int4{Int32: 42, Valid: true},
+					})).
+					Times(1)
+			},
+		},
+		{
+			name: "transfer was not made",
+			claims: []db.ScheduledTransferClaim{
+				{ScheduledTransfer: scheduled, Run: db.ScheduledTransferRun{ID: 8}},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{}, pgx.ErrNoRows)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(owner.Username)).Times(1).Return(owner, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, params db.TransferTxParams) (db.TransferTxResult, error) {
+						require.NotNil(t, params.Idempotency)
+						require.Equal(t, "scheduled-transfer-run-8", params.Idempotency.Key)
+						return db.TransferTxResult{Transfer: db.Transfer{ID: 44}}, nil
+					})
+				store.EXPECT().
+					FinishScheduledTransferRun(gomock.Any(), gomock.Eq(db.FinishScheduledTransferRunParams{
+						ID:         8,
+						Status:     utils.ScheduledRunStatusSucceeded,
+						TransferID: pgtype.Int4{Int32: 44, Valid: true},
+					})).
+					Times(1)
+			},
+		},
+		{
+			name: "key taken by a client",
+			claims: []db.ScheduledTransferClaim{
+				{ScheduledTransfer: scheduled, Run: db.ScheduledTransferRun{ID: 9}},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{Username: owner.Username, Key: "scheduled-transfer-run-9", RequestHash: "abc"}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					FinishScheduledTransferRun(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, params db.FinishScheduledTransferRunParams) (db.ScheduledTransferRun, error) {
+						require.Equal(t, utils.ScheduledRunStatusFailed, params.Status)
+						require.NotEmpty(t, params.Error)
+						return db.ScheduledTransferRun{}, nil
+					})
+			},
+		},
+		{
+			name:       "nothing stale",
+			buildStubs: func(store *mockdb.MockStore) {},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				ClaimStaleScheduledTransferRunsTx(gomock.Any(), gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(tc.claims, nil)
+			tc.buildStubs(store)
+
+			claimed, err := scheduler.New(store, 0).RunStale(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, len(tc.claims), claimed)
+		})
+	}
+}
+
+// storedRun is the idempotency key TransferTx stores for the transfer of a run.
+func storedRun(t *testing.T, username string, runID, transferID int32) db.IdempotencyKey {
+	response, err := json.Marshal(db.TransferTxResult{Transfer: db.Transfer{ID: transferID}})
+	require.NoError(t, err)
+
+	key := fmt.Sprintf("scheduled-transfer-run-%d", runID)
+	return db.IdempotencyKey{
+		Username:    username,
+		Key:         key,
+		RequestHash: key,
+		Response:    response,
+	}
+}
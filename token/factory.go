@@ -0,0 +1,83 @@
+package token
+
+import (
+	"fmt"
+
+	"github.com/mohammad19khodaei/simple_bank/utils"
+)
+
+const (
+	TypePaseto       = "paseto"
+	TypeJWT          = "jwt"
+	TypePasetoPublic = "paseto_public"
+	TypeJWTEdDSA     = "jwt_eddsa"
+)
+
+// NewMaker builds the Maker selected by TOKEN_TYPE. Invalid settings are
+// reported by their config name so a misconfigured deployment fails at startup.
+func NewMaker(config utils.Config) (Maker, error) {
+	switch config.TokenType {
+	case "", TypePaseto:
+		return newSymmetricMaker(config, NewPasetoKeyringMaker)
+	case TypeJWT:
+		return newSymmetricMaker(config, NewJWTKeyringMaker)
+	case TypePasetoPublic:
+		return newAsymmetricMaker(config, NewPasetoPublicMaker)
+	case TypeJWTEdDSA:
+		return newAsymmetricMaker(config, NewJWTEdDSAMaker)
+	default:
+		return nil, fmt.Errorf("invalid TOKEN_TYPE %q, must be one of %s, %s, %s or %s", config.TokenType, TypePaseto, TypeJWT, TypePasetoPublic, TypeJWTEdDSA)
+	}
+}
+
+func newSymmetricMaker(config utils.Config, newMaker func(keyring *Keyring) (Maker, error)) (Maker, error) {
+	current := Key{ID: config.SecretKeyID, Secret: config.SecretKey}
+
+	currentKeyring, err := NewKeyring(current)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SECRET_KEY_ID: %w", err)
+	}
+	if _, err := newMaker(currentKeyring); err != nil {
+		return nil, fmt.Errorf("invalid SECRET_KEY: %w", err)
+	}
+
+	previousKeys, err := ParseKeys(config.PreviousSecretKeys)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PREVIOUS_SECRET_KEYS: %w", err)
+	}
+
+	keyring, err := NewKeyring(current, previousKeys...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PREVIOUS_SECRET_KEYS: %w", err)
+	}
+
+	maker, err := newMaker(keyring)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PREVIOUS_SECRET_KEYS: %w", err)
+	}
+
+	return maker, nil
+}
+
+func newAsymmetricMaker(config utils.Config, newMaker func(keyring *PublicKeyring) (Maker, error)) (Maker, error) {
+	if config.TokenPrivateKey == "" {
+		return nil, fmt.Errorf("TOKEN_PRIVATE_KEY is required when TOKEN_TYPE is %s", config.TokenType)
+	}
+
+	privateKey, err := ParsePrivateKey(config.TokenPrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TOKEN_PRIVATE_KEY: %w", err)
+	}
+
+	previousKeys, err := ParseVerificationKeys(config.PreviousTokenPublicKeys)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PREVIOUS_TOKEN_PUBLIC_KEYS: %w", err)
+	}
+
+	keyring, err := NewPublicKeyring(SigningKey{ID: config.TokenPrivateKeyID, PrivateKey: privateKey}, previousKeys...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PREVIOUS_TOKEN_PUBLIC_KEYS: %w", err)
+	}
+
+	return newMaker(keyring)
+}
@@ -0,0 +1,107 @@
+package token_test
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"testing"
+
+	"github.com/mohammad19khodaei/simple_bank/token"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMaker(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	encodedPrivateKey := base64.StdEncoding.EncodeToString(privateKey.Seed())
+
+	testCases := []struct {
+		name        string
+		config      utils.Config
+		checkResult func(t *testing.T, maker token.Maker, err error)
+	}{
+		{
+			name:   "default type is paseto",
+			config: utils.Config{SecretKey: utils.RandomString(32)},
+			checkResult: func(t *testing.T, maker token.Maker, err error) {
+				require.NoError(t, err)
+				require.IsType(t, &token.PasetoMaker{}, maker)
+			},
+		},
+		{
+			name:   "jwt",
+			config: utils.Config{TokenType: token.TypeJWT, SecretKey: utils.RandomString(40)},
+			checkResult: func(t *testing.T, maker token.Maker, err error) {
+				require.NoError(t, err)
+				require.IsType(t, &token.JWTMaker{}, maker)
+			},
+		},
+		{
+			name:   "paseto public",
+			config: utils.Config{TokenType: token.TypePasetoPublic, TokenPrivateKey: encodedPrivateKey},
+			checkResult: func(t *testing.T, maker token.Maker, err error) {
+				require.NoError(t, err)
+				require.IsType(t, &token.PasetoPublicMaker{}, maker)
+			},
+		},
+		{
+			name:   "jwt eddsa",
+			config: utils.Config{TokenType: token.TypeJWTEdDSA, TokenPrivateKey: encodedPrivateKey},
+			checkResult: func(t *testing.T, maker token.Maker, err error) {
+				require.NoError(t, err)
+				require.IsType(t, &token.JWTEdDSAMaker{}, maker)
+			},
+		},
+		{
+			name:   "unknown type",
+			config: utils.Config{TokenType: "basic", SecretKey: utils.RandomString(32)},
+			checkResult: func(t *testing.T, _ token.Maker, err error) {
+				require.ErrorContains(t, err, "TOKEN_TYPE")
+			},
+		},
+		{
+			name:   "invalid secret key",
+			config: utils.Config{TokenType: token.TypePaseto, SecretKey: utils.RandomString(10)},
+			checkResult: func(t *testing.T, _ token.Maker, err error) {
+				require.ErrorContains(t, err, "SECRET_KEY")
+				require.NotContains(t, err.Error(), "PREVIOUS_SECRET_KEYS")
+			},
+		},
+		{
+			name: "invalid previous secret key",
+			config: utils.Config{
+				TokenType:          token.TypePaseto,
+				SecretKey:          utils.RandomString(32),
+				PreviousSecretKeys: "old:" + utils.RandomString(10),
+			},
+			checkResult: func(t *testing.T, _ token.Maker, err error) {
+				require.ErrorContains(t, err, "PREVIOUS_SECRET_KEYS")
+			},
+		},
+		{
+			name:   "missing private key",
+			config: utils.Config{TokenType: token.TypeJWTEdDSA},
+			checkResult: func(t *testing.T, _ token.Maker, err error) {
+				require.ErrorContains(t, err, "TOKEN_PRIVATE_KEY")
+			},
+		},
+		{
+			name: "invalid previous public key",
+			config: utils.Config{
+				TokenType:               token.TypePasetoPublic,
+				TokenPrivateKey:         encodedPrivateKey,
+				PreviousTokenPublicKeys: "old:" + base64.StdEncoding.EncodeToString([]byte("short")),
+			},
+			checkResult: func(t *testing.T, _ token.Maker, err error) {
+				require.ErrorContains(t, err, "PREVIOUS_TOKEN_PUBLIC_KEYS")
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			maker, err := token.NewMaker(tc.config)
+			tc.checkResult(t, maker, err)
+		})
+	}
+}
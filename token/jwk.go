@@ -0,0 +1,36 @@
+package token
+
+import (
+	"encoding/base64"
+)
+
+// JWK is the JSON Web Key representation of an Ed25519 verification key.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Curve     string `json:"crv"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	X         string `json:"x"`
+}
+
+// PublicKeyProvider is implemented by makers whose tokens can be verified
+// without holding any signing material.
+type PublicKeyProvider interface {
+	PublicKeys() []JWK
+}
+
+func newJWKs(keys []VerificationKey, algorithm string) []JWK {
+	jwks := make([]JWK, 0, len(keys))
+	for _, key := range keys {
+		jwks = append(jwks, JWK{
+			KeyType:   "OKP",
+			Curve:     "Ed25519",
+			KeyID:     key.ID,
+			Use:       "sig",
+			Algorithm: algorithm,
+			X:         base64.RawURLEncoding.EncodeToString(key.PublicKey),
+		})
+	}
+	return jwks
+}
@@ -0,0 +1,66 @@
+package token
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTEdDSAMaker issues JWTs signed with Ed25519.
+type JWTEdDSAMaker struct {
+	keyring *PublicKeyring
+}
+
+func NewJWTEdDSAMaker(keyring *PublicKeyring) (Maker, error) {
+	return &JWTEdDSAMaker{
+		keyring: keyring,
+	}, nil
+}
+
+func (m *JWTEdDSAMaker) GenerateToken(username string, role string, duration time.Duration, tokenType TokenType) (string, *Payload, error) {
+	payload, err := NewPayload(username, role, duration, tokenType)
+	if err != nil {
+		return "", nil, err
+	}
+
+	key := m.keyring.Current()
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, payload)
+	token.Header["kid"] = key.ID
+	tokenString, err := token.SignedString(key.PrivateKey)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return tokenString, payload, nil
+}
+
+func (m *JWTEdDSAMaker) VerifyToken(tokenString string) (*Payload, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Payload{}, func(token *jwt.Token) (interface{}, error) {
+		_, ok := token.Method.(*jwt.SigningMethodEd25519)
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+
+		keyID, _ := token.Header["kid"].(string)
+		key, ok := m.keyring.PublicKey(keyID)
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+		return key.PublicKey, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	payload, ok := token.Claims.(*Payload)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	return payload, nil
+}
+
+func (m *JWTEdDSAMaker) PublicKeys() []JWK {
+	return newJWKs(m.keyring.PublicKeys(), jwt.SigningMethodEdDSA.Alg())
+}
@@ -11,26 +11,44 @@ import (
 const minSecretLength = 32
 
 type JWTMaker struct {
-	secretKey string
+	keyring *Keyring
 }
 
 func NewJWTMaker(secretKey string) (Maker, error) {
-	if len(secretKey) < minSecretLength {
-		return nil, errors.New(fmt.Sprintf("valid secret key size must be at least %d characters", minSecretLength))
+	keyring, err := NewKeyring(Key{ID: DefaultKeyID, Secret: secretKey})
+	if err != nil {
+		return nil, err
+	}
+
+	return NewJWTKeyringMaker(keyring)
+}
+
+func NewJWTKeyringMaker(keyring *Keyring) (Maker, error) {
+	for _, key := range keyring.Keys() {
+		if len(key.Secret) < minSecretLength {
+			return nil, errors.New(fmt.Sprintf("valid secret key size for key %q must be at least %d characters", key.ID, minSecretLength))
+		}
 	}
 	return &JWTMaker{
-		secretKey: secretKey,
+		keyring: keyring,
 	}, nil
 }
 
-func (m *JWTMaker) GenerateToken(username string, duration time.Duration) (string, error) {
-	payload, err := NewPayload(username, duration)
+func (m *JWTMaker) GenerateToken(username string, role string, duration time.Duration, tokenType TokenType) (string, *Payload, error) {
+	payload, err := NewPayload(username, role, duration, tokenType)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
+	key := m.keyring.Current()
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
-	return token.SignedString([]byte(m.secretKey))
+	token.Header["kid"] = key.ID
+	tokenString, err := token.SignedString([]byte(key.Secret))
+	if err != nil {
+		return "", nil, err
+	}
+
+	return tokenString, payload, nil
 }
 
 func (m *JWTMaker) VerifyToken(tokenString string) (*Payload, error) {
@@ -39,7 +57,13 @@ func (m *JWTMaker) VerifyToken(tokenString string) (*Payload, error) {
 		if !ok {
 			return nil, ErrInvalidToken
 		}
-		return []byte(m.secretKey), nil
+
+		keyID, _ := token.Header["kid"].(string)
+		key, ok := m.keyring.Key(keyID)
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+		return []byte(key.Secret), nil
 	})
 
 	if err != nil {
@@ -18,14 +18,18 @@ func TestJWTMaker(t *testing.T) {
 
 	username := utils.RandomOwner()
 	duration := time.Minute
-	tokenString, err := jwtMaker.GenerateToken(username, duration)
+	tokenString, generatedPayload, err := jwtMaker.GenerateToken(username, utils.RoleCustomer, duration, token.TokenTypeAccess)
 	require.NoError(t, err)
 	require.NotEmpty(t, tokenString)
+	require.NotEmpty(t, generatedPayload)
 
 	payload, err := jwtMaker.VerifyToken(tokenString)
 	require.NoError(t, err)
 	require.NotEmpty(t, payload)
+	require.Equal(t, generatedPayload.ID, payload.ID)
 	require.Equal(t, username, payload.Username)
+	require.Equal(t, utils.RoleCustomer, payload.Role)
+	require.Equal(t, token.TokenTypeAccess, payload.Type)
 	require.WithinDuration(t, time.Now(), payload.IssuedAt.Time, time.Second)
 	require.WithinDuration(t, time.Now().Add(duration), payload.ExpiresAt.Time, time.Second)
 }
@@ -35,7 +39,7 @@ func TestJWTMakerWithExpiredToken(t *testing.T) {
 	require.NoError(t, err)
 	require.NotEmpty(t, jwtMaker)
 
-	tokenString, err := jwtMaker.GenerateToken(utils.RandomOwner(), -time.Minute)
+	tokenString, _, err := jwtMaker.GenerateToken(utils.RandomOwner(), utils.RoleCustomer, -time.Minute, token.TokenTypeAccess)
 	require.NoError(t, err)
 	require.NotEmpty(t, tokenString)
 
@@ -50,7 +54,7 @@ func TestJWTMakerInvalidToken(t *testing.T) {
 	require.NoError(t, err)
 	require.NotEmpty(t, jwtMaker)
 
-	payload, err := token.NewPayload(utils.RandomOwner(), time.Minute)
+	payload, err := token.NewPayload(utils.RandomOwner(), utils.RoleCustomer, time.Minute, token.TokenTypeAccess)
 	require.NoError(t, err)
 
 	jwtToken := jwt.NewWithClaims(jwt.SigningMethodNone, payload)
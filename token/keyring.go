@@ -0,0 +1,89 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultKeyID identifies the secret of tokens issued without a key ID, which
+// is how every token was signed before key rotation was supported.
+const DefaultKeyID = "default"
+
+type Key struct {
+	ID     string
+	Secret string
+}
+
+// Keyring signs tokens with its current key and verifies them with the current
+// key or any of the previous keys, so a rotated secret keeps validating the
+// tokens it issued until they expire.
+type Keyring struct {
+	current  Key
+	previous []Key
+}
+
+func NewKeyring(current Key, previous ...Key) (*Keyring, error) {
+	if current.ID == "" {
+		current.ID = DefaultKeyID
+	}
+
+	seen := map[string]bool{current.ID: true}
+	for _, key := range previous {
+		if key.ID == "" {
+			return nil, errors.New("previous key must have an id")
+		}
+		if seen[key.ID] {
+			return nil, fmt.Errorf("duplicate key id %q", key.ID)
+		}
+		seen[key.ID] = true
+	}
+
+	return &Keyring{
+		current:  current,
+		previous: previous,
+	}, nil
+}
+
+func (k *Keyring) Current() Key {
+	return k.current
+}
+
+// Key returns the key with the given id. Tokens without a key id are looked up
+// under DefaultKeyID.
+func (k *Keyring) Key(id string) (Key, bool) {
+	if id == "" {
+		id = DefaultKeyID
+	}
+
+	for _, key := range k.Keys() {
+		if key.ID == id {
+			return key, true
+		}
+	}
+	return Key{}, false
+}
+
+// Keys returns the current key followed by the previous keys.
+func (k *Keyring) Keys() []Key {
+	return append([]Key{k.current}, k.previous...)
+}
+
+// ParseKeys parses a comma separated list of id:secret pairs.
+func ParseKeys(value string) ([]Key, error) {
+	var keys []Key
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		id, secret, ok := strings.Cut(pair, ":")
+		if !ok || id == "" || secret == "" {
+			return nil, fmt.Errorf("invalid key %q, must be in id:secret format", pair)
+		}
+		keys = append(keys, Key{ID: id, Secret: secret})
+	}
+
+	return keys, nil
+}
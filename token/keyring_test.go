@@ -0,0 +1,82 @@
+package token_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mohammad19khodaei/simple_bank/token"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKeys(t *testing.T) {
+	keys, err := token.ParseKeys("")
+	require.NoError(t, err)
+	require.Empty(t, keys)
+
+	keys, err = token.ParseKeys("k1:secret1, k2:secret:2")
+	require.NoError(t, err)
+	require.Equal(t, []token.Key{{ID: "k1", Secret: "secret1"}, {ID: "k2", Secret: "secret:2"}}, keys)
+
+	_, err = token.ParseKeys("secret-without-id")
+	require.Error(t, err)
+}
+
+func TestNewKeyringWithDuplicateKeyID(t *testing.T) {
+	_, err := token.NewKeyring(token.Key{ID: "k1", Secret: utils.RandomString(32)}, token.Key{ID: "k1", Secret: utils.RandomString(32)})
+	require.Error(t, err)
+}
+
+func TestKeyRotation(t *testing.T) {
+	makers := map[string]func(keyring *token.Keyring) (token.Maker, error){
+		"paseto": token.NewPasetoKeyringMaker,
+		"jwt":    token.NewJWTKeyringMaker,
+	}
+
+	for name, newMaker := range makers {
+		t.Run(name, func(t *testing.T) {
+			oldKey := token.Key{ID: "k1", Secret: utils.RandomString(32)}
+			newKey := token.Key{ID: "k2", Secret: utils.RandomString(32)}
+
+			oldKeyring, err := token.NewKeyring(oldKey)
+			require.NoError(t, err)
+			oldMaker, err := newMaker(oldKeyring)
+			require.NoError(t, err)
+
+			rotatedKeyring, err := token.NewKeyring(newKey, oldKey)
+			require.NoError(t, err)
+			rotatedMaker, err := newMaker(rotatedKeyring)
+			require.NoError(t, err)
+
+			retiredKeyring, err := token.NewKeyring(newKey)
+			require.NoError(t, err)
+			retiredMaker, err := newMaker(retiredKeyring)
+			require.NoError(t, err)
+
+			username := utils.RandomOwner()
+			oldToken, _, err := oldMaker.GenerateToken(username, utils.RoleCustomer, time.Minute, token.TokenTypeAccess)
+			require.NoError(t, err)
+
+			// tokens signed by a previous key are still accepted
+			payload, err := rotatedMaker.VerifyToken(oldToken)
+			require.NoError(t, err)
+			require.Equal(t, username, payload.Username)
+
+			// new tokens are signed with the current key only
+			newToken, _, err := rotatedMaker.GenerateToken(username, utils.RoleCustomer, time.Minute, token.TokenTypeAccess)
+			require.NoError(t, err)
+
+			_, err = oldMaker.VerifyToken(newToken)
+			require.True(t, errors.Is(err, token.ErrInvalidToken))
+
+			payload, err = retiredMaker.VerifyToken(newToken)
+			require.NoError(t, err)
+			require.Equal(t, username, payload.Username)
+
+			// once the previous key is dropped its tokens are rejected
+			_, err = retiredMaker.VerifyToken(oldToken)
+			require.True(t, errors.Is(err, token.ErrInvalidToken))
+		})
+	}
+}
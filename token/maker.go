@@ -3,6 +3,6 @@ package token
 import "time"
 
 type Maker interface {
-	GenerateToken(username string, duration time.Duration) (string, error)
+	GenerateToken(username string, role string, duration time.Duration, tokenType TokenType) (string, *Payload, error)
 	VerifyToken(token string) (*Payload, error)
 }
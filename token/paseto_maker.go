@@ -10,36 +10,67 @@ import (
 )
 
 type PasetoMaker struct {
-	paseto    *paseto.V2
-	secretKey []byte
+	paseto  *paseto.V2
+	keyring *Keyring
+}
+
+type pasetoFooter struct {
+	KeyID string `json:"kid"`
 }
 
 func NewPasetoMaker(secretKey string) (Maker, error) {
-	if len(secretKey) != chacha20poly1305.KeySize {
-		return nil, errors.New(fmt.Sprintf("invalid secret key size, must be exact %d characters", chacha20poly1305.KeySize))
+	keyring, err := NewKeyring(Key{ID: DefaultKeyID, Secret: secretKey})
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPasetoKeyringMaker(keyring)
+}
+
+func NewPasetoKeyringMaker(keyring *Keyring) (Maker, error) {
+	for _, key := range keyring.Keys() {
+		if len(key.Secret) != chacha20poly1305.KeySize {
+			return nil, errors.New(fmt.Sprintf("invalid secret key size for key %q, must be exact %d characters", key.ID, chacha20poly1305.KeySize))
+		}
 	}
 
 	maker := &PasetoMaker{
-		paseto:    &paseto.V2{},
-		secretKey: []byte(secretKey),
+		paseto:  &paseto.V2{},
+		keyring: keyring,
 	}
 
 	return maker, nil
 }
 
-func (m *PasetoMaker) GenerateToken(username string, duration time.Duration) (string, error) {
-	payload, err := NewPayload(username, duration)
+func (m *PasetoMaker) GenerateToken(username string, role string, duration time.Duration, tokenType TokenType) (string, *Payload, error) {
+	payload, err := NewPayload(username, role, duration, tokenType)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
-	return m.paseto.Encrypt(m.secretKey, payload, nil)
+	key := m.keyring.Current()
+	tokenString, err := m.paseto.Encrypt([]byte(key.Secret), payload, pasetoFooter{KeyID: key.ID})
+	if err != nil {
+		return "", nil, err
+	}
+
+	return tokenString, payload, nil
 }
 
 func (m *PasetoMaker) VerifyToken(tokenString string) (*Payload, error) {
+	var footer pasetoFooter
+	if err := paseto.ParseFooter(tokenString, &footer); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	key, ok := m.keyring.Key(footer.KeyID)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
 	payload := &Payload{}
 
-	err := m.paseto.Decrypt(tokenString, m.secretKey, payload, nil)
+	err := m.paseto.Decrypt(tokenString, []byte(key.Secret), payload, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,65 @@
+package token
+
+import (
+	"time"
+
+	"github.com/o1egl/paseto"
+)
+
+// PasetoPublicMaker issues v2.public tokens signed with Ed25519.
+type PasetoPublicMaker struct {
+	paseto  *paseto.V2
+	keyring *PublicKeyring
+}
+
+func NewPasetoPublicMaker(keyring *PublicKeyring) (Maker, error) {
+	return &PasetoPublicMaker{
+		paseto:  &paseto.V2{},
+		keyring: keyring,
+	}, nil
+}
+
+func (m *PasetoPublicMaker) GenerateToken(username string, role string, duration time.Duration, tokenType TokenType) (string, *Payload, error) {
+	payload, err := NewPayload(username, role, duration, tokenType)
+	if err != nil {
+		return "", nil, err
+	}
+
+	key := m.keyring.Current()
+	tokenString, err := m.paseto.Sign(key.PrivateKey, payload, pasetoFooter{KeyID: key.ID})
+	if err != nil {
+		return "", nil, err
+	}
+
+	return tokenString, payload, nil
+}
+
+func (m *PasetoPublicMaker) VerifyToken(tokenString string) (*Payload, error) {
+	var footer pasetoFooter
+	if err := paseto.ParseFooter(tokenString, &footer); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	key, ok := m.keyring.PublicKey(footer.KeyID)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	payload := &Payload{}
+
+	err := m.paseto.Verify(tokenString, key.PublicKey, payload, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = payload.Valid()
+	if err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+func (m *PasetoPublicMaker) PublicKeys() []JWK {
+	return newJWKs(m.keyring.PublicKeys(), "v2.public")
+}
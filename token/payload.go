@@ -13,12 +13,23 @@ var (
 	ErrExpiredToken = errors.New("token has expired")
 )
 
+// TokenType tells access tokens, which authorize requests, from refresh
+// tokens, which can only be traded for a new access token.
+type TokenType string
+
+const (
+	TokenTypeAccess  TokenType = "access"
+	TokenTypeRefresh TokenType = "refresh"
+)
+
 type Payload struct {
-	Username string `json:"username"`
+	Username string    `json:"username"`
+	Role     string    `json:"role"`
+	Type     TokenType `json:"token_type"`
 	jwt.RegisteredClaims
 }
 
-func NewPayload(username string, duration time.Duration) (*Payload, error) {
+func NewPayload(username string, role string, duration time.Duration, tokenType TokenType) (*Payload, error) {
 	id, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
@@ -26,6 +37,8 @@ func NewPayload(username string, duration time.Duration) (*Payload, error) {
 
 	payload := &Payload{
 		Username: username,
+		Role:     role,
+		Type:     tokenType,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ID:        id.String(),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
@@ -0,0 +1,126 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type SigningKey struct {
+	ID         string
+	PrivateKey ed25519.PrivateKey
+}
+
+type VerificationKey struct {
+	ID        string
+	PublicKey ed25519.PublicKey
+}
+
+// PublicKeyring signs tokens with the current Ed25519 private key and verifies
+// them with its public half or any previous public key. Retired keys only need
+// their public half to keep validating the tokens they issued.
+type PublicKeyring struct {
+	current  SigningKey
+	previous []VerificationKey
+}
+
+func NewPublicKeyring(current SigningKey, previous ...VerificationKey) (*PublicKeyring, error) {
+	if current.ID == "" {
+		current.ID = DefaultKeyID
+	}
+	if len(current.PrivateKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid private key size for key %q, must be %d bytes", current.ID, ed25519.PrivateKeySize)
+	}
+
+	seen := map[string]bool{current.ID: true}
+	for _, key := range previous {
+		if key.ID == "" {
+			return nil, errors.New("previous public key must have an id")
+		}
+		if seen[key.ID] {
+			return nil, fmt.Errorf("duplicate key id %q", key.ID)
+		}
+		if len(key.PublicKey) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid public key size for key %q, must be %d bytes", key.ID, ed25519.PublicKeySize)
+		}
+		seen[key.ID] = true
+	}
+
+	return &PublicKeyring{
+		current:  current,
+		previous: previous,
+	}, nil
+}
+
+func (k *PublicKeyring) Current() SigningKey {
+	return k.current
+}
+
+// PublicKey returns the verification key with the given id. Tokens without a
+// key id are looked up under DefaultKeyID.
+func (k *PublicKeyring) PublicKey(id string) (VerificationKey, bool) {
+	if id == "" {
+		id = DefaultKeyID
+	}
+
+	for _, key := range k.PublicKeys() {
+		if key.ID == id {
+			return key, true
+		}
+	}
+	return VerificationKey{}, false
+}
+
+// PublicKeys returns the public half of the current key followed by the
+// previous public keys.
+func (k *PublicKeyring) PublicKeys() []VerificationKey {
+	current := VerificationKey{
+		ID:        k.current.ID,
+		PublicKey: k.current.PrivateKey.Public().(ed25519.PublicKey),
+	}
+	return append([]VerificationKey{current}, k.previous...)
+}
+
+// ParsePrivateKey decodes a base64 encoded Ed25519 seed or private key.
+func ParsePrivateKey(value string) (ed25519.PrivateKey, error) {
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(decoded) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(decoded), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(decoded), nil
+	default:
+		return nil, fmt.Errorf("private key must be a %d byte seed or a %d byte key", ed25519.SeedSize, ed25519.PrivateKeySize)
+	}
+}
+
+// ParseVerificationKeys parses a comma separated list of id:public_key pairs
+// where each public key is base64 encoded.
+func ParseVerificationKeys(value string) ([]VerificationKey, error) {
+	var keys []VerificationKey
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		id, encoded, ok := strings.Cut(pair, ":")
+		if !ok || id == "" || encoded == "" {
+			return nil, fmt.Errorf("invalid public key %q, must be in id:public_key format", pair)
+		}
+
+		publicKey, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key %q: %w", id, err)
+		}
+		keys = append(keys, VerificationKey{ID: id, PublicKey: publicKey})
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,123 @@
+package token_test
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mohammad19khodaei/simple_bank/token"
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func randomPublicKeyring(t *testing.T, id string, previous ...token.VerificationKey) *token.PublicKeyring {
+	_, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	keyring, err := token.NewPublicKeyring(token.SigningKey{ID: id, PrivateKey: privateKey}, previous...)
+	require.NoError(t, err)
+	return keyring
+}
+
+func TestPublicMakers(t *testing.T) {
+	makers := map[string]func(keyring *token.PublicKeyring) (token.Maker, error){
+		"paseto": token.NewPasetoPublicMaker,
+		"jwt":    token.NewJWTEdDSAMaker,
+	}
+
+	for name, newMaker := range makers {
+		t.Run(name, func(t *testing.T) {
+			keyring := randomPublicKeyring(t, "k1")
+			maker, err := newMaker(keyring)
+			require.NoError(t, err)
+
+			username := utils.RandomOwner()
+			duration := time.Minute
+			tokenString, _, err := maker.GenerateToken(username, utils.RoleCustomer, duration, token.TokenTypeAccess)
+			require.NoError(t, err)
+			require.NotEmpty(t, tokenString)
+
+			payload, err := maker.VerifyToken(tokenString)
+			require.NoError(t, err)
+			require.Equal(t, username, payload.Username)
+			require.WithinDuration(t, time.Now().Add(duration), payload.ExpiresAt.Time, time.Second)
+
+			// a verifier that only holds the public key accepts the token
+			verifier, err := newMaker(randomPublicKeyring(t, "k2", keyring.PublicKeys()[0]))
+			require.NoError(t, err)
+
+			payload, err = verifier.VerifyToken(tokenString)
+			require.NoError(t, err)
+			require.Equal(t, username, payload.Username)
+
+			// a verifier without the key rejects it
+			stranger, err := newMaker(randomPublicKeyring(t, "k1"))
+			require.NoError(t, err)
+
+			_, err = stranger.VerifyToken(tokenString)
+			require.Error(t, err)
+
+			provider, ok := maker.(token.PublicKeyProvider)
+			require.True(t, ok)
+			jwks := provider.PublicKeys()
+			require.Len(t, jwks, 1)
+			require.Equal(t, "k1", jwks[0].KeyID)
+			require.Equal(t, "Ed25519", jwks[0].Curve)
+			require.Equal(t, base64.RawURLEncoding.EncodeToString(keyring.PublicKeys()[0].PublicKey), jwks[0].X)
+		})
+	}
+}
+
+func TestPublicMakersWithExpiredToken(t *testing.T) {
+	makers := map[string]func(keyring *token.PublicKeyring) (token.Maker, error){
+		"paseto": token.NewPasetoPublicMaker,
+		"jwt":    token.NewJWTEdDSAMaker,
+	}
+
+	for name, newMaker := range makers {
+		t.Run(name, func(t *testing.T) {
+			maker, err := newMaker(randomPublicKeyring(t, "k1"))
+			require.NoError(t, err)
+
+			tokenString, _, err := maker.GenerateToken(utils.RandomOwner(), utils.RoleCustomer, -time.Minute, token.TokenTypeAccess)
+			require.NoError(t, err)
+
+			payload, err := maker.VerifyToken(tokenString)
+			require.Error(t, err)
+			require.Empty(t, payload)
+		})
+	}
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	parsed, err := token.ParsePrivateKey(base64.StdEncoding.EncodeToString(privateKey.Seed()))
+	require.NoError(t, err)
+	require.True(t, privateKey.Equal(parsed))
+
+	parsed, err = token.ParsePrivateKey(base64.StdEncoding.EncodeToString(privateKey))
+	require.NoError(t, err)
+	require.True(t, privateKey.Equal(parsed))
+
+	_, err = token.ParsePrivateKey(base64.StdEncoding.EncodeToString([]byte("short")))
+	require.Error(t, err)
+}
+
+func TestPasetoPublicMakerRejectsLocalToken(t *testing.T) {
+	localMaker, err := token.NewPasetoMaker(utils.RandomString(32))
+	require.NoError(t, err)
+
+	tokenString, _, err := localMaker.GenerateToken(utils.RandomOwner(), utils.RoleCustomer, time.Minute, token.TokenTypeAccess)
+	require.NoError(t, err)
+
+	maker, err := token.NewPasetoPublicMaker(randomPublicKeyring(t, token.DefaultKeyID))
+	require.NoError(t, err)
+
+	_, err = maker.VerifyToken(tokenString)
+	require.Error(t, err)
+	require.False(t, errors.Is(err, token.ErrExpiredToken))
+}
@@ -0,0 +1,30 @@
+package utils
+
+const (
+	AccountStatusActive = "active"
+	AccountStatusFrozen = "frozen"
+	AccountStatusClosed = "closed"
+)
+
+// accountStatusTransitions lists the statuses an account may move to from
+// each status. Closed is terminal.
+var accountStatusTransitions = map[string][]string{
+	AccountStatusActive: {AccountStatusFrozen, AccountStatusClosed},
+	AccountStatusFrozen: {AccountStatusActive},
+}
+
+func CanTransitionAccountStatus(from, to string) bool {
+	for _, status := range accountStatusTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	AccountKindCustomer   = "customer"
+	AccountKindSettlement = "settlement"
+	// AccountKindFee accounts collect the transfer fees of their currency.
+	AccountKindFee = "fee"
+)
@@ -0,0 +1,58 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAccountNumber(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		number, err := utils.NewAccountNumber()
+		require.NoError(t, err)
+		require.Len(t, number, 16)
+		require.True(t, utils.IsValidAccountNumber(number), number)
+	}
+}
+
+func TestIsValidAccountNumber(t *testing.T) {
+	number, err := utils.NewAccountNumber()
+	require.NoError(t, err)
+
+	// a single changed digit breaks the check digits
+	digit := number[10]
+	changed := []byte(number)
+	changed[10] = '0' + (digit-'0'+1)%10
+	require.False(t, utils.IsValidAccountNumber(string(changed)))
+
+	// so does swapping two different adjacent digits
+	for i := 4; i < len(number)-1; i++ {
+		if number[i] != number[i+1] {
+			swapped := []byte(number)
+			swapped[i], swapped[i+1] = swapped[i+1], swapped[i]
+			require.False(t, utils.IsValidAccountNumber(string(swapped)))
+			break
+		}
+	}
+
+	require.False(t, utils.IsValidAccountNumber(""))
+	require.False(t, utils.IsValidAccountNumber("XX"+number[2:]))
+	require.False(t, utils.IsValidAccountNumber(number[:15]))
+	require.False(t, utils.IsValidAccountNumber(number[:15]+"A"))
+}
+
+func TestNormalizeAccountNumber(t *testing.T) {
+	number, err := utils.NewAccountNumber()
+	require.NoError(t, err)
+
+	printed := "sb" + number[2:4] + " " + number[4:8] + " " + number[8:12] + " " + number[12:]
+	require.Equal(t, number, utils.NormalizeAccountNumber(printed))
+}
+
+func TestMaskName(t *testing.T) {
+	require.Equal(t, "J*** S****", utils.MaskName("John Smith"))
+	require.Equal(t, "Z****", utils.MaskName("  Zahra "))
+	require.Equal(t, "Ž***", utils.MaskName("Žofi"))
+	require.Equal(t, "", utils.MaskName(""))
+}
@@ -0,0 +1,19 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanTransitionAccountStatus(t *testing.T) {
+	require.True(t, utils.CanTransitionAccountStatus(utils.AccountStatusActive, utils.AccountStatusFrozen))
+	require.True(t, utils.CanTransitionAccountStatus(utils.AccountStatusFrozen, utils.AccountStatusActive))
+	require.True(t, utils.CanTransitionAccountStatus(utils.AccountStatusActive, utils.AccountStatusClosed))
+
+	require.False(t, utils.CanTransitionAccountStatus(utils.AccountStatusFrozen, utils.AccountStatusClosed))
+	require.False(t, utils.CanTransitionAccountStatus(utils.AccountStatusClosed, utils.AccountStatusActive))
+	require.False(t, utils.CanTransitionAccountStatus(utils.AccountStatusClosed, utils.AccountStatusFrozen))
+	require.False(t, utils.CanTransitionAccountStatus(utils.AccountStatusActive, utils.AccountStatusActive))
+}
@@ -7,10 +7,29 @@ import (
 )
 
 type Config struct {
-	DBSource      string        `mapstructure:"DB_SOURCE"`
-	ServerAddress string        `mapstructure:"SERVER_ADDRESS"`
-	SecretKey     string        `mapstructure:"SECRET_KEY"`
-	TokenDuration time.Duration `mapstructure:"TOKEN_DURATION"`
+	DBSource                   string        `mapstructure:"DB_SOURCE"`
+	ServerAddress              string        `mapstructure:"SERVER_ADDRESS"`
+	TokenType                  string        `mapstructure:"TOKEN_TYPE"`
+	SecretKey                  string        `mapstructure:"SECRET_KEY"`
+	SecretKeyID                string        `mapstructure:"SECRET_KEY_ID"`
+	PreviousSecretKeys         string        `mapstructure:"PREVIOUS_SECRET_KEYS"`
+	TokenPrivateKey            string        `mapstructure:"TOKEN_PRIVATE_KEY"`
+	TokenPrivateKeyID          string        `mapstructure:"TOKEN_PRIVATE_KEY_ID"`
+	PreviousTokenPublicKeys    string        `mapstructure:"PREVIOUS_TOKEN_PUBLIC_KEYS"`
+	TokenDuration              time.Duration `mapstructure:"TOKEN_DURATION"`
+	RefreshTokenDuration       time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	RevocationStore            string        `mapstructure:"REVOCATION_STORE"`
+	RevocationCleanupInterval  time.Duration `mapstructure:"REVOCATION_CLEANUP_INTERVAL"`
+	CurrencySource             string        `mapstructure:"CURRENCY_SOURCE"`
+	CurrenciesFile             string        `mapstructure:"CURRENCIES_FILE"`
+	FxProvider                 string        `mapstructure:"FX_PROVIDER"`
+	FxRatesFile                string        `mapstructure:"FX_RATES_FILE"`
+	FxQuoteDuration            time.Duration `mapstructure:"FX_QUOTE_DURATION"`
+	HoldDuration               time.Duration `mapstructure:"HOLD_DURATION"`
+	HoldExpiryInterval         time.Duration `mapstructure:"HOLD_EXPIRY_INTERVAL"`
+	SchedulerInterval          time.Duration `mapstructure:"SCHEDULER_INTERVAL"`
+	IdempotencyKeyDuration     time.Duration `mapstructure:"IDEMPOTENCY_KEY_DURATION"`
+	IdempotencyCleanupInterval time.Duration `mapstructure:"IDEMPOTENCY_CLEANUP_INTERVAL"`
 }
 
 func LoadConfig(path string, filename string) (config Config, err error) {
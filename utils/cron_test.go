@@ -0,0 +1,60 @@
+package utils_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronNext(t *testing.T) {
+	// a Wednesday
+	from := time.Date(2024, time.January, 31, 10, 30, 15, 0, time.UTC)
+
+	testCases := []struct {
+		expr string
+		want time.Time
+	}{
+		{expr: "* * * * *", want: time.Date(2024, time.January, 31, 10, 31, 0, 0, time.UTC)},
+		{expr: "*/15 * * * *", want: time.Date(2024, time.January, 31, 10, 45, 0, 0, time.UTC)},
+		{expr: "0 9 1 * *", want: time.Date(2024, time.February, 1, 9, 0, 0, 0, time.UTC)},
+		{expr: "@monthly", want: time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 29 2 *", want: time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{expr: "30 8 * * 1-5", want: time.Date(2024, time.February, 1, 8, 30, 0, 0, time.UTC)},
+		{expr: "0 12 * * 7", want: time.Date(2024, time.February, 4, 12, 0, 0, 0, time.UTC)},
+		{expr: "0 0 1 1 *", want: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 10,11 * * *", want: time.Date(2024, time.January, 31, 11, 0, 0, 0, time.UTC)},
+		// either day field matches when both are restricted
+		{expr: "0 0 15 * 5", want: time.Date(2024, time.February, 2, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 30 2 *", want: time.Time{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expr, func(t *testing.T) {
+			schedule, err := utils.ParseCron(tc.expr)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, schedule.Next(from))
+		})
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"@every 5m",
+	} {
+		_, err := utils.ParseCron(expr)
+		require.Error(t, err, expr)
+	}
+}
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// rateScale is the number of decimal places kept when a rate is stored.
+const rateScale = 10
+
+// ParseRate parses a positive decimal string such as "0.92".
+func ParseRate(s string) (*big.Rat, error) {
+	rate, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		return nil, fmt.Errorf("invalid rate %q", s)
+	}
+	if rate.Sign() <= 0 {
+		return nil, fmt.Errorf("rate %q must be positive", s)
+	}
+	return rate, nil
+}
+
+// FormatRate renders a rate as a decimal string without trailing zeros.
+func FormatRate(rate *big.Rat) string {
+	s := rate.FloatString(rateScale)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+func NumericToRat(n pgtype.Numeric) (*big.Rat, error) {
+	if !n.Valid || n.NaN || n.InfinityModifier != pgtype.Finite || n.Int == nil {
+		return nil, errors.New("numeric is not a finite number")
+	}
+
+	rat := new(big.Rat).SetInt(n.Int)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(n.Exp))), nil))
+	if n.Exp >= 0 {
+		return rat.Mul(rat, scale), nil
+	}
+	return rat.Quo(rat, scale), nil
+}
+
+func RatToNumeric(rat *big.Rat) (pgtype.Numeric, error) {
+	var n pgtype.Numeric
+	err := n.Scan(FormatRate(rat))
+	return n, err
+}
+
+// MinorUnitRate turns a rate between major units into the rate between the
+// minor units amounts are stored in. A USD to JPY rate of 150 is a cents to
+// yen rate of 1.5, as USD has two minor units and JPY none.
+func MinorUnitRate(rate *big.Rat, fromMinorUnits int32, toMinorUnits int32) *big.Rat {
+	exp := toMinorUnits - fromMinorUnits
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(exp))), nil))
+	if exp >= 0 {
+		return new(big.Rat).Mul(rate, scale)
+	}
+	return new(big.Rat).Quo(rate, scale)
+}
+
+// ConvertAmount applies rate to amount, rounding down so the bank never
+// credits more than it received.
+func ConvertAmount(amount int64, rate *big.Rat) (int64, error) {
+	converted := new(big.Int).Mul(big.NewInt(amount), rate.Num())
+	converted.Quo(converted, rate.Denom())
+	if !converted.IsInt64() {
+		return 0, errors.New("converted amount overflows")
+	}
+	return converted.Int64(), nil
+}
+
+func abs(n int32) int32 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
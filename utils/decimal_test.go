@@ -0,0 +1,75 @@
+package utils_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/mohammad19khodaei/simple_bank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateNumericRoundTrip(t *testing.T) {
+	rate, err := utils.ParseRate("0.9215")
+	require.NoError(t, err)
+
+	numeric, err := utils.RatToNumeric(rate)
+	require.NoError(t, err)
+
+	got, err := utils.NumericToRat(numeric)
+	require.NoError(t, err)
+	require.Equal(t, 0, rate.Cmp(got))
+	require.Equal(t, "0.9215", utils.FormatRate(got))
+}
+
+func TestParseRate(t *testing.T) {
+	_, err := utils.ParseRate("abc")
+	require.Error(t, err)
+
+	_, err = utils.ParseRate("0")
+	require.Error(t, err)
+
+	_, err = utils.ParseRate("-1.5")
+	require.Error(t, err)
+
+	rate, err := utils.ParseRate("42000")
+	require.NoError(t, err)
+	require.Equal(t, "42000", utils.FormatRate(rate))
+}
+
+func TestConvertAmount(t *testing.T) {
+	rate, err := utils.ParseRate("0.92")
+	require.NoError(t, err)
+
+	converted, err := utils.ConvertAmount(1000, rate)
+	require.NoError(t, err)
+	require.Equal(t, int64(920), converted)
+
+	// rounds down
+	converted, err = utils.ConvertAmount(1, rate)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), converted)
+}
+
+func TestMinorUnitRate(t *testing.T) {
+	rate, err := utils.ParseRate("150")
+	require.NoError(t, err)
+
+	// cents to yen
+	converted, err := utils.ConvertAmount(100, utils.MinorUnitRate(rate, 2, 0))
+	require.NoError(t, err)
+	require.Equal(t, int64(150), converted)
+
+	// yen to cents
+	converted, err = utils.ConvertAmount(150, utils.MinorUnitRate(new(big.Rat).Inv(rate), 0, 2))
+	require.NoError(t, err)
+	require.Equal(t, int64(100), converted)
+
+	// cents to fils, KWD has three minor units
+	rate, err = utils.ParseRate("0.3")
+	require.NoError(t, err)
+	converted, err = utils.ConvertAmount(100, utils.MinorUnitRate(rate, 2, 3))
+	require.NoError(t, err)
+	require.Equal(t, int64(300), converted)
+
+	require.Equal(t, "0.3", utils.FormatRate(utils.MinorUnitRate(rate, 2, 2)))
+}
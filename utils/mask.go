@@ -0,0 +1,15 @@
+package utils
+
+import "strings"
+
+// MaskName keeps the first letter of every word of a name and hides the rest,
+// "John Smith" becomes "J*** S****". It lets a sender confirm a recipient
+// without disclosing their full name.
+func MaskName(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		letters := []rune(word)
+		words[i] = string(letters[0]) + strings.Repeat("*", len(letters)-1)
+	}
+	return strings.Join(words, " ")
+}
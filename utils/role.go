@@ -0,0 +1,20 @@
+package utils
+
+const (
+	RoleCustomer = "customer"
+	RoleSupport  = "support"
+	RoleAdmin    = "admin"
+)
+
+func GetValidRoles() []string {
+	return []string{RoleCustomer, RoleSupport, RoleAdmin}
+}
+
+func IsValidRole(input string) bool {
+	for _, role := range GetValidRoles() {
+		if input == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package utils
+
+const (
+	ScheduledTransferStatusActive    = "active"
+	ScheduledTransferStatusPaused    = "paused"
+	ScheduledTransferStatusCancelled = "cancelled"
+	ScheduledTransferStatusCompleted = "completed"
+)
+
+// scheduledTransferStatusTransitions lists the statuses a scheduled transfer
+// may be moved to by its owner. Cancelled and completed are terminal.
+var scheduledTransferStatusTransitions = map[string][]string{
+	ScheduledTransferStatusActive: {ScheduledTransferStatusPaused, ScheduledTransferStatusCancelled},
+	ScheduledTransferStatusPaused: {ScheduledTransferStatusActive, ScheduledTransferStatusCancelled},
+}
+
+func CanTransitionScheduledTransferStatus(from, to string) bool {
+	for _, status := range scheduledTransferStatusTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	ScheduledRunStatusPending   = "pending"
+	ScheduledRunStatusSucceeded = "succeeded"
+	ScheduledRunStatusFailed    = "failed"
+)
@@ -0,0 +1,20 @@
+package utils
+
+const (
+	// TransferBatchModeAtomic moves every item of a batch or none of them.
+	TransferBatchModeAtomic = "atomic"
+	// TransferBatchModeBestEffort moves the items that can be moved and
+	// records why the others failed.
+	TransferBatchModeBestEffort = "best_effort"
+)
+
+const (
+	TransferBatchStatusCompleted          = "completed"
+	TransferBatchStatusPartiallyCompleted = "partially_completed"
+	TransferBatchStatusFailed             = "failed"
+)
+
+const (
+	TransferBatchItemStatusSucceeded = "succeeded"
+	TransferBatchItemStatusFailed    = "failed"
+)